@@ -8,6 +8,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/bootstrap"
 	"github.com/alist-org/alist/v3/internal/bootstrap/data"
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
@@ -21,6 +22,7 @@ func Init() {
 }
 
 func Release() {
+	task.Release()
 	db.Close()
 }
 
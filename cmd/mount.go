@@ -0,0 +1,85 @@
+//go:build fuse
+
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/alist-org/alist/v3/internal/bootstrap"
+	"github.com/alist-org/alist/v3/internal/fuse"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mountOptions   []string
+	mountCacheSize int64
+	mountBlockSize int64
+	mountExitCode  int
+)
+
+// MountCmd represents the mount command
+var MountCmd = &cobra.Command{
+	Use:   "mount [alist path] [mount point]",
+	Short: "Mount an alist path to the local file system",
+	Long: `Mount an alist path to the local file system with fuse,
+the storages are loaded like the server command does`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		mountSrc, mountDst := utils.FixAndCleanPath(args[0]), args[1]
+		Init()
+		defer Release()
+		bootstrap.InitOfflineDownloadTools()
+		bootstrap.LoadStorages()
+		bootstrap.InitTaskManager()
+		var cache *fuse.BlockCache
+		if mountCacheSize > 0 {
+			var err error
+			cache, err = fuse.NewBlockCache(mountBlockSize*1024*1024, mountCacheSize*1024*1024)
+			if err != nil {
+				utils.Log.Fatalf("failed create read cache: %+v", err)
+			}
+			defer func() {
+				_ = cache.Clear()
+			}()
+		}
+		var opts []string
+		for _, o := range mountOptions {
+			opts = append(opts, "-o", o)
+		}
+		host, done := fuse.Mount(mountSrc, mountDst, opts, cache)
+		utils.Log.Infof("mount [%s] to %s", mountSrc, mountDst)
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		select {
+		case <-quit:
+			utils.Log.Println("Unmount...")
+			if !host.Unmount() {
+				utils.Log.Errorf("failed unmount %s", mountDst)
+			}
+			<-done
+		case ok := <-done:
+			// mount failed or unmounted from outside
+			if !ok {
+				utils.Log.Errorf("failed mount [%s] to %s", mountSrc, mountDst)
+				mountExitCode = 1
+				return
+			}
+			utils.Log.Infof("%s is unmounted", mountDst)
+		}
+	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		if mountExitCode != 0 {
+			os.Exit(mountExitCode)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(MountCmd)
+	MountCmd.Flags().StringSliceVarP(&mountOptions, "option", "o", nil, "fuse mount options, such as -o allow_other")
+	MountCmd.Flags().Int64Var(&mountCacheSize, "cache-size", 1024, "max size of the read cache in MB, 0 to disable it")
+	MountCmd.Flags().Int64Var(&mountBlockSize, "block-size", 4, "size of a read cache block in MB")
+}
@@ -0,0 +1,24 @@
+//go:build !fuse
+
+package cmd
+
+import (
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// MountCmd represents the mount command, which needs the build with the fuse tag
+var MountCmd = &cobra.Command{
+	Use:   "mount [alist path] [mount point]",
+	Short: "Mount an alist path to the local file system",
+	Long: `Mount an alist path to the local file system with fuse,
+it's only available when alist is built with the fuse tag and cgo, such as:
+CGO_ENABLED=1 go build -tags fuse`,
+	Run: func(cmd *cobra.Command, args []string) {
+		utils.Log.Fatalf("mount is not supported by this build, rebuild alist with the fuse tag")
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(MountCmd)
+}
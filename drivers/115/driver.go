@@ -182,4 +182,25 @@ func (d *Pan115) Put(ctx context.Context, dstDir model.Obj, stream model.FileStr
 
 }
 
+func (d *Pan115) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	if err := d.WaitLimit(ctx); err != nil {
+		return nil, err
+	}
+	var result IndexInfoResp
+	resp, err := d.client.NewRequest().
+		SetContext(ctx).
+		SetResult(&result).
+		Get("https://webapi.115.com/files/index_info")
+	if err := driver115.CheckErr(err, &result, resp); err != nil {
+		return nil, err
+	}
+	info := result.Data.SpaceInfo
+	return &model.StorageDetails{
+		TotalSpace: int64(info.AllTotal.Size),
+		UsedSpace:  int64(info.AllUse.Size),
+		FreeSpace:  int64(info.AllRemain.Size),
+	}, nil
+}
+
 var _ driver.Driver = (*Pan115)(nil)
+var _ driver.WithDetails = (*Pan115)(nil)
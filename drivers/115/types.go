@@ -20,3 +20,19 @@ func (f *FileObj) CreateTime() time.Time {
 func (f *FileObj) GetHash() utils.HashInfo {
 	return utils.NewHashInfo(utils.SHA1, f.Sha1)
 }
+
+// IndexInfoResp the space usage of https://webapi.115.com/files/index_info
+type IndexInfoResp struct {
+	driver.BasicResp
+	Data struct {
+		SpaceInfo struct {
+			AllTotal  SizeInfo `json:"all_total"`
+			AllRemain SizeInfo `json:"all_remain"`
+			AllUse    SizeInfo `json:"all_use"`
+		} `json:"space_info"`
+	} `json:"data"`
+}
+
+type SizeInfo struct {
+	Size float64 `json:"size"`
+}
@@ -228,8 +228,25 @@ func (d *AliyundriveOpen) Other(ctx context.Context, args model.OtherArgs) (inte
 	return resp, nil
 }
 
+func (d *AliyundriveOpen) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	res, err := d.request("/adrive/v1.0/user/getSpaceInfo", http.MethodPost, func(req *resty.Request) {
+		req.SetContext(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	total := utils.Json.Get(res, "personal_space_info", "total_size").ToInt64()
+	used := utils.Json.Get(res, "personal_space_info", "used_size").ToInt64()
+	return &model.StorageDetails{
+		TotalSpace: total,
+		UsedSpace:  used,
+		FreeSpace:  total - used,
+	}, nil
+}
+
 var _ driver.Driver = (*AliyundriveOpen)(nil)
 var _ driver.MkdirResult = (*AliyundriveOpen)(nil)
 var _ driver.MoveResult = (*AliyundriveOpen)(nil)
 var _ driver.RenameResult = (*AliyundriveOpen)(nil)
 var _ driver.PutResult = (*AliyundriveOpen)(nil)
+var _ driver.WithDetails = (*AliyundriveOpen)(nil)
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"io"
 	"math"
+	"net/http"
 	"net/url"
 	stdpath "path"
 	"strconv"
@@ -19,6 +20,7 @@ import (
 	"github.com/alist-org/alist/v3/pkg/errgroup"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/avast/retry-go"
+	"github.com/go-resty/resty/v2"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -329,4 +331,23 @@ func (d *BaiduNetdisk) uploadSlice(ctx context.Context, params map[string]string
 	return nil
 }
 
+func (d *BaiduNetdisk) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var resp QuotaResp
+	_, err := d.request("https://pan.baidu.com/api/quota", http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx).SetQueryParams(map[string]string{
+			"checkfree":   "1",
+			"checkexpire": "1",
+		})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		TotalSpace: resp.Total,
+		UsedSpace:  resp.Used,
+		FreeSpace:  resp.Total - resp.Used,
+	}, nil
+}
+
 var _ driver.Driver = (*BaiduNetdisk)(nil)
+var _ driver.WithDetails = (*BaiduNetdisk)(nil)
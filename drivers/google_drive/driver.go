@@ -12,6 +12,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
 )
 
 type GoogleDrive struct {
@@ -166,4 +167,31 @@ func (d *GoogleDrive) Put(ctx context.Context, dstDir model.Obj, stream model.Fi
 	return err
 }
 
+func (d *GoogleDrive) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var about About
+	_, err := d.request("https://www.googleapis.com/drive/v3/about", http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx).SetQueryParam("fields", "storageQuota")
+	}, &about)
+	if err != nil {
+		return nil, err
+	}
+	if about.StorageQuota.Limit == "" {
+		return nil, errors.WithMessage(errs.NotSupport, "the storage is unlimited")
+	}
+	total, err := strconv.ParseInt(about.StorageQuota.Limit, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	used, err := strconv.ParseInt(about.StorageQuota.Usage, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		TotalSpace: total,
+		UsedSpace:  used,
+		FreeSpace:  total - used,
+	}, nil
+}
+
 var _ driver.Driver = (*GoogleDrive)(nil)
+var _ driver.WithDetails = (*GoogleDrive)(nil)
@@ -78,3 +78,12 @@ type Error struct {
 		Message string `json:"message"`
 	} `json:"error"`
 }
+
+// About only the storage quota of https://developers.google.com/drive/api/reference/rest/v3/about
+type About struct {
+	StorageQuota struct {
+		// Limit is empty if the storage is unlimited
+		Limit string `json:"limit"`
+		Usage string `json:"usage"`
+	} `json:"storageQuota"`
+}
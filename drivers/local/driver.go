@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
 	"os"
@@ -19,9 +20,11 @@ import (
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/djherbis/times"
+	"github.com/shirou/gopsutil/v3/disk"
 	log "github.com/sirupsen/logrus"
 	_ "golang.org/x/image/webp"
 )
@@ -277,21 +280,71 @@ func (d *Local) Remove(ctx context.Context, obj model.Obj) error {
 }
 
 func (d *Local) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	return d.put(ctx, dstDir, stream, 0, up)
+}
+
+// partialPath is where the file is written before it's complete, the size is in
+// the name so that an interrupted put is only resumed by a stream of the same size
+func partialPath(dir, name string, size int64) string {
+	return filepath.Join(dir, fmt.Sprintf(".%s.%d.alist_partial", name, size))
+}
+
+func (d *Local) Uploaded(ctx context.Context, dstDir model.Obj, stream model.FileStreamer) (int64, error) {
+	info, err := os.Stat(partialPath(dstDir.GetPath(), stream.GetName(), stream.GetSize()))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if info.Size() >= stream.GetSize() {
+		return 0, nil
+	}
+	return info.Size(), nil
+}
+
+func (d *Local) PutResume(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, offset int64, up driver.UpdateProgress) error {
+	return d.put(ctx, dstDir, stream, offset, up)
+}
+
+// put writes the stream after offset to the partial file, and moves it to the dst when it's complete.
+// The partial file is kept if the put fails, so it can be resumed, unless it's canceled.
+func (d *Local) put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, offset int64, up driver.UpdateProgress) error {
 	fullPath := filepath.Join(dstDir.GetPath(), stream.GetName())
-	out, err := os.Create(fullPath)
+	tmpPath := partialPath(dstDir.GetPath(), stream.GetName(), stream.GetSize())
+	out, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE, 0666)
 	if err != nil {
 		return err
 	}
 	defer func() {
 		_ = out.Close()
 		if errors.Is(err, context.Canceled) {
-			_ = os.Remove(fullPath)
+			_ = os.Remove(tmpPath)
 		}
 	}()
-	err = utils.CopyWithCtx(ctx, out, stream, stream.GetSize(), up)
+	if err = out.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err = out.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	var r io.Reader = stream
+	if offset > 0 {
+		r, err = stream.RangeRead(http_range.Range{Start: offset, Length: stream.GetSize() - offset})
+		if err != nil {
+			return err
+		}
+	}
+	err = utils.CopyWithCtx(ctx, out, r, stream.GetSize()-offset, up)
 	if err != nil {
 		return err
 	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, fullPath); err != nil {
+		return err
+	}
 	err = os.Chtimes(fullPath, stream.ModTime(), stream.ModTime())
 	if err != nil {
 		log.Errorf("[local] failed to change time of %s: %s", fullPath, err)
@@ -299,4 +352,18 @@ func (d *Local) Put(ctx context.Context, dstDir model.Obj, stream model.FileStre
 	return nil
 }
 
+func (d *Local) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	usage, err := disk.UsageWithContext(ctx, d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		TotalSpace: int64(usage.Total),
+		UsedSpace:  int64(usage.Used),
+		FreeSpace:  int64(usage.Free),
+	}, nil
+}
+
 var _ driver.Driver = (*Local)(nil)
+var _ driver.WithDetails = (*Local)(nil)
+var _ driver.PutResume = (*Local)(nil)
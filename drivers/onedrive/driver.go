@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"sync"
 
 	"github.com/alist-org/alist/v3/drivers/base"
@@ -207,4 +208,22 @@ func (d *Onedrive) Put(ctx context.Context, dstDir model.Obj, stream model.FileS
 	return err
 }
 
+func (d *Onedrive) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var drive Drive
+	// the url of the drive is the one of its root without the suffix
+	driveUrl := strings.TrimSuffix(d.GetMetaUrl(false, "/"), "/root")
+	_, err := d.Request(driveUrl, http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx)
+	}, &drive)
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		TotalSpace: drive.Quota.Total,
+		UsedSpace:  drive.Quota.Used,
+		FreeSpace:  drive.Quota.Remaining,
+	}, nil
+}
+
 var _ driver.Driver = (*Onedrive)(nil)
+var _ driver.WithDetails = (*Onedrive)(nil)
@@ -72,3 +72,12 @@ type Files struct {
 	Value    []File `json:"value"`
 	NextLink string `json:"@odata.nextLink"`
 }
+
+// Drive only the quota of https://learn.microsoft.com/en-us/graph/api/resources/drive
+type Drive struct {
+	Quota struct {
+		Total     int64 `json:"total"`
+		Used      int64 `json:"used"`
+		Remaining int64 `json:"remaining"`
+	} `json:"quota"`
+}
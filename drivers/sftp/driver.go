@@ -115,4 +115,21 @@ func (d *SFTP) Put(ctx context.Context, dstDir model.Obj, stream model.FileStrea
 	return err
 }
 
+func (d *SFTP) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	if err := d.clientReconnectOnConnectionError(); err != nil {
+		return nil, err
+	}
+	// the server must support the statvfs@openssh.com extension
+	stat, err := d.client.StatVFS(d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		TotalSpace: int64(stat.TotalSpace()),
+		UsedSpace:  int64(stat.TotalSpace() - stat.FreeSpace()),
+		FreeSpace:  int64(stat.Frsize * stat.Bavail),
+	}, nil
+}
+
 var _ driver.Driver = (*SFTP)(nil)
+var _ driver.WithDetails = (*SFTP)(nil)
@@ -197,4 +197,23 @@ func (d *SMB) Put(ctx context.Context, dstDir model.Obj, stream model.FileStream
 //	return nil, errs.NotSupport
 //}
 
+func (d *SMB) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	if err := d.checkConn(); err != nil {
+		return nil, err
+	}
+	stat, err := d.fs.Statfs(d.GetRootPath())
+	if err != nil {
+		d.cleanLastConnTime()
+		return nil, err
+	}
+	d.updateLastConnTime()
+	blockSize := stat.BlockSize() * stat.FragmentSize()
+	return &model.StorageDetails{
+		TotalSpace: int64(stat.TotalBlockCount() * blockSize),
+		UsedSpace:  int64((stat.TotalBlockCount() - stat.FreeBlockCount()) * blockSize),
+		FreeSpace:  int64(stat.AvailableBlockCount() * blockSize),
+	}, nil
+}
+
 var _ driver.Driver = (*SMB)(nil)
+var _ driver.WithDetails = (*SMB)(nil)
@@ -0,0 +1,316 @@
+// Package archive reads the entries of the archives through an io.ReaderAt,
+// so that the archives in the storages can be browsed with ranged reads instead of downloading them.
+package archive
+
+import (
+	"io"
+	stdpath "path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+type opener func(r io.ReaderAt, size int64) (*Archive, error)
+
+// the formats are matched by the suffix of the name, the longer suffixes first
+var formats = []struct {
+	suffix string
+	open   opener
+}{
+	{".tar.gz", openTarGz},
+	{".tgz", openTarGz},
+	{".tar.bz2", openTarBz2},
+	{".tbz2", openTarBz2},
+	{".tar", openTar},
+	{".zip", openZip},
+	{".7z", open7z},
+	{".rar", openRar},
+}
+
+func getOpener(name string) (opener, bool) {
+	name = strings.ToLower(name)
+	for _, f := range formats {
+		if strings.HasSuffix(name, f.suffix) {
+			return f.open, true
+		}
+	}
+	return nil, false
+}
+
+// IsArchive returns whether the name is of an archive format, it may be unsupported
+func IsArchive(name string) bool {
+	_, ok := getOpener(name)
+	return ok
+}
+
+type entry struct {
+	obj      *model.Object
+	children []*entry
+	// the data to open the entry, depends on the format
+	data interface{}
+}
+
+// Archive is the index of the entries of an archive
+type Archive struct {
+	entries map[string]*entry
+	// files are the entries in the order of the archive
+	files []*entry
+	open  func(e *entry, offset, length int64) (io.ReadCloser, error)
+	// walk reads the matched files in order, the entries are opened one by one if it's nil
+	walk func(match func(e *entry) bool, fn func(e *entry, r io.Reader) error) error
+}
+
+// Open reads the index of the archive named name, r reads the content of the archive
+func Open(name string, r io.ReaderAt, size int64) (*Archive, error) {
+	open, ok := getOpener(name)
+	if !ok {
+		return nil, errors.Errorf("%s is not an archive", name)
+	}
+	if open == nil {
+		return nil, errors.WithMessagef(errs.NotSupport, "the format of %s", name)
+	}
+	return open(r, size)
+}
+
+func newArchive() *Archive {
+	return &Archive{
+		entries: map[string]*entry{
+			"/": {obj: &model.Object{Name: "root", Path: "/", IsFolder: true}},
+		},
+	}
+}
+
+// add puts a file or a folder into the tree, the missing parent folders are created
+func (a *Archive) add(name string, size int64, modified time.Time, isDir bool, data interface{}) {
+	path := utils.FixAndCleanPath(name)
+	if path == "/" {
+		return
+	}
+	e, ok := a.entries[path]
+	if !ok {
+		e = &entry{obj: &model.Object{Name: stdpath.Base(path), Path: path}}
+		a.entries[path] = e
+		parent := stdpath.Dir(path)
+		a.add(parent, 0, modified, true, nil)
+		a.entries[parent].children = append(a.entries[parent].children, e)
+	}
+	// a folder created for its children may be listed later
+	if data != nil || !ok {
+		e.obj.Size = size
+		e.obj.Modified = modified
+		e.obj.IsFolder = isDir
+		e.data = data
+	}
+	if data != nil {
+		a.files = append(a.files, e)
+	}
+}
+
+func (a *Archive) sort() {
+	for _, e := range a.entries {
+		sort.Slice(e.children, func(i, j int) bool {
+			return e.children[i].obj.Name < e.children[j].obj.Name
+		})
+	}
+}
+
+// Get returns the entry at path, the root of the archive is /
+func (a *Archive) Get(path string) (model.Obj, error) {
+	e, ok := a.entries[utils.FixAndCleanPath(path)]
+	if !ok {
+		return nil, errors.WithStack(errs.ObjectNotFound)
+	}
+	return e.obj, nil
+}
+
+// List returns the entries in the folder at path
+func (a *Archive) List(path string) ([]model.Obj, error) {
+	e, ok := a.entries[utils.FixAndCleanPath(path)]
+	if !ok {
+		return nil, errors.WithStack(errs.ObjectNotFound)
+	}
+	if !e.obj.IsDir() {
+		return nil, errors.WithStack(errs.NotFolder)
+	}
+	objs := make([]model.Obj, 0, len(e.children))
+	for _, child := range e.children {
+		objs = append(objs, child.obj)
+	}
+	return objs, nil
+}
+
+// RangeRead reads the range of the file at path
+func (a *Archive) RangeRead(path string, httpRange http_range.Range) (io.ReadCloser, error) {
+	e, ok := a.entries[utils.FixAndCleanPath(path)]
+	if !ok {
+		return nil, errors.WithStack(errs.ObjectNotFound)
+	}
+	if e.obj.IsDir() {
+		return nil, errors.WithStack(errs.NotFile)
+	}
+	length := httpRange.Length
+	if length < 0 || httpRange.Start+length > e.obj.Size {
+		length = e.obj.Size - httpRange.Start
+	}
+	if httpRange.Start < 0 || length < 0 {
+		return nil, errors.Errorf("invalid range %d-%d of size %d", httpRange.Start, httpRange.Length, e.obj.Size)
+	}
+	return a.open(e, httpRange.Start, length)
+}
+
+// Walk calls fn with each file and folder under the folder at path in the order of the archive,
+// r reads the content of the file and is nil for a folder
+func (a *Archive) Walk(path string, fn func(obj model.Obj, r io.Reader) error) error {
+	root, ok := a.entries[utils.FixAndCleanPath(path)]
+	if !ok {
+		return errors.WithStack(errs.ObjectNotFound)
+	}
+	if !root.obj.IsDir() {
+		return errors.WithStack(errs.NotFolder)
+	}
+	walk := a.walk
+	if walk == nil {
+		walk = a.walkFiles
+	}
+	match := func(e *entry) bool {
+		return e != root && utils.IsSubPath(root.obj.Path, e.obj.Path)
+	}
+	return walk(match, func(e *entry, r io.Reader) error {
+		return fn(e.obj, r)
+	})
+}
+
+func (a *Archive) walkFiles(match func(e *entry) bool, fn func(e *entry, r io.Reader) error) error {
+	for _, e := range a.files {
+		if !match(e) {
+			continue
+		}
+		if e.obj.IsDir() {
+			if err := fn(e, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, err := a.open(e, 0, e.obj.Size)
+		if err != nil {
+			return err
+		}
+		err = fn(e, rc)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// member is a file or folder of an archive which is read in order
+type member struct {
+	name     string
+	size     int64
+	modified time.Time
+	isDir    bool
+}
+
+// sequence reads the members of an archive in order,
+// such as a compressed tar which can only be read by decompressing it from the start
+type sequence interface {
+	// next returns the next member and the reader of its content, the error is io.EOF at the end
+	next() (*member, io.Reader, error)
+	Close() error
+}
+
+// openSequence indexes the archive by reading it through,
+// the entries are opened by reading the archive again from the start until them
+func openSequence(open func() (sequence, error)) (*Archive, error) {
+	a := newArchive()
+	err := readSequence(open, func(i int, m *member, r io.Reader) error {
+		a.add(m.name, m.size, m.modified, m.isDir, i)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	a.sort()
+	// the members are read in a single pass instead of reading the archive for each of them
+	a.walk = func(match func(e *entry) bool, fn func(e *entry, r io.Reader) error) error {
+		return readSequence(open, func(i int, m *member, r io.Reader) error {
+			e, ok := a.entries[utils.FixAndCleanPath(m.name)]
+			// the member may be replaced by a later one of the same name
+			if !ok || e.data != i || !match(e) {
+				return nil
+			}
+			if e.obj.IsDir() {
+				return fn(e, nil)
+			}
+			return fn(e, r)
+		})
+	}
+	a.open = func(e *entry, offset, length int64) (io.ReadCloser, error) {
+		return cachedOpen(e, offset, length, func() (io.ReadCloser, error) {
+			return seekSequence(open, e)
+		})
+	}
+	return a, nil
+}
+
+func readSequence(open func() (sequence, error), fn func(i int, m *member, r io.Reader) error) error {
+	s, err := open()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	for i := 0; ; i++ {
+		m, r, err := s.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(i, m, r); err != nil {
+			return err
+		}
+	}
+}
+
+// seekSequence reads the archive until the member of the entry and returns the reader of its content
+func seekSequence(open func() (sequence, error), e *entry) (io.ReadCloser, error) {
+	s, err := open()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; ; i++ {
+		_, r, err := s.next()
+		if err != nil {
+			_ = s.Close()
+			if err == io.EOF {
+				return nil, errors.Errorf("%s not found in the archive", e.obj.Path)
+			}
+			return nil, err
+		}
+		if i == e.data {
+			return readCloser{Reader: r, Closer: s}, nil
+		}
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// skipRange discards offset bytes of rc and limits it to length
+func skipRange(rc io.ReadCloser, offset, length int64) (io.ReadCloser, error) {
+	if _, err := io.CopyN(io.Discard, rc, offset); err != nil {
+		_ = rc.Close()
+		return nil, errors.WithStack(err)
+	}
+	return readCloser{Reader: io.LimitReader(rc, length), Closer: rc}, nil
+}
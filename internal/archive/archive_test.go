@@ -0,0 +1,238 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// testFile is a file of the archives built by the tests, a folder if dir is true
+type testFile struct {
+	name    string
+	content string
+	dir     bool
+}
+
+var testFiles = []testFile{
+	{name: "dir", dir: true},
+	{name: "dir/a.txt", content: "hello"},
+	{name: "b.txt", content: "0123456789"},
+	{name: "empty.txt"},
+}
+
+func setTempDir(t *testing.T) string {
+	conf.Conf = conf.DefaultConfig()
+	conf.Conf.TempDir = t.TempDir()
+	return conf.Conf.TempDir
+}
+
+// build7z makes a 7z archive of the files with a solid lzma2 folder
+func build7z(t *testing.T, files []testFile) []byte {
+	var data bytes.Buffer
+	var sizes []int64
+	var emptyStream, emptyFile []bool
+	var names []byte
+	for _, f := range files {
+		empty := f.dir || f.content == ""
+		emptyStream = append(emptyStream, empty)
+		if empty {
+			emptyFile = append(emptyFile, !f.dir)
+		} else {
+			data.WriteString(f.content)
+			sizes = append(sizes, int64(len(f.content)))
+		}
+		for _, c := range utf16.Encode([]rune(f.name + "\x00")) {
+			names = binary.LittleEndian.AppendUint16(names, c)
+		}
+	}
+	var packed bytes.Buffer
+	w, err := lzma.Writer2Config{DictCap: 1 << 20}.NewWriter2(&packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bits := func(b []bool) []byte {
+		bytes := make([]byte, (len(b)+7)/8)
+		for i, ok := range b {
+			if ok {
+				bytes[i/8] |= 0x80 >> (i % 8)
+			}
+		}
+		return bytes
+	}
+	// all the numbers are less than 0x80, which are written as a byte
+	h := []byte{szHeader, szMainStreams,
+		szPackInfo, 0, 1, szSize, byte(packed.Len()), szEnd,
+		// a coder of lzma2 with the dictionary of 1 MB
+		szUnpackInfo, szFolderID, 1, 0, 1, 0x21, 0x21, 1, 16, szCodersUnpack, byte(data.Len()), szEnd,
+		szSubStreamsInfo, szNumUnpackStream, byte(len(sizes)), szSize}
+	for _, size := range sizes[:len(sizes)-1] {
+		h = append(h, byte(size))
+	}
+	h = append(h, szEnd, szEnd, szFilesInfo, byte(len(files)))
+	h = append(h, szEmptyStream, byte(len(bits(emptyStream))))
+	h = append(h, bits(emptyStream)...)
+	h = append(h, szEmptyFile, byte(len(bits(emptyFile))))
+	h = append(h, bits(emptyFile)...)
+	h = append(h, szName, byte(len(names)+1), 0)
+	h = append(h, names...)
+	h = append(h, szEnd, szEnd)
+
+	start := append([]byte{}, szSignature...)
+	start = append(start, 0, 4, 0, 0, 0, 0)
+	start = binary.LittleEndian.AppendUint64(start, uint64(packed.Len()))
+	start = binary.LittleEndian.AppendUint64(start, uint64(len(h)))
+	start = binary.LittleEndian.AppendUint32(start, crc32.ChecksumIEEE(h))
+	return append(append(start, packed.Bytes()...), h...)
+}
+
+// buildRar makes a rar 4 archive of the stored files
+func buildRar(files []testFile) []byte {
+	block := func(htype byte, flags uint16, data []byte) []byte {
+		b := []byte{htype}
+		b = binary.LittleEndian.AppendUint16(b, flags)
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(data)+7))
+		b = append(b, data...)
+		return append(binary.LittleEndian.AppendUint16(nil, uint16(crc32.ChecksumIEEE(b))), b...)
+	}
+	rar := []byte("Rar!\x1a\x07\x00")
+	rar = append(rar, block(0x73, 0, make([]byte, 6))...)
+	for _, f := range files {
+		flags := uint16(0x8000)
+		if f.dir {
+			flags |= 0x00e0
+		}
+		h := binary.LittleEndian.AppendUint32(nil, uint32(len(f.content)))
+		h = binary.LittleEndian.AppendUint32(h, uint32(len(f.content)))
+		h = append(h, 2)
+		h = binary.LittleEndian.AppendUint32(h, crc32.ChecksumIEEE([]byte(f.content)))
+		h = binary.LittleEndian.AppendUint32(h, 0)
+		// the version and the method of storing
+		h = append(h, 29, 0x30)
+		h = binary.LittleEndian.AppendUint16(h, uint16(len(f.name)))
+		h = binary.LittleEndian.AppendUint32(h, 0)
+		h = append(h, f.name...)
+		rar = append(rar, block(0x74, flags, h)...)
+		rar = append(rar, f.content...)
+	}
+	return append(rar, block(0x7b, 0, nil)...)
+}
+
+func buildTarGz(t *testing.T, files []testFile) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, f := range files {
+		hdr := &tar.Header{Name: f.name, Size: int64(len(f.content)), Mode: 0644, Typeflag: tar.TypeReg}
+		if f.dir {
+			hdr.Typeflag = tar.TypeDir
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func readRange(t *testing.T, a *Archive, path string, start, length int64) string {
+	rc, err := a.RangeRead(path, http_range.Range{Start: start, Length: length})
+	if err != nil {
+		t.Fatalf("failed read %s: %+v", path, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("failed read %s: %+v", path, err)
+	}
+	return string(data)
+}
+
+// checkArchive checks the archive of testFiles is listed and read
+func checkArchive(t *testing.T, name string, data []byte) {
+	tempDir := setTempDir(t)
+	a, err := Open(name, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("failed open %s: %+v", name, err)
+	}
+	objs, err := a.List("/")
+	if err != nil {
+		t.Fatalf("failed list %s: %+v", name, err)
+	}
+	var names []string
+	for _, obj := range objs {
+		names = append(names, obj.GetName())
+	}
+	if len(names) != 3 || names[0] != "b.txt" || names[1] != "dir" || names[2] != "empty.txt" {
+		t.Errorf("the root of %s should be listed in order, got %v", name, names)
+	}
+	if obj, err := a.Get("/dir"); err != nil || !obj.IsDir() {
+		t.Errorf("dir in %s should be a folder", name)
+	}
+	if s := readRange(t, a, "/dir/a.txt", 0, -1); s != "hello" {
+		t.Errorf("the file in %s should be read, got %s", name, s)
+	}
+	// the ranges in the middle are read from the decompressed file
+	for i := 0; i < 2; i++ {
+		if s := readRange(t, a, "/b.txt", 3, 4); s != "3456" {
+			t.Errorf("the range of the file in %s should be read, got %s", name, s)
+		}
+	}
+	if entries, _ := os.ReadDir(tempDir); len(entries) != 1 {
+		t.Errorf("the file in %s should be decompressed once, got %d temp files", name, len(entries))
+	}
+
+	contents := make(map[string]string)
+	err = a.Walk("/", func(obj model.Obj, r io.Reader) error {
+		if r == nil {
+			contents[obj.GetPath()] = "/"
+			return nil
+		}
+		data, err := io.ReadAll(r)
+		contents[obj.GetPath()] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed walk %s: %+v", name, err)
+	}
+	if len(contents) != 4 || contents["/dir"] != "/" || contents["/dir/a.txt"] != "hello" ||
+		contents["/b.txt"] != "0123456789" || contents["/empty.txt"] != "" {
+		t.Errorf("all the files in %s should be walked, got %v", name, contents)
+	}
+}
+
+func TestSevenZip(t *testing.T) {
+	checkArchive(t, "test.7z", build7z(t, testFiles))
+}
+
+func TestRar(t *testing.T) {
+	checkArchive(t, "test.rar", buildRar(testFiles))
+}
+
+func TestTarGz(t *testing.T) {
+	checkArchive(t, "test.tar.gz", buildTarGz(t, testFiles))
+}
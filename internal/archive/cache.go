@@ -0,0 +1,142 @@
+package archive
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/pkg/errors"
+)
+
+// the members of the compressed archives can only be read by decompressing them from the start,
+// so a ranged read of a member in the middle, such as seeking in a video, decompresses the member into a temp file,
+// and the following ranged reads are read from the file instead of decompressing again.
+const (
+	memberCacheCount   = 8
+	memberCacheMaxSize = 1024 * 1024 * 1024 // the larger members are decompressed for each ranged read
+)
+
+type memberFile struct {
+	e       *entry
+	once    sync.Once
+	name    string
+	err     error
+	refs    int
+	evicted bool
+}
+
+type memberCache struct {
+	sync.Mutex
+	// the most recently used last
+	files []*memberFile
+}
+
+var members memberCache
+
+// get returns the cached file of the entry, it's kept until released
+func (c *memberCache) get(e *entry) *memberFile {
+	c.Lock()
+	defer c.Unlock()
+	for i, f := range c.files {
+		if f.e == e {
+			c.files = append(append(c.files[:i:i], c.files[i+1:]...), f)
+			f.refs++
+			return f
+		}
+	}
+	f := &memberFile{e: e, refs: 1}
+	c.files = append(c.files, f)
+	for len(c.files) > memberCacheCount {
+		c.evict(c.files[0])
+	}
+	return f
+}
+
+// evict removes f from the cache, the temp file is removed when it's no longer read
+func (c *memberCache) evict(f *memberFile) {
+	for i := range c.files {
+		if c.files[i] == f {
+			c.files = append(c.files[:i:i], c.files[i+1:]...)
+			break
+		}
+	}
+	f.evicted = true
+	if f.refs == 0 && f.name != "" {
+		_ = os.Remove(f.name)
+	}
+}
+
+func (c *memberCache) release(f *memberFile) {
+	c.Lock()
+	defer c.Unlock()
+	f.refs--
+	if f.refs == 0 && f.evicted && f.name != "" {
+		_ = os.Remove(f.name)
+	}
+}
+
+// cachedOpen reads the range of the entry, read returns the content of the entry from the start
+func cachedOpen(e *entry, offset, length int64, read func() (io.ReadCloser, error)) (io.ReadCloser, error) {
+	if offset == 0 || e.obj.Size > memberCacheMaxSize {
+		rc, err := read()
+		if err != nil {
+			return nil, err
+		}
+		return skipRange(rc, offset, length)
+	}
+	f := members.get(e)
+	f.once.Do(func() {
+		f.name, f.err = fillTemp(read)
+	})
+	if f.err != nil {
+		members.Lock()
+		members.evict(f)
+		members.Unlock()
+		members.release(f)
+		return nil, f.err
+	}
+	file, err := os.Open(f.name)
+	if err != nil {
+		members.release(f)
+		return nil, errors.WithStack(err)
+	}
+	return readCloser{
+		Reader: io.NewSectionReader(file, offset, length),
+		Closer: closerFunc(func() error {
+			defer members.release(f)
+			return file.Close()
+		}),
+	}, nil
+}
+
+// fillTemp writes the content to a temp file and returns the name of the file
+func fillTemp(read func() (io.ReadCloser, error)) (string, error) {
+	rc, err := read()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	if err := os.MkdirAll(conf.Conf.TempDir, 0777); err != nil {
+		return "", errors.WithStack(err)
+	}
+	file, err := os.CreateTemp(conf.Conf.TempDir, "archive-*")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	_, err = io.Copy(file, rc)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", errors.WithStack(err)
+	}
+	return file.Name(), nil
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
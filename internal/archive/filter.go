@@ -0,0 +1,131 @@
+package archive
+
+import (
+	"io"
+)
+
+// deltaReader decodes the delta filter, each byte is stored as the difference to the byte dist bytes before
+type deltaReader struct {
+	r    io.Reader
+	dist int
+	hist [256]byte
+	pos  byte
+}
+
+func (d *deltaReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	for i := range p[:n] {
+		p[i] += d.hist[(d.dist+int(d.pos))&0xff]
+		d.hist[d.pos] = p[i]
+		d.pos--
+	}
+	return n, err
+}
+
+var (
+	x86AllowedMask = [8]bool{true, true, true, false, true, false, false, false}
+	x86MaskBit     = [8]uint32{0, 1, 2, 2, 3, 3, 3, 3}
+)
+
+const x86BufferSize = 64 * 1024
+
+// x86Reader decodes the bcj filter of x86, which converts the absolute addresses of the call and jump instructions
+// back to the relative ones, the last bytes which may be a part of an instruction are decoded with the following ones
+type x86Reader struct {
+	r   io.Reader
+	buf []byte
+	// the count of the decoded bytes at the start of buf
+	decoded int
+	// the position of buf in the output
+	pos      uint32
+	prevPos  uint32
+	prevMask uint32
+	err      error
+}
+
+func (x *x86Reader) Read(p []byte) (int, error) {
+	for x.decoded == 0 {
+		if x.err != nil {
+			// the rest is not filtered
+			if len(x.buf) == 0 {
+				return 0, x.err
+			}
+			x.decoded = len(x.buf)
+			break
+		}
+		if cap(x.buf) == 0 {
+			x.buf = make([]byte, 0, x86BufferSize)
+		}
+		n, err := x.r.Read(x.buf[len(x.buf):cap(x.buf)])
+		x.buf = x.buf[:len(x.buf)+n]
+		x.err = err
+		x.decoded = x.code(x.buf)
+	}
+	n := copy(p, x.buf[:x.decoded])
+	x.buf = x.buf[:copy(x.buf, x.buf[n:])]
+	x.decoded -= n
+	x.pos += uint32(n)
+	return n, nil
+}
+
+func test86(b byte) bool {
+	return b == 0 || b == 0xff
+}
+
+// code decodes buf at x.pos and returns the count of the decoded bytes, it's ported from the x86 filter of xz
+func (x *x86Reader) code(buf []byte) int {
+	if len(buf) < 5 {
+		return 0
+	}
+	if x.pos-x.prevPos > 5 {
+		x.prevPos = x.pos - 5
+	}
+	i := 0
+	for i <= len(buf)-5 {
+		b := buf[i]
+		if b != 0xe8 && b != 0xe9 {
+			i++
+			continue
+		}
+		offset := x.pos + uint32(i) - x.prevPos
+		x.prevPos = x.pos + uint32(i)
+		if offset > 5 {
+			x.prevMask = 0
+		} else {
+			for j := uint32(0); j < offset; j++ {
+				x.prevMask &= 0x77
+				x.prevMask <<= 1
+			}
+		}
+		b = buf[i+4]
+		if test86(b) && x86AllowedMask[(x.prevMask>>1)&7] && x.prevMask>>1 < 0x10 {
+			src := uint32(b)<<24 | uint32(buf[i+3])<<16 | uint32(buf[i+2])<<8 | uint32(buf[i+1])
+			var dest uint32
+			for {
+				dest = src - (x.pos + uint32(i) + 5)
+				if x.prevMask == 0 {
+					break
+				}
+				j := x86MaskBit[(x.prevMask>>1)&7]
+				b = byte(dest >> (24 - j*8))
+				if !test86(b) {
+					break
+				}
+				src = dest ^ (1<<(32-j*8) - 1)
+			}
+			buf[i+4] = ^byte((dest>>24)&1 - 1)
+			buf[i+3] = byte(dest >> 16)
+			buf[i+2] = byte(dest >> 8)
+			buf[i+1] = byte(dest)
+			i += 5
+			x.prevMask = 0
+		} else {
+			i++
+			x.prevMask |= 1
+			if test86(b) {
+				x.prevMask |= 0x10
+			}
+		}
+	}
+	return i
+}
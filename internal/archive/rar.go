@@ -0,0 +1,42 @@
+package archive
+
+import (
+	"io"
+
+	"github.com/nwaples/rardecode"
+	"github.com/pkg/errors"
+)
+
+// openRar reads the rar archive in order like a compressed tar, the solid archives can only be read this way,
+// the archives of multiple volumes and with encrypted headers are not supported
+func openRar(r io.ReaderAt, size int64) (*Archive, error) {
+	return openSequence(func() (sequence, error) {
+		rr, err := rardecode.NewReader(io.NewSectionReader(r, 0, size), "")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed read rar")
+		}
+		return rarSequence{rr}, nil
+	})
+}
+
+type rarSequence struct {
+	r *rardecode.Reader
+}
+
+func (s rarSequence) next() (*member, io.Reader, error) {
+	hdr, err := s.r.Next()
+	if err == io.EOF {
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed read rar")
+	}
+	if hdr.IsDir {
+		return &member{name: hdr.Name, modified: hdr.ModificationTime, isDir: true}, nil, nil
+	}
+	return &member{name: hdr.Name, size: hdr.UnPackedSize, modified: hdr.ModificationTime}, s.r, nil
+}
+
+func (s rarSequence) Close() error {
+	return nil
+}
@@ -0,0 +1,705 @@
+package archive
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/flate"
+	"encoding/binary"
+	"io"
+	"time"
+	"unicode/utf16"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/pkg/errors"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// the property ids of the 7z headers
+const (
+	szEnd             = 0x00
+	szHeader          = 0x01
+	szArchiveProps    = 0x02
+	szAdditional      = 0x03
+	szMainStreams     = 0x04
+	szFilesInfo       = 0x05
+	szPackInfo        = 0x06
+	szUnpackInfo      = 0x07
+	szSubStreamsInfo  = 0x08
+	szSize            = 0x09
+	szCRC             = 0x0a
+	szFolderID        = 0x0b
+	szCodersUnpack    = 0x0c
+	szNumUnpackStream = 0x0d
+	szEmptyStream     = 0x0e
+	szEmptyFile       = 0x0f
+	szName            = 0x11
+	szMTime           = 0x14
+	szEncodedHeader   = 0x17
+)
+
+var szSignature = []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}
+
+const (
+	szSignatureHeaderSize = 32
+	szMaxHeaderSize       = 64 * 1024 * 1024
+)
+
+type szCoder struct {
+	id         []byte
+	numIn      uint64
+	numOut     uint64
+	properties []byte
+}
+
+type szFolder struct {
+	coders []szCoder
+	// the offset of the packed stream in the archive
+	packOffset  int64
+	packSize    int64
+	unpackSizes []uint64
+	// the pairs of the input and the output bound to it
+	bindPairs [][2]uint64
+	// the index of the output of the folder in unpackSizes, the other outputs are bound to the inputs of the coders
+	mainOut    int
+	crcDefined bool
+	// the sizes of the files in the folder, the files are stored one after another
+	streams []int64
+}
+
+func (f *szFolder) unpackSize() int64 {
+	if f.mainOut >= len(f.unpackSizes) {
+		return 0
+	}
+	return int64(f.unpackSizes[f.mainOut])
+}
+
+// szMember is the data of a file entry, folder is -1 for an empty file
+type szMember struct {
+	folder int
+	offset int64
+}
+
+// open7z reads the headers at the end of the archive, the files compressed by copy, lzma, lzma2, deflate or bzip2
+// with the delta or bcj filter can be read, the others such as the encrypted ones are listed but can't be opened
+func open7z(r io.ReaderAt, size int64) (*Archive, error) {
+	start := make([]byte, szSignatureHeaderSize)
+	if _, err := r.ReadAt(start, 0); err != nil {
+		return nil, errors.Wrap(err, "failed read 7z signature header")
+	}
+	if !bytes.Equal(start[:6], szSignature) {
+		return nil, errors.New("not a 7z archive")
+	}
+	headerOffset := binary.LittleEndian.Uint64(start[12:20])
+	headerSize := binary.LittleEndian.Uint64(start[20:28])
+	if headerSize > szMaxHeaderSize || headerOffset > uint64(size) ||
+		szSignatureHeaderSize+headerOffset+headerSize > uint64(size) {
+		return nil, errors.New("invalid 7z header")
+	}
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, int64(szSignatureHeaderSize+headerOffset)); err != nil {
+		return nil, errors.Wrap(err, "failed read 7z header")
+	}
+	z := &sevenZip{r: r, size: size}
+	for {
+		hr := &szReader{b: header}
+		switch hr.byte() {
+		case szHeader:
+			if err := z.readHeader(hr); err != nil {
+				return nil, err
+			}
+			return z.archive()
+		case szEncodedHeader:
+			// the header is packed as the streams
+			folders, err := z.readStreamsInfo(hr)
+			if err != nil {
+				return nil, err
+			}
+			if len(folders) == 0 || folders[0].unpackSize() > szMaxHeaderSize {
+				return nil, errors.New("invalid 7z encoded header")
+			}
+			rc, err := z.decode(folders[0])
+			if err != nil {
+				return nil, errors.WithMessage(err, "failed decode 7z header")
+			}
+			header, err = io.ReadAll(io.LimitReader(rc, folders[0].unpackSize()))
+			_ = rc.Close()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed decode 7z header")
+			}
+		default:
+			return nil, errors.New("invalid 7z header")
+		}
+	}
+}
+
+type sevenZip struct {
+	r       io.ReaderAt
+	size    int64
+	folders []*szFolder
+	files   []szFile
+}
+
+type szFile struct {
+	name        string
+	modified    time.Time
+	emptyStream bool
+	emptyFile   bool
+}
+
+func (z *sevenZip) readHeader(r *szReader) error {
+	id := r.byte()
+	if id == szArchiveProps {
+		for r.byte() != szEnd && r.err == nil {
+			r.bytes(r.number())
+		}
+		id = r.byte()
+	}
+	if id == szAdditional {
+		return errors.WithMessage(errs.NotSupport, "the additional streams of 7z")
+	}
+	if id == szMainStreams {
+		folders, err := z.readStreamsInfo(r)
+		if err != nil {
+			return err
+		}
+		z.folders = folders
+		id = r.byte()
+	}
+	if id == szFilesInfo {
+		if err := z.readFilesInfo(r); err != nil {
+			return err
+		}
+		id = r.byte()
+	}
+	if r.err != nil || id != szEnd {
+		return errors.New("invalid 7z header")
+	}
+	return nil
+}
+
+func (z *sevenZip) readStreamsInfo(r *szReader) ([]*szFolder, error) {
+	var packOffset int64
+	var packSizes []int64
+	var folders []*szFolder
+	id := r.byte()
+	if id == szPackInfo {
+		packOffset = szSignatureHeaderSize + int64(r.number())
+		packSizes = make([]int64, r.count())
+		for id = r.byte(); id != szEnd && r.err == nil; id = r.byte() {
+			switch id {
+			case szSize:
+				for i := range packSizes {
+					packSizes[i] = int64(r.number())
+				}
+			case szCRC:
+				r.digests(len(packSizes))
+			default:
+				r.bytes(r.number())
+			}
+		}
+		id = r.byte()
+	}
+	if id == szUnpackInfo {
+		if r.byte() != szFolderID {
+			return nil, errors.New("invalid 7z unpack info")
+		}
+		folders = make([]*szFolder, r.count())
+		if r.byte() != 0 {
+			return nil, errors.WithMessage(errs.NotSupport, "the external folders of 7z")
+		}
+		packIndex := 0
+		for i := range folders {
+			f, numPacked := r.folder()
+			if r.err != nil {
+				break
+			}
+			// only the folders of a single packed stream are supported, the others are listed only
+			if packIndex < len(packSizes) {
+				f.packOffset = packOffset
+				f.packSize = packSizes[packIndex]
+			}
+			for j := 0; j < numPacked && packIndex < len(packSizes); j++ {
+				packOffset += packSizes[packIndex]
+				packIndex++
+			}
+			folders[i] = f
+		}
+		if r.err != nil || r.byte() != szCodersUnpack {
+			return nil, errors.New("invalid 7z unpack info")
+		}
+		for _, f := range folders {
+			for j := range f.unpackSizes {
+				f.unpackSizes[j] = r.number()
+			}
+			// a folder has one file unless the substreams info says
+			f.streams = []int64{f.unpackSize()}
+		}
+		for id = r.byte(); id != szEnd && r.err == nil; id = r.byte() {
+			if id == szCRC {
+				for i, ok := range r.digests(len(folders)) {
+					folders[i].crcDefined = ok
+				}
+				continue
+			}
+			r.bytes(r.number())
+		}
+		id = r.byte()
+	}
+	if id == szSubStreamsInfo {
+		id = r.byte()
+		if id == szNumUnpackStream {
+			for _, f := range folders {
+				f.streams = make([]int64, r.count())
+			}
+			id = r.byte()
+		}
+		// the size of the last file in a folder is the rest of the folder
+		hasSizes := id == szSize
+		for _, f := range folders {
+			if len(f.streams) == 0 {
+				continue
+			}
+			var sum int64
+			for j := 0; j < len(f.streams)-1; j++ {
+				if hasSizes {
+					f.streams[j] = int64(r.number())
+				}
+				sum += f.streams[j]
+			}
+			f.streams[len(f.streams)-1] = f.unpackSize() - sum
+		}
+		if hasSizes {
+			id = r.byte()
+		}
+		for ; id != szEnd && r.err == nil; id = r.byte() {
+			if id != szCRC {
+				r.bytes(r.number())
+				continue
+			}
+			// the crcs of the folders of a single file are already read
+			n := 0
+			for _, f := range folders {
+				if len(f.streams) != 1 || !f.crcDefined {
+					n += len(f.streams)
+				}
+			}
+			r.digests(n)
+		}
+		id = r.byte()
+	}
+	if r.err != nil || id != szEnd {
+		return nil, errors.New("invalid 7z streams info")
+	}
+	for _, f := range folders {
+		if f.packOffset+f.packSize > z.size {
+			return nil, errors.New("invalid 7z streams info")
+		}
+		for _, s := range f.streams {
+			if s < 0 {
+				return nil, errors.New("invalid 7z streams info")
+			}
+		}
+	}
+	return folders, nil
+}
+
+func (z *sevenZip) readFilesInfo(r *szReader) error {
+	z.files = make([]szFile, r.count())
+	var numEmpty int
+	for id := r.byte(); id != szEnd && r.err == nil; id = r.byte() {
+		pr := &szReader{b: r.bytes(r.number())}
+		switch id {
+		case szEmptyStream:
+			for i, empty := range pr.bits(len(z.files)) {
+				z.files[i].emptyStream = empty
+				if empty {
+					numEmpty++
+				}
+			}
+		case szEmptyFile:
+			i := 0
+			for _, empty := range pr.bits(numEmpty) {
+				for i < len(z.files) && !z.files[i].emptyStream {
+					i++
+				}
+				if i == len(z.files) {
+					break
+				}
+				z.files[i].emptyFile = empty
+				i++
+			}
+		case szName:
+			if pr.byte() != 0 {
+				return errors.WithMessage(errs.NotSupport, "the external names of 7z")
+			}
+			for i := range z.files {
+				z.files[i].name = pr.utf16()
+			}
+		case szMTime:
+			defined := pr.defined(len(z.files))
+			if pr.byte() != 0 {
+				return errors.WithMessage(errs.NotSupport, "the external times of 7z")
+			}
+			for i, ok := range defined {
+				if ok {
+					z.files[i].modified = fileTime(pr.uint64())
+				}
+			}
+		}
+		if pr.err != nil {
+			r.err = pr.err
+		}
+	}
+	if r.err != nil {
+		return errors.New("invalid 7z files info")
+	}
+	return nil
+}
+
+// archive indexes the files, the files of streams are in the folders in order
+func (z *sevenZip) archive() (*Archive, error) {
+	a := newArchive()
+	folder, stream := 0, 0
+	var offset int64
+	for _, f := range z.files {
+		if f.emptyStream {
+			a.add(f.name, 0, f.modified, !f.emptyFile, &szMember{folder: -1})
+			continue
+		}
+		for folder < len(z.folders) && stream >= len(z.folders[folder].streams) {
+			folder, stream, offset = folder+1, 0, 0
+		}
+		if folder >= len(z.folders) {
+			return nil, errors.New("invalid 7z files info")
+		}
+		size := z.folders[folder].streams[stream]
+		a.add(f.name, size, f.modified, false, &szMember{folder: folder, offset: offset})
+		stream, offset = stream+1, offset+size
+	}
+	a.sort()
+	a.open = func(e *entry, offset, length int64) (io.ReadCloser, error) {
+		m := e.data.(*szMember)
+		if m.folder < 0 {
+			return io.NopCloser(bytes.NewReader(nil)), nil
+		}
+		f := z.folders[m.folder]
+		// the stored files are read directly
+		if len(f.coders) == 1 && bytes.Equal(f.coders[0].id, []byte{0x00}) {
+			return io.NopCloser(io.NewSectionReader(z.r, f.packOffset+m.offset+offset, length)), nil
+		}
+		return cachedOpen(e, offset, length, func() (io.ReadCloser, error) {
+			rc, err := z.decode(f)
+			if err != nil {
+				return nil, err
+			}
+			return skipRange(rc, m.offset, e.obj.Size)
+		})
+	}
+	// the files of a folder are read by decoding the folder once
+	a.walk = func(match func(e *entry) bool, fn func(e *entry, r io.Reader) error) error {
+		folder := -1
+		var pos int64
+		var rc io.ReadCloser
+		defer func() {
+			if rc != nil {
+				_ = rc.Close()
+			}
+		}()
+		for _, e := range a.files {
+			if !match(e) {
+				continue
+			}
+			m := e.data.(*szMember)
+			if e.obj.IsDir() {
+				if err := fn(e, nil); err != nil {
+					return err
+				}
+				continue
+			}
+			if m.folder < 0 {
+				if err := fn(e, bytes.NewReader(nil)); err != nil {
+					return err
+				}
+				continue
+			}
+			if m.folder != folder || m.offset < pos {
+				if rc != nil {
+					_ = rc.Close()
+				}
+				var err error
+				rc, err = z.decode(z.folders[m.folder])
+				if err != nil {
+					return err
+				}
+				folder, pos = m.folder, 0
+			}
+			if _, err := io.CopyN(io.Discard, rc, m.offset-pos); err != nil {
+				return errors.WithStack(err)
+			}
+			lr := &io.LimitedReader{R: rc, N: e.obj.Size}
+			if err := fn(e, lr); err != nil {
+				return err
+			}
+			pos = m.offset + e.obj.Size - lr.N
+		}
+		return nil
+	}
+	return a, nil
+}
+
+// decode returns the output of the folder, the coders of a single input and output are chained by the bind pairs,
+// such as the bcj filter of the lzma output
+func (z *sevenZip) decode(f *szFolder) (io.ReadCloser, error) {
+	for _, c := range f.coders {
+		if c.numIn != 1 || c.numOut != 1 {
+			return nil, errors.WithMessage(errs.NotSupport, "the 7z coder of multiple streams")
+		}
+	}
+	r, err := z.decodeCoder(f, f.mainOut, 0)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(r), nil
+}
+
+// decodeCoder returns the output of the coder i, the index of the input and output of a coder is the index of the coder
+func (z *sevenZip) decodeCoder(f *szFolder, i, depth int) (io.Reader, error) {
+	if depth >= len(f.coders) {
+		return nil, errors.New("invalid 7z bind pairs")
+	}
+	var in io.Reader = io.NewSectionReader(z.r, f.packOffset, f.packSize)
+	for _, pair := range f.bindPairs {
+		if pair[0] == uint64(i) {
+			var err error
+			if in, err = z.decodeCoder(f, int(pair[1]), depth+1); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	c := f.coders[i]
+	size := int64(f.unpackSizes[i])
+	switch string(c.id) {
+	case "\x00":
+		return in, nil
+	case "\x03\x01\x01":
+		if len(c.properties) != 5 {
+			return nil, errors.New("invalid lzma properties")
+		}
+		// the properties of the coder and the unpacked size make the header of the lzma stream
+		header := make([]byte, 13)
+		copy(header, c.properties)
+		binary.LittleEndian.PutUint64(header[5:], uint64(size))
+		r, err := lzma.NewReader(io.MultiReader(bytes.NewReader(header), in))
+		return r, errors.Wrap(err, "failed read lzma")
+	case "\x21":
+		if len(c.properties) != 1 || c.properties[0] > 40 {
+			return nil, errors.New("invalid lzma2 properties")
+		}
+		dictCap := int64(lzma.MaxDictCap)
+		if p := c.properties[0]; p < 40 {
+			dictCap = int64(2|p&1) << (p/2 + 11)
+		}
+		// the dictionary is never larger than the output
+		if dictCap > size {
+			dictCap = size
+		}
+		if dictCap < lzma.MinDictCap {
+			dictCap = lzma.MinDictCap
+		}
+		r, err := lzma.Reader2Config{DictCap: int(dictCap)}.NewReader2(in)
+		return r, errors.Wrap(err, "failed read lzma2")
+	case "\x04\x01\x08":
+		return flate.NewReader(in), nil
+	case "\x04\x02\x02":
+		return bzip2.NewReader(in), nil
+	case "\x03":
+		if len(c.properties) != 1 {
+			return nil, errors.New("invalid delta properties")
+		}
+		return &deltaReader{r: in, dist: int(c.properties[0]) + 1}, nil
+	case "\x03\x03\x01\x03":
+		return &x86Reader{r: in, prevPos: ^uint32(4)}, nil
+	case "\x06\xf1\x07\x01":
+		return nil, errors.WithMessage(errs.NotSupport, "the encrypted 7z")
+	default:
+		return nil, errors.WithMessagef(errs.NotSupport, "the 7z coder %x", c.id)
+	}
+}
+
+// fileTime converts the windows file time of 100 nanoseconds since 1601
+func fileTime(t uint64) time.Time {
+	const epochDiff = 116444736000000000
+	return time.Unix(0, (int64(t)-epochDiff)*100)
+}
+
+// szReader reads the 7z header, the error is kept and the following reads return zero values
+type szReader struct {
+	b   []byte
+	err error
+}
+
+func (r *szReader) bytes(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > uint64(len(r.b)) {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *szReader) byte() byte {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *szReader) uint64() uint64 {
+	b := r.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// number reads the number of variable length, the count of the leading 1 bits of the first byte is the count of the following bytes
+func (r *szReader) number() uint64 {
+	first := r.byte()
+	var value uint64
+	mask := byte(0x80)
+	for i := 0; i < 8; i++ {
+		if first&mask == 0 {
+			return value | uint64(first&(mask-1))<<(8*i)
+		}
+		value |= uint64(r.byte()) << (8 * i)
+		mask >>= 1
+	}
+	return value
+}
+
+// count reads the count of the items, each item takes at least one byte of the header
+func (r *szReader) count() int {
+	n := r.number()
+	if n > uint64(len(r.b)) {
+		if r.err == nil {
+			r.err = errors.New("invalid count")
+		}
+		return 0
+	}
+	return int(n)
+}
+
+func (r *szReader) bits(n int) []bool {
+	b := r.bytes(uint64((n + 7) / 8))
+	if b == nil {
+		return make([]bool, n)
+	}
+	bits := make([]bool, n)
+	for i := range bits {
+		bits[i] = b[i/8]&(0x80>>(i%8)) != 0
+	}
+	return bits
+}
+
+// defined reads which of the n items are defined
+func (r *szReader) defined(n int) []bool {
+	if r.byte() != 0 {
+		bits := make([]bool, n)
+		for i := range bits {
+			bits[i] = true
+		}
+		return bits
+	}
+	return r.bits(n)
+}
+
+// digests skips the crcs of the n items and returns which of them are defined
+func (r *szReader) digests(n int) []bool {
+	defined := r.defined(n)
+	for _, ok := range defined {
+		if ok {
+			r.bytes(4)
+		}
+	}
+	return defined
+}
+
+// utf16 reads a string of utf-16le ending with zero
+func (r *szReader) utf16() string {
+	var s []uint16
+	for r.err == nil {
+		b := r.bytes(2)
+		if b == nil {
+			break
+		}
+		c := binary.LittleEndian.Uint16(b)
+		if c == 0 {
+			break
+		}
+		s = append(s, c)
+	}
+	return string(utf16.Decode(s))
+}
+
+// folder reads the coders of a folder and returns the count of its packed streams
+func (r *szReader) folder() (*szFolder, int) {
+	f := &szFolder{coders: make([]szCoder, r.count())}
+	if len(f.coders) == 0 {
+		r.err = errors.New("invalid 7z folder")
+		return nil, 0
+	}
+	var numIn, numOut uint64
+	for i := range f.coders {
+		flag := r.byte()
+		c := szCoder{id: r.bytes(uint64(flag & 0x0f)), numIn: 1, numOut: 1}
+		if flag&0x10 != 0 {
+			c.numIn, c.numOut = r.number(), r.number()
+		}
+		if flag&0x20 != 0 {
+			c.properties = r.bytes(r.number())
+		}
+		if flag&0x80 != 0 || c.numOut == 0 || c.numOut > 32 || c.numIn > 32 {
+			r.err = errors.New("invalid 7z coder")
+			return nil, 0
+		}
+		f.coders[i] = c
+		numIn += c.numIn
+		numOut += c.numOut
+	}
+	// the bind pairs connect the outputs to the inputs of the coders
+	numBindPairs := numOut - 1
+	bound := make([]bool, numOut)
+	for i := uint64(0); i < numBindPairs && r.err == nil; i++ {
+		in, out := r.number(), r.number()
+		if in >= numIn || out >= numOut {
+			r.err = errors.New("invalid 7z bind pair")
+			return nil, 0
+		}
+		f.bindPairs = append(f.bindPairs, [2]uint64{in, out})
+		bound[out] = true
+	}
+	for i, ok := range bound {
+		if !ok {
+			f.mainOut = i
+			break
+		}
+	}
+	if numIn < numBindPairs {
+		r.err = errors.New("invalid 7z folder")
+		return nil, 0
+	}
+	numPacked := numIn - numBindPairs
+	if numPacked > 1 {
+		for i := uint64(0); i < numPacked; i++ {
+			r.number()
+		}
+	}
+	f.unpackSizes = make([]uint64, numOut)
+	return f, int(numPacked)
+}
@@ -0,0 +1,95 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// openTar reads the headers of the archive, the file contents are skipped by seeking
+func openTar(r io.ReaderAt, size int64) (*Archive, error) {
+	sr := io.NewSectionReader(r, 0, size)
+	a := newArchive()
+	tr := tar.NewReader(sr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed read tar")
+		}
+		// the content of an entry starts right after its header
+		offset, err := sr.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		addTarEntry(a, hdr, offset)
+	}
+	a.sort()
+	a.open = func(e *entry, offset, length int64) (io.ReadCloser, error) {
+		return io.NopCloser(io.NewSectionReader(r, e.data.(int64)+offset, length)), nil
+	}
+	return a, nil
+}
+
+func addTarEntry(a *Archive, hdr *tar.Header, data interface{}) {
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		a.add(hdr.Name, 0, hdr.ModTime, true, data)
+	case tar.TypeReg, tar.TypeRegA:
+		a.add(hdr.Name, hdr.Size, hdr.ModTime, false, data)
+	}
+}
+
+type decompressor func(r io.Reader) (io.ReadCloser, error)
+
+func openTarGz(r io.ReaderAt, size int64) (*Archive, error) {
+	return openCompressedTar(r, size, func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	})
+}
+
+func openTarBz2(r io.ReaderAt, size int64) (*Archive, error) {
+	return openCompressedTar(r, size, func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	})
+}
+
+// openCompressedTar has to decompress the whole archive to read the headers,
+// and reading a member decompresses the archive until the member
+func openCompressedTar(r io.ReaderAt, size int64, decompress decompressor) (*Archive, error) {
+	return openSequence(func() (sequence, error) {
+		dr, err := decompress(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed decompress tar")
+		}
+		return &tarSequence{tr: tar.NewReader(dr), Closer: dr}, nil
+	})
+}
+
+type tarSequence struct {
+	tr *tar.Reader
+	io.Closer
+}
+
+func (s *tarSequence) next() (*member, io.Reader, error) {
+	for {
+		hdr, err := s.tr.Next()
+		if err == io.EOF {
+			return nil, nil, err
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed read tar")
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			return &member{name: hdr.Name, modified: hdr.ModTime, isDir: true}, nil, nil
+		case tar.TypeReg, tar.TypeRegA:
+			return &member{name: hdr.Name, size: hdr.Size, modified: hdr.ModTime}, s.tr, nil
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+)
+
+// Writer writes the files into an archive, the archive is streamed to the underlying writer
+type Writer interface {
+	// Add writes the file or folder at path of the archive, r is nil for a folder
+	Add(path string, obj model.Obj, r io.Reader) error
+	// Close finishes the archive, it doesn't close the underlying writer
+	Close() error
+}
+
+// NewWriter returns a writer of the archive format, zip or tar.gz
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatZip:
+		return &zipWriter{zw: zip.NewWriter(w)}, nil
+	case FormatTarGz:
+		gw := gzip.NewWriter(w)
+		return &tarGzWriter{gw: gw, tw: tar.NewWriter(gw)}, nil
+	default:
+		return nil, errors.Errorf("unsupported archive format: %s", format)
+	}
+}
+
+// archiveName is the name of the file or folder in the archive, without the leading slash
+func archiveName(path string) string {
+	return strings.TrimPrefix(utils.FixAndCleanPath(path), "/")
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipWriter) Add(path string, obj model.Obj, r io.Reader) error {
+	header := &zip.FileHeader{
+		Name:     archiveName(path),
+		Method:   zip.Deflate,
+		Modified: obj.ModTime(),
+	}
+	if obj.IsDir() {
+		header.Name += "/"
+		header.Method = zip.Store
+		header.SetMode(os.ModeDir | 0755)
+	} else {
+		header.SetMode(0644)
+	}
+	w, err := z.zw.CreateHeader(header)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if obj.IsDir() {
+		return nil
+	}
+	_, err = utils.CopyWithBuffer(w, r)
+	return errors.WithStack(err)
+}
+
+func (z *zipWriter) Close() error {
+	return errors.WithStack(z.zw.Close())
+}
+
+type tarGzWriter struct {
+	gw *gzip.Writer
+	tw *tar.Writer
+}
+
+func (t *tarGzWriter) Add(path string, obj model.Obj, r io.Reader) error {
+	header := &tar.Header{
+		Name:    archiveName(path),
+		ModTime: obj.ModTime(),
+	}
+	if obj.IsDir() {
+		header.Name += "/"
+		header.Typeflag = tar.TypeDir
+		header.Mode = 0755
+	} else {
+		header.Typeflag = tar.TypeReg
+		header.Mode = 0644
+		header.Size = obj.GetSize()
+	}
+	if err := t.tw.WriteHeader(header); err != nil {
+		return errors.WithStack(err)
+	}
+	if obj.IsDir() {
+		return nil
+	}
+	_, err := utils.CopyWithBuffer(t.tw, r)
+	return errors.WithStack(err)
+}
+
+func (t *tarGzWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(t.gw.Close())
+}
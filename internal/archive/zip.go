@@ -0,0 +1,43 @@
+package archive
+
+import (
+	"archive/zip"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// openZip reads the central directory at the end of the archive, the members are read on demand
+func openZip(r io.ReaderAt, size int64) (*Archive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed read zip")
+	}
+	a := newArchive()
+	for _, f := range zr.File {
+		a.add(f.Name, int64(f.UncompressedSize64), f.Modified, f.FileInfo().IsDir(), f)
+	}
+	a.sort()
+	a.open = func(e *entry, offset, length int64) (io.ReadCloser, error) {
+		f := e.data.(*zip.File)
+		// the stored members can be read from the offset directly
+		if f.Method == zip.Store {
+			raw, err := f.OpenRaw()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if s, ok := raw.(io.ReadSeeker); ok {
+				if _, err := s.Seek(offset, io.SeekStart); err != nil {
+					return nil, errors.WithStack(err)
+				}
+				return io.NopCloser(io.LimitReader(s, length)), nil
+			}
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return skipRange(rc, offset, length)
+	}
+	return a, nil
+}
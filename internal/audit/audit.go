@@ -0,0 +1,53 @@
+// Package audit records the mutating operations and the login attempts,
+// the user, ip and protocol of an operation are taken from its context.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	log "github.com/sirupsen/logrus"
+)
+
+// Log records the action on path done in ctx, err is the result of the action
+func Log(ctx context.Context, action, path, target string, err error) {
+	l := &model.AuditLog{
+		Action:  action,
+		Path:    path,
+		Target:  target,
+		Success: err == nil,
+		Created: time.Now(),
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok && user != nil {
+		l.UserID = user.ID
+		l.Username = user.Username
+	}
+	l.IP, _ = ctx.Value(conf.ClientIPKey).(string)
+	l.Protocol, _ = ctx.Value(conf.ProtocolKey).(string)
+	if err != nil {
+		l.Error = err.Error()
+	}
+	if err := db.CreateAuditLog(l); err != nil {
+		log.Errorf("failed create audit log: %+v", err)
+	}
+}
+
+// Login records a login attempt of username, the user isn't in ctx yet
+func Login(ctx context.Context, username string, err error) {
+	Log(context.WithValue(ctx, "user", &model.User{Username: username}), model.AuditLogin, "", "", err)
+}
+
+// CleanExpired deletes the audit logs older than the retention days
+func CleanExpired() {
+	days := setting.GetInt(conf.AuditLogRetention, 90)
+	if days <= 0 {
+		return
+	}
+	if err := db.DeleteAuditLogsBefore(time.Now().AddDate(0, 0, -days)); err != nil {
+		log.Errorf("failed clean expired audit logs: %+v", err)
+	}
+}
@@ -0,0 +1,170 @@
+// Package bandwidth limits the transfer rate of proxied downloads and uploads
+// with token buckets, the limits can be set globally, per user, per storage and per client ip.
+package bandwidth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"golang.org/x/time/rate"
+)
+
+// chunkSize is the max bytes waited at once, the burst of a limiter is never less than it
+const chunkSize = 32 * 1024
+
+const (
+	// expiration of an unused limiter
+	expiration = 10 * time.Minute
+	// touchInterval is how often a transfer refreshes the expiration of its limiters
+	touchInterval = time.Minute
+)
+
+var (
+	mu       sync.Mutex
+	global   *Limiter
+	limiters = cache.NewMemCache(cache.WithShards[*Limiter](16))
+)
+
+// Limiter is a token bucket shared by the transfers with the same key
+type Limiter struct {
+	*rate.Limiter
+	key string
+	// touched is the unix time of the last refresh of the expiration
+	touched atomic.Int64
+}
+
+// touch keeps the limiter in the cache while a transfer is using it, or a new
+// bucket would be created for the key and the rate of the key doubled
+func (l *Limiter) touch() {
+	if l.key == "" {
+		return
+	}
+	now := time.Now().Unix()
+	last := l.touched.Load()
+	if now-last < int64(touchInterval/time.Second) || !l.touched.CompareAndSwap(last, now) {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	limiters.Set(l.key, l, cache.WithEx[*Limiter](expiration))
+}
+
+// Limiters are the token buckets a transfer has to pass, all of them are waited
+type Limiters []*Limiter
+
+// WaitN blocks until n bytes are allowed by all limiters
+func (l Limiters) WaitN(ctx context.Context, n int) error {
+	for n > 0 {
+		size := n
+		if size > chunkSize {
+			size = chunkSize
+		}
+		for _, limiter := range l {
+			limiter.touch()
+			if err := limiter.WaitN(ctx, size); err != nil {
+				return err
+			}
+		}
+		n -= size
+	}
+	return nil
+}
+
+// Get returns the limiters of a transfer, user and storage can be nil if unknown.
+// limits are in KB/s, a user limit of 0 means the default user limit and negative means no limit.
+func Get(user *model.User, storage *model.Storage, ip string) Limiters {
+	var res Limiters
+	if limiter := getGlobal(int64(setting.GetInt(conf.BandwidthLimit, 0))); limiter != nil {
+		res = append(res, limiter)
+	}
+	if user != nil {
+		limit := user.BandwidthLimit
+		if limit == 0 {
+			limit = int64(setting.GetInt(conf.UserBandwidthLimit, 0))
+		}
+		if limiter := get(fmt.Sprintf("user:%d", user.ID), limit); limiter != nil {
+			res = append(res, limiter)
+		}
+	}
+	if storage != nil {
+		if limiter := get(fmt.Sprintf("storage:%d", storage.ID), storage.BandwidthLimit); limiter != nil {
+			res = append(res, limiter)
+		}
+	}
+	if ip != "" {
+		if limiter := get("ip:"+ip, int64(setting.GetInt(conf.IPBandwidthLimit, 0))); limiter != nil {
+			res = append(res, limiter)
+		}
+	}
+	return res
+}
+
+// GetByPath is Get with the storage of the path
+func GetByPath(user *model.User, path string, ip string) Limiters {
+	var s *model.Storage
+	storage, _, err := op.GetStorageAndActualPath(path)
+	if err == nil {
+		s = storage.GetStorage()
+	}
+	return Get(user, s, ip)
+}
+
+func getGlobal(limit int64) *Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if global == nil {
+		global = newLimiter("", limit)
+	} else {
+		setLimit(global.Limiter, limit)
+	}
+	return global
+}
+
+func get(key string, limit int64) *Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	limiter, ok := limiters.Get(key)
+	if ok {
+		setLimit(limiter.Limiter, limit)
+	} else {
+		limiter = newLimiter(key, limit)
+	}
+	// the expiration is refreshed by every transfer using the limiter, see touch
+	limiter.touched.Store(time.Now().Unix())
+	limiters.Set(key, limiter, cache.WithEx[*Limiter](expiration))
+	return limiter
+}
+
+func newLimiter(key string, limit int64) *Limiter {
+	return &Limiter{Limiter: rate.NewLimiter(rate.Limit(limit*1024), burst(limit)), key: key}
+}
+
+// setLimit applies the changed settings to an existing limiter
+func setLimit(limiter *rate.Limiter, limit int64) {
+	if limiter.Limit() != rate.Limit(limit*1024) {
+		limiter.SetLimit(rate.Limit(limit * 1024))
+		limiter.SetBurst(burst(limit))
+	}
+}
+
+// burst allows one second of transfer at most
+func burst(limit int64) int {
+	if limit*1024 < chunkSize {
+		return chunkSize
+	}
+	return int(limit * 1024)
+}
@@ -0,0 +1,23 @@
+package bandwidth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterKeptWhileInUse(t *testing.T) {
+	limiter := get("test:1", 1024)
+	if get("test:1", 1024) != limiter {
+		t.Fatalf("the limiter of a key should be shared")
+	}
+	// the entry expires while a long transfer still uses the limiter
+	limiters.Del("test:1")
+	limiter.touched.Store(time.Now().Add(-touchInterval).Unix())
+	if err := (Limiters{limiter}).WaitN(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if l, ok := limiters.Get("test:1"); !ok || l != limiter {
+		t.Errorf("the limiter in use should be kept for the new transfers of the key")
+	}
+}
@@ -0,0 +1,87 @@
+package bandwidth
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+type reader struct {
+	ctx      context.Context
+	r        io.Reader
+	limiters Limiters
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if len(p) > chunkSize {
+		p = p[:chunkSize]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if e := r.limiters.WaitN(r.ctx, n); e != nil {
+			return n, e
+		}
+	}
+	return n, err
+}
+
+// NewReader limits the read rate of r
+func NewReader(ctx context.Context, r io.Reader, limiters Limiters) io.Reader {
+	if len(limiters) == 0 {
+		return r
+	}
+	return &reader{ctx: ctx, r: r, limiters: limiters}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// NewReadCloser limits the read rate of rc, such as a request body
+func NewReadCloser(ctx context.Context, rc io.ReadCloser, limiters Limiters) io.ReadCloser {
+	if len(limiters) == 0 {
+		return rc
+	}
+	return readCloser{Reader: NewReader(ctx, rc, limiters), Closer: rc}
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	ctx      context.Context
+	limiters Limiters
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		size := len(p)
+		if size > chunkSize {
+			size = chunkSize
+		}
+		if err := w.limiters.WaitN(w.ctx, size); err != nil {
+			return written, err
+		}
+		n, err := w.ResponseWriter.Write(p[:size])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[size:]
+	}
+	return written, nil
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// NewResponseWriter limits the write rate of w
+func NewResponseWriter(ctx context.Context, w http.ResponseWriter, limiters Limiters) http.ResponseWriter {
+	if len(limiters) == 0 {
+		return w
+	}
+	return &responseWriter{ResponseWriter: w, ctx: ctx, limiters: limiters}
+}
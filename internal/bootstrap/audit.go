@@ -0,0 +1,13 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+// InitAuditCleaner deletes the audit logs out of the retention hourly
+func InitAuditCleaner() {
+	cron.NewCron(time.Hour).Do(audit.CleanExpired)
+}
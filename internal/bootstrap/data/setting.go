@@ -144,6 +144,15 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.ForwardDirectLinkParams, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL},
 		{Key: conf.IgnoreDirectLinkParams, Value: "sign,alist_ts", Type: conf.TypeString, Group: model.GLOBAL},
 		{Key: conf.WebauthnLoginEnabled, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PUBLIC},
+		{Key: conf.RecycleBinRetention, Value: "30", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE},
+		{Key: conf.BandwidthLimit, Value: "0", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE},
+		{Key: conf.UserBandwidthLimit, Value: "0", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE},
+		{Key: conf.IPBandwidthLimit, Value: "0", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE},
+		{Key: conf.AuditLogRetention, Value: "90", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE},
+		{Key: conf.MetricsToken, Value: "", Type: conf.TypeString, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `the token of /metrics, empty to disable it`},
+		{Key: conf.StorageHealthCheckInterval, Value: "5", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `minutes between the health checks of each storage, 0 to disable them`},
+		{Key: conf.ThumbnailConcurrency, Value: "2", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `the max number of thumbnails generated at the same time, 0 to disable the thumbnails`},
+		{Key: conf.ThumbnailMaxSize, Value: "20", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `MB, the max size of the images to generate thumbnails for, and of the beginning of the videos read for their snapshots`},
 
 		// single settings
 		{Key: conf.Token, Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE},
@@ -151,6 +160,12 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.AutoUpdateIndex, Value: "false", Type: conf.TypeBool, Group: model.INDEX},
 		{Key: conf.IgnorePaths, Value: "", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line`},
 		{Key: conf.MaxIndexDepth, Value: "20", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `max depth of index`},
+		{Key: conf.IndexRebuildPaths, Value: "", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line, the index of them is rebuilt periodically`},
+		{Key: conf.IndexRebuildInterval, Value: "0", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `hours between the rebuilds of the index of the rebuild paths, 0 to disable it`},
+		{Key: conf.IndexContent, Value: "false", Type: conf.TypeBool, Group: model.INDEX, Flag: model.PRIVATE, Help: `index the contents of the text, docx and pdf files (pdf needs pdftotext installed), only for bleve and meilisearch`},
+		{Key: conf.IndexContentMaxSize, Value: "10", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `MB, the max size of the files to index the contents of`},
+		{Key: conf.IndexContentIncludePaths, Value: "", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line, the contents are indexed only under them, empty for all the paths`},
+		{Key: conf.IndexContentExcludePaths, Value: "", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line, the contents are not indexed under them`},
 		{Key: conf.IndexProgress, Value: "{}", Type: conf.TypeText, Group: model.SINGLE, Flag: model.PRIVATE},
 
 		// SSO settings
@@ -183,6 +198,7 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.S3AccessKeyId, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
 		{Key: conf.S3SecretAccessKey, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
 		{Key: conf.S3Buckets, Value: "[]", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
+		{Key: conf.S3User, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
 	}
 	initialSettingItems = append(initialSettingItems, tool.Tools.Items()...)
 	if flags.Dev {
@@ -0,0 +1,15 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/health"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+// InitHealthChecker checks the storages which are due every minute
+// and deletes the expired health history hourly
+func InitHealthChecker() {
+	cron.NewCron(time.Minute).Do(health.Check)
+	cron.NewCron(time.Hour).Do(health.CleanHistory)
+}
@@ -3,13 +3,26 @@ package bootstrap
 import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/mirror"
 	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/xhofe/tache"
 )
 
 func InitTaskManager() {
 	fs.UploadTaskManager = tache.NewManager[*fs.UploadTask](tache.WithWorks(conf.Conf.Tasks.Upload.Workers), tache.WithMaxRetry(conf.Conf.Tasks.Upload.MaxRetry))
 	fs.CopyTaskManager = tache.NewManager[*fs.CopyTask](tache.WithWorks(conf.Conf.Tasks.Copy.Workers), tache.WithMaxRetry(conf.Conf.Tasks.Copy.MaxRetry))
+	fs.ExtractTaskManager = tache.NewManager[*fs.ExtractTask](tache.WithWorks(conf.Conf.Tasks.Extract.Workers), tache.WithMaxRetry(conf.Conf.Tasks.Extract.MaxRetry))
+	fs.CompressTaskManager = tache.NewManager[*fs.CompressTask](tache.WithWorks(conf.Conf.Tasks.Compress.Workers), tache.WithMaxRetry(conf.Conf.Tasks.Compress.MaxRetry))
+	mirror.TaskManager = tache.NewManager[*mirror.SyncTask](tache.WithWorks(conf.Conf.Tasks.Sync.Workers), tache.WithMaxRetry(conf.Conf.Tasks.Sync.MaxRetry))
 	tool.DownloadTaskManager = tache.NewManager[*tool.DownloadTask](tache.WithWorks(conf.Conf.Tasks.Download.Workers), tache.WithMaxRetry(conf.Conf.Tasks.Download.MaxRetry))
 	tool.TransferTaskManager = tache.NewManager[*tool.TransferTask](tache.WithWorks(conf.Conf.Tasks.Transfer.Workers), tache.WithMaxRetry(conf.Conf.Tasks.Transfer.MaxRetry))
+	task.Persist("upload", fs.UploadTaskManager, func() *fs.UploadTask { return &fs.UploadTask{} })
+	task.Persist("copy", fs.CopyTaskManager, func() *fs.CopyTask { return &fs.CopyTask{} })
+	task.Persist("extract", fs.ExtractTaskManager, func() *fs.ExtractTask { return &fs.ExtractTask{} })
+	task.Persist("compress", fs.CompressTaskManager, func() *fs.CompressTask { return &fs.CompressTask{} })
+	task.Persist("sync", mirror.TaskManager, func() *mirror.SyncTask { return &mirror.SyncTask{} })
+	task.Persist("offline_download", tool.DownloadTaskManager, func() *tool.DownloadTask { return &tool.DownloadTask{} })
+	task.Persist("offline_download_transfer", tool.TransferTaskManager, func() *tool.TransferTask { return &tool.TransferTask{} })
+	mirror.Init()
 }
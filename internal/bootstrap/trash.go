@@ -0,0 +1,13 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+// InitTrashCleaner purges the expired objects in the recycle bins hourly
+func InitTrashCleaner() {
+	cron.NewCron(time.Hour).Do(fs.CleanExpiredTrash)
+}
@@ -0,0 +1,13 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/webhook"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+// InitWebhookCleaner deletes the expired webhook delivery logs hourly
+func InitWebhookCleaner() {
+	cron.NewCron(time.Hour).Do(webhook.CleanDeliveries)
+}
@@ -56,6 +56,9 @@ type TasksConfig struct {
 	Transfer TaskConfig `json:"transfer" envPrefix:"TRANSFER_"`
 	Upload   TaskConfig `json:"upload" envPrefix:"UPLOAD_"`
 	Copy     TaskConfig `json:"copy" envPrefix:"COPY_"`
+	Extract  TaskConfig `json:"extract" envPrefix:"EXTRACT_"`
+	Compress TaskConfig `json:"compress" envPrefix:"COMPRESS_"`
+	Sync     TaskConfig `json:"sync" envPrefix:"SYNC_"`
 }
 
 type Cors struct {
@@ -81,6 +84,7 @@ type Config struct {
 	Scheme                Scheme      `json:"scheme"`
 	TempDir               string      `json:"temp_dir" env:"TEMP_DIR"`
 	BleveDir              string      `json:"bleve_dir" env:"BLEVE_DIR"`
+	ThumbDir              string      `json:"thumb_dir" env:"THUMB_DIR"`
 	DistDir               string      `json:"dist_dir"`
 	Log                   LogConfig   `json:"log"`
 	DelayedStart          int         `json:"delayed_start" env:"DELAYED_START"`
@@ -94,6 +98,7 @@ type Config struct {
 func DefaultConfig() *Config {
 	tempDir := filepath.Join(flags.DataDir, "temp")
 	indexDir := filepath.Join(flags.DataDir, "bleve")
+	thumbDir := filepath.Join(flags.DataDir, "thumb")
 	logPath := filepath.Join(flags.DataDir, "log/log.log")
 	dbPath := filepath.Join(flags.DataDir, "data.db")
 	return &Config{
@@ -119,6 +124,7 @@ func DefaultConfig() *Config {
 			Host: "http://localhost:7700",
 		},
 		BleveDir: indexDir,
+		ThumbDir: thumbDir,
 		Log: LogConfig{
 			Enable:     true,
 			Name:       logPath,
@@ -144,6 +150,18 @@ func DefaultConfig() *Config {
 				Workers:  5,
 				MaxRetry: 2,
 			},
+			Extract: TaskConfig{
+				Workers:  2,
+				MaxRetry: 1,
+			},
+			Compress: TaskConfig{
+				Workers:  2,
+				MaxRetry: 1,
+			},
+			Sync: TaskConfig{
+				Workers:  5,
+				MaxRetry: 2,
+			},
 		},
 		Cors: Cors{
 			AllowOrigins: []string{"*"},
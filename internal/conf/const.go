@@ -32,23 +32,38 @@ const (
 	VideoAutoplay      = "video_autoplay"
 
 	// global
-	HideFiles               = "hide_files"
-	CustomizeHead           = "customize_head"
-	CustomizeBody           = "customize_body"
-	LinkExpiration          = "link_expiration"
-	SignAll                 = "sign_all"
-	PrivacyRegs             = "privacy_regs"
-	OcrApi                  = "ocr_api"
-	FilenameCharMapping     = "filename_char_mapping"
-	ForwardDirectLinkParams = "forward_direct_link_params"
-	IgnoreDirectLinkParams  = "ignore_direct_link_params"
-	WebauthnLoginEnabled    = "webauthn_login_enabled"
+	HideFiles                  = "hide_files"
+	CustomizeHead              = "customize_head"
+	CustomizeBody              = "customize_body"
+	LinkExpiration             = "link_expiration"
+	SignAll                    = "sign_all"
+	PrivacyRegs                = "privacy_regs"
+	OcrApi                     = "ocr_api"
+	FilenameCharMapping        = "filename_char_mapping"
+	ForwardDirectLinkParams    = "forward_direct_link_params"
+	IgnoreDirectLinkParams     = "ignore_direct_link_params"
+	WebauthnLoginEnabled       = "webauthn_login_enabled"
+	RecycleBinRetention        = "recycle_bin_retention"
+	BandwidthLimit             = "bandwidth_limit"
+	UserBandwidthLimit         = "user_bandwidth_limit"
+	IPBandwidthLimit           = "ip_bandwidth_limit"
+	AuditLogRetention          = "audit_log_retention"
+	MetricsToken               = "metrics_token"
+	StorageHealthCheckInterval = "storage_health_check_interval"
+	ThumbnailConcurrency       = "thumbnail_concurrency"
+	ThumbnailMaxSize           = "thumbnail_max_size"
 
 	// index
-	SearchIndex     = "search_index"
-	AutoUpdateIndex = "auto_update_index"
-	IgnorePaths     = "ignore_paths"
-	MaxIndexDepth   = "max_index_depth"
+	SearchIndex              = "search_index"
+	AutoUpdateIndex          = "auto_update_index"
+	IgnorePaths              = "ignore_paths"
+	MaxIndexDepth            = "max_index_depth"
+	IndexRebuildPaths        = "index_rebuild_paths"
+	IndexRebuildInterval     = "index_rebuild_interval"
+	IndexContent             = "index_content"
+	IndexContentMaxSize      = "index_content_max_size"
+	IndexContentIncludePaths = "index_content_include_paths"
+	IndexContentExcludePaths = "index_content_exclude_paths"
 
 	// aria2
 	Aria2Uri    = "aria2_uri"
@@ -88,6 +103,7 @@ const (
 	S3Buckets         = "s3_buckets"
 	S3AccessKeyId     = "s3_access_key_id"
 	S3SecretAccessKey = "s3_secret_access_key"
+	S3User            = "s3_user"
 
 	// qbittorrent
 	QbittorrentUrl      = "qbittorrent_url"
@@ -106,5 +122,9 @@ const (
 
 // ContextKey is the type of context keys.
 const (
-	NoTaskKey = "no_task"
+	NoTaskKey         = "no_task"
+	ConflictPolicyKey = "conflict_policy"
+	ResumeUploadKey   = "resume_upload"
+	ClientIPKey       = "client_ip"
+	ProtocolKey       = "protocol"
 )
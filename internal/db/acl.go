@@ -0,0 +1,55 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetAclRuleById(id uint) (*model.AclRule, error) {
+	var r model.AclRule
+	if err := db.First(&r, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get acl rule")
+	}
+	return &r, nil
+}
+
+func CreateAclRule(r *model.AclRule) error {
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateAclRule(r *model.AclRule) error {
+	return errors.WithStack(db.Save(r).Error)
+}
+
+// GetAclRules returns the rules of a subject, or all the rules if subjectType is empty
+func GetAclRules(subjectType string, subjectId uint, pageIndex, pageSize int) (rules []model.AclRule, count int64, err error) {
+	ruleDB := db.Model(&model.AclRule{})
+	if subjectType != "" {
+		ruleDB = ruleDB.Where(fmt.Sprintf("%s = ? AND %s = ?", columnName("subject_type"), columnName("subject_id")), subjectType, subjectId)
+	}
+	if err = ruleDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get acl rules count")
+	}
+	if err = ruleDB.Order(columnName("path")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&rules).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find acl rules")
+	}
+	return rules, count, nil
+}
+
+func GetAclRulesBySubject(subjectType string, subjectIds ...uint) ([]model.AclRule, error) {
+	var rules []model.AclRule
+	if len(subjectIds) == 0 {
+		return rules, nil
+	}
+	if err := db.Where(fmt.Sprintf("%s = ? AND %s IN ?", columnName("subject_type"), columnName("subject_id")), subjectType, subjectIds).
+		Find(&rules).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return rules, nil
+}
+
+func DeleteAclRuleById(id uint) error {
+	return errors.WithStack(db.Delete(&model.AclRule{}, id).Error)
+}
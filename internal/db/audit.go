@@ -0,0 +1,67 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// AuditLogFilter filters the audit logs, the zero fields are ignored
+type AuditLogFilter struct {
+	Username string
+	Action   string
+	Protocol string
+	IP       string
+	Path     string // the logs of the path and its sub paths
+	Success  *bool
+	Start    *time.Time
+	End      *time.Time
+}
+
+func CreateAuditLog(l *model.AuditLog) error {
+	return errors.WithStack(db.Create(l).Error)
+}
+
+func GetAuditLogs(filter AuditLogFilter, pageIndex, pageSize int) (logs []model.AuditLog, count int64, err error) {
+	auditDB := db.Model(&model.AuditLog{})
+	if filter.Username != "" {
+		auditDB = auditDB.Where(fmt.Sprintf("%s = ?", columnName("username")), filter.Username)
+	}
+	if filter.Action != "" {
+		auditDB = auditDB.Where(fmt.Sprintf("%s = ?", columnName("action")), filter.Action)
+	}
+	if filter.Protocol != "" {
+		auditDB = auditDB.Where(fmt.Sprintf("%s = ?", columnName("protocol")), filter.Protocol)
+	}
+	if filter.IP != "" {
+		auditDB = auditDB.Where(fmt.Sprintf("%s = ?", columnName("ip")), filter.IP)
+	}
+	if filter.Path != "" && filter.Path != "/" {
+		auditDB = auditDB.Where(fmt.Sprintf("%s = ? OR %s LIKE ?", columnName("path"), columnName("path")),
+			filter.Path, filter.Path+"/%")
+	}
+	if filter.Success != nil {
+		auditDB = auditDB.Where(fmt.Sprintf("%s = ?", columnName("success")), *filter.Success)
+	}
+	if filter.Start != nil {
+		auditDB = auditDB.Where(fmt.Sprintf("%s >= ?", columnName("created")), *filter.Start)
+	}
+	if filter.End != nil {
+		auditDB = auditDB.Where(fmt.Sprintf("%s <= ?", columnName("created")), *filter.End)
+	}
+	if err = auditDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get audit logs count")
+	}
+	if err = auditDB.Order(fmt.Sprintf("%s desc", columnName("id"))).
+		Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find audit logs")
+	}
+	return logs, count, nil
+}
+
+// DeleteAuditLogsBefore deletes the audit logs created before t
+func DeleteAuditLogsBefore(t time.Time) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s < ?", columnName("created")), t).Delete(&model.AuditLog{}).Error)
+}
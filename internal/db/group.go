@@ -0,0 +1,61 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+func GetGroupById(id uint) (*model.Group, error) {
+	var g model.Group
+	if err := db.First(&g, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get group")
+	}
+	return &g, nil
+}
+
+func GetGroupsByIds(ids []uint) ([]model.Group, error) {
+	var groups []model.Group
+	if len(ids) == 0 {
+		return groups, nil
+	}
+	if err := db.Find(&groups, ids).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return groups, nil
+}
+
+func CreateGroup(g *model.Group) error {
+	return errors.WithStack(db.Create(g).Error)
+}
+
+func UpdateGroup(g *model.Group) error {
+	return errors.WithStack(db.Save(g).Error)
+}
+
+func GetGroups(pageIndex, pageSize int) (groups []model.Group, count int64, err error) {
+	groupDB := db.Model(&model.Group{})
+	if err = groupDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get groups count")
+	}
+	if err = groupDB.Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&groups).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find groups")
+	}
+	return groups, count, nil
+}
+
+func DeleteGroupById(id uint) error {
+	return errors.WithStack(db.Delete(&model.Group{}, id).Error)
+}
+
+// GetUsersByGroupId returns the users in the group,
+// the group ids are stored as json so they are filtered here
+func GetUsersByGroupId(id uint) ([]model.User, error) {
+	var users []model.User
+	if err := db.Find(&users).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return utils.SliceFilter(users, func(u model.User) bool {
+		return utils.SliceContains(u.GroupIds, id)
+	}), nil
+}
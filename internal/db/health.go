@@ -0,0 +1,38 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateStorageHealth(h *model.StorageHealth) error {
+	return errors.WithStack(db.Create(h).Error)
+}
+
+// GetStorageHealths returns the health history of a storage, or of all storages if storageId is 0
+func GetStorageHealths(storageId uint, pageIndex, pageSize int) (healths []model.StorageHealth, count int64, err error) {
+	healthDB := db.Model(&model.StorageHealth{})
+	if storageId != 0 {
+		healthDB = healthDB.Where(fmt.Sprintf("%s = ?", columnName("storage_id")), storageId)
+	}
+	if err = healthDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get storage healths count")
+	}
+	if err = healthDB.Order(fmt.Sprintf("%s desc", columnName("id"))).
+		Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&healths).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find storage healths")
+	}
+	return healths, count, nil
+}
+
+// DeleteStorageHealthsBefore deletes the health history created before t
+func DeleteStorageHealthsBefore(t time.Time) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s < ?", columnName("created")), t).Delete(&model.StorageHealth{}).Error)
+}
+
+func DeleteStorageHealthsByStorageId(storageId uint) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ?", columnName("storage_id")), storageId).Delete(&model.StorageHealth{}).Error)
+}
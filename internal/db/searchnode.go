@@ -10,6 +10,7 @@ import (
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func whereInParent(parent string) *gorm.DB {
@@ -35,7 +36,7 @@ func DeleteSearchNodesByParent(path string) error {
 	if err != nil {
 		return err
 	}
-	dir, name := stdpath.Split(path)
+	dir, name := stdpath.Dir(path), stdpath.Base(path)
 	return db.Where(fmt.Sprintf("%s = ? AND %s = ?",
 		columnName("parent"), columnName("name")),
 		dir, name).Delete(&model.SearchNode{}).Error
@@ -56,6 +57,8 @@ func GetSearchNodesByParent(parent string) ([]model.SearchNode, error) {
 
 func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int64, error) {
 	var searchDB *gorm.DB
+	// order by relevance, name is used if there is no score
+	order := clause.OrderBy{Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "name"}}}}
 	if !useFullText || conf.Conf.Database.Type == "sqlite3" {
 		keywordsClause := db.Where("1 = 1")
 		for _, keyword := range strings.Fields(req.Keywords) {
@@ -65,27 +68,63 @@ func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int6
 	} else {
 		switch conf.Conf.Database.Type {
 		case "mysql":
+			keywords := "'*" + req.Keywords + "*'"
 			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
-				Where("MATCH (name) AGAINST (? IN BOOLEAN MODE)", "'*"+req.Keywords+"*'")
+				Where("MATCH (name) AGAINST (? IN BOOLEAN MODE)", keywords)
+			order = clause.OrderBy{Expression: clause.Expr{
+				SQL: "MATCH (name) AGAINST (? IN BOOLEAN MODE) DESC", Vars: []interface{}{keywords}}}
 		case "postgres":
+			keywords := strings.Join(strings.Fields(req.Keywords), " & ")
 			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
-				Where("to_tsvector(name) @@ to_tsquery(?)", strings.Join(strings.Fields(req.Keywords), " & "))
+				Where("to_tsvector(name) @@ to_tsquery(?)", keywords)
+			order = clause.OrderBy{Expression: clause.Expr{
+				SQL: "ts_rank(to_tsvector(name), to_tsquery(?)) DESC", Vars: []interface{}{keywords}}}
 		}
 	}
-
-	if req.Scope != 0 {
-		isDir := req.Scope == 1
-		searchDB.Where(db.Where("is_dir = ?", isDir))
-	}
+	searchDB = whereSearchFilters(searchDB, req)
 
 	var count int64
 	if err := searchDB.Count(&count).Error; err != nil {
 		return nil, 0, errors.Wrapf(err, "failed get search items count")
 	}
+	if req.OrderBy != model.SearchOrderByRelevance {
+		order = clause.OrderBy{Columns: []clause.OrderByColumn{{Column: clause.Column{Name: req.OrderBy}, Desc: req.Desc()}}}
+	}
 	var files []model.SearchNode
-	if err := searchDB.Order("name asc").Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
+	if err := searchDB.Clauses(order).Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
 		Find(&files).Error; err != nil {
 		return nil, 0, err
 	}
 	return files, count, nil
 }
+
+// whereSearchFilters adds the conditions of the filters except parent and keywords in req
+func whereSearchFilters(searchDB *gorm.DB, req model.SearchReq) *gorm.DB {
+	if req.Scope != 0 {
+		isDir := req.Scope == 1
+		searchDB = searchDB.Where(fmt.Sprintf("%s = ?", columnName("is_dir")), isDir)
+	}
+	if len(req.Exts) > 0 {
+		extsClause := db.Where("1 = 0")
+		for _, ext := range req.Exts {
+			extsClause = extsClause.Or(fmt.Sprintf("LOWER(%s) LIKE ?", columnName("name")), "%."+ext)
+		}
+		searchDB = searchDB.Where(extsClause)
+	}
+	if len(req.Categories) > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s IN ?", columnName("category")), req.Categories)
+	}
+	if req.MinSize > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s >= ?", columnName("size")), req.MinSize)
+	}
+	if req.MaxSize > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s <= ?", columnName("size")), req.MaxSize)
+	}
+	if req.ModifiedFrom != nil {
+		searchDB = searchDB.Where(fmt.Sprintf("%s >= ?", columnName("modified")), *req.ModifiedFrom)
+	}
+	if req.ModifiedTo != nil {
+		searchDB = searchDB.Where(fmt.Sprintf("%s <= ?", columnName("modified")), *req.ModifiedTo)
+	}
+	return searchDB
+}
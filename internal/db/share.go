@@ -0,0 +1,58 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetShareById(id string) (*model.Share, error) {
+	var s model.Share
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("id")), id).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get share")
+	}
+	return &s, nil
+}
+
+func CreateShare(s *model.Share) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+// GetShares returns the shares of a user, or of all users if userId is 0
+func GetShares(userId uint, pageIndex, pageSize int) (shares []model.Share, count int64, err error) {
+	shareDB := db.Model(&model.Share{})
+	if userId != 0 {
+		shareDB = shareDB.Where(fmt.Sprintf("%s = ?", columnName("user_id")), userId)
+	}
+	if err = shareDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get shares count")
+	}
+	if err = shareDB.Order(fmt.Sprintf("%s desc", columnName("created"))).
+		Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&shares).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find shares")
+	}
+	return shares, count, nil
+}
+
+// IncrShareDownloads counts a download of the share,
+// false is returned if the max downloads has been reached.
+func IncrShareDownloads(id string) (bool, error) {
+	res := db.Model(&model.Share{}).
+		Where(fmt.Sprintf("%s = ? AND (%s = 0 OR %s < %s)", columnName("id"),
+			columnName("max_downloads"), columnName("downloads"), columnName("max_downloads")), id).
+		Update("downloads", gorm.Expr(fmt.Sprintf("%s + 1", columnName("downloads"))))
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func DeleteShareById(id string) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ?", columnName("id")), id).Delete(&model.Share{}).Error)
+}
+
+func DeleteSharesByUserId(userId uint) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ?", columnName("user_id")), userId).Delete(&model.Share{}).Error)
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetSyncJobById(id uint) (*model.SyncJob, error) {
+	var j model.SyncJob
+	if err := db.First(&j, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get sync job")
+	}
+	return &j, nil
+}
+
+func CreateSyncJob(j *model.SyncJob) error {
+	return errors.WithStack(db.Create(j).Error)
+}
+
+func UpdateSyncJob(j *model.SyncJob) error {
+	return errors.WithStack(db.Save(j).Error)
+}
+
+func GetSyncJobs(pageIndex, pageSize int) (jobs []model.SyncJob, count int64, err error) {
+	jobDB := db.Model(&model.SyncJob{})
+	if err = jobDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get sync jobs count")
+	}
+	if err = jobDB.Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&jobs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find sync jobs")
+	}
+	return jobs, count, nil
+}
+
+func GetEnabledSyncJobs() ([]model.SyncJob, error) {
+	var jobs []model.SyncJob
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("disabled")), false).Find(&jobs).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return jobs, nil
+}
+
+func DeleteSyncJobById(id uint) error {
+	return errors.WithStack(db.Delete(&model.SyncJob{}, id).Error)
+}
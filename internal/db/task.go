@@ -0,0 +1,59 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func SaveTaskItem(item *model.TaskItem) error {
+	return errors.WithStack(db.Save(item).Error)
+}
+
+func GetTaskItemsByState(typ string, states ...int) ([]model.TaskItem, error) {
+	var items []model.TaskItem
+	taskDB := db.Where(fmt.Sprintf("%s = ?", columnName("type")), typ)
+	if len(states) > 0 {
+		taskDB = taskDB.Where(fmt.Sprintf("%s IN ?", columnName("state")), states)
+	}
+	if err := taskDB.Order(columnName("created_at")).Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return items, nil
+}
+
+// GetTaskItems returns the task items of typ in the given states created by the user,
+// an empty typ or states or a zero userID means no filter on it
+func GetTaskItems(typ string, states []int, userID uint, pageIndex, pageSize int) (items []model.TaskItem, count int64, err error) {
+	taskDB := db.Model(&model.TaskItem{})
+	if userID != 0 {
+		taskDB = taskDB.Where(fmt.Sprintf("%s = ?", columnName("user_id")), userID)
+	}
+	if typ != "" {
+		taskDB = taskDB.Where(fmt.Sprintf("%s = ?", columnName("type")), typ)
+	}
+	if len(states) > 0 {
+		taskDB = taskDB.Where(fmt.Sprintf("%s IN ?", columnName("state")), states)
+	}
+	if err = taskDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get tasks count")
+	}
+	if err = taskDB.Order(fmt.Sprintf("%s DESC", columnName("updated_at"))).
+		Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find tasks")
+	}
+	return items, count, nil
+}
+
+func DeleteTaskItemById(id string) error {
+	return errors.WithStack(db.Delete(&model.TaskItem{ID: id}).Error)
+}
+
+func DeleteTaskItemsByState(typ string, states ...int) error {
+	taskDB := db.Where(fmt.Sprintf("%s IN ?", columnName("state")), states)
+	if typ != "" {
+		taskDB = taskDB.Where(fmt.Sprintf("%s = ?", columnName("type")), typ)
+	}
+	return errors.WithStack(taskDB.Delete(&model.TaskItem{}).Error)
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateTrashItem(item *model.TrashItem) error {
+	return errors.WithStack(db.Create(item).Error)
+}
+
+func GetTrashItemById(id uint) (*model.TrashItem, error) {
+	var item model.TrashItem
+	if err := db.First(&item, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get trash item")
+	}
+	return &item, nil
+}
+
+// GetTrashItems returns the trash items of a storage, or of all storages if storageId is 0
+func GetTrashItems(storageId uint, pageIndex, pageSize int) (items []model.TrashItem, count int64, err error) {
+	trashDB := db.Model(&model.TrashItem{})
+	if storageId != 0 {
+		trashDB = trashDB.Where(fmt.Sprintf("%s = ?", columnName("storage_id")), storageId)
+	}
+	if err = trashDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get trash items count")
+	}
+	if err = trashDB.Order(fmt.Sprintf("%s desc", columnName("removed_at"))).
+		Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find trash items")
+	}
+	return items, count, nil
+}
+
+func GetTrashItemsByStorage(storageId uint) ([]model.TrashItem, error) {
+	var items []model.TrashItem
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("storage_id")), storageId).Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return items, nil
+}
+
+// GetTrashItemsRemovedBefore returns the trash items removed before t
+func GetTrashItemsRemovedBefore(t time.Time) ([]model.TrashItem, error) {
+	var items []model.TrashItem
+	if err := db.Where(fmt.Sprintf("%s < ?", columnName("removed_at")), t).Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return items, nil
+}
+
+func DeleteTrashItemById(id uint) error {
+	return errors.WithStack(db.Delete(&model.TrashItem{}, id).Error)
+}
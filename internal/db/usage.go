@@ -0,0 +1,130 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// GetUserUsage returns the usage of user, zero if it has uploaded nothing
+func GetUserUsage(userId uint) (*model.UserUsage, error) {
+	usage := model.UserUsage{UserID: userId}
+	if err := db.Where(usage).FirstOrInit(&usage).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get user usage")
+	}
+	return &usage, nil
+}
+
+// SumUserUsage returns the total usage of users
+func SumUserUsage(userIds []uint) (bytes int64, files int64, err error) {
+	if len(userIds) == 0 {
+		return 0, 0, nil
+	}
+	var res struct {
+		Bytes int64
+		Files int64
+	}
+	err = db.Model(&model.UserUsage{}).
+		Select(fmt.Sprintf("COALESCE(SUM(%s), 0) AS bytes, COALESCE(SUM(%s), 0) AS files", columnName("bytes"), columnName("files"))).
+		Where(fmt.Sprintf("%s IN ?", columnName("user_id")), userIds).Scan(&res).Error
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed sum user usage")
+	}
+	return res.Bytes, res.Files, nil
+}
+
+// AddUserUsage adds bytes and files to the usage of user, the usage is never less than zero
+// as the removed files may be uploaded by the others
+func AddUserUsage(userId uint, bytes, files int64) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		usage := model.UserUsage{UserID: userId}
+		if err := tx.Where(usage).FirstOrCreate(&usage).Error; err != nil {
+			return err
+		}
+		bytes = max(bytes, -usage.Bytes)
+		files = max(files, -usage.Files)
+		return tx.Model(&usage).UpdateColumns(map[string]interface{}{
+			"bytes":      gorm.Expr(fmt.Sprintf("%s + ?", columnName("bytes")), bytes),
+			"files":      gorm.Expr(fmt.Sprintf("%s + ?", columnName("files")), files),
+			"updated_at": time.Now(),
+		}).Error
+	}))
+}
+
+// SetUploadOwner records the owner of the file at owner.Path, the owner of the file it overwrites is returned
+func SetUploadOwner(owner *model.UploadOwner) (old *model.UploadOwner, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var owners []model.UploadOwner
+		if err := tx.Where(fmt.Sprintf("%s = ?", columnName("path")), owner.Path).Find(&owners).Error; err != nil {
+			return err
+		}
+		if len(owners) > 0 {
+			old = &owners[0]
+			if err := tx.Delete(old).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(owner).Error
+	})
+	return old, errors.WithStack(err)
+}
+
+// uploadOwnersIn selects the owners of the files at path and in it,
+// the paths in it are between "path/" and "path0" as '0' is next to '/'
+func uploadOwnersIn(tx *gorm.DB, path string) *gorm.DB {
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	return tx.Where(fmt.Sprintf("%s = ? OR (%s >= ? AND %s < ?)", columnName("path"), columnName("path"), columnName("path")),
+		path, prefix, strings.TrimSuffix(prefix, "/")+"0")
+}
+
+// DeleteUploadOwners deletes the owners of the files at path and in it, the deleted ones are returned
+func DeleteUploadOwners(path string) (owners []model.UploadOwner, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := uploadOwnersIn(tx, path).Find(&owners).Error; err != nil {
+			return err
+		}
+		if len(owners) == 0 {
+			return nil
+		}
+		return tx.Delete(&owners).Error
+	})
+	return owners, errors.WithStack(err)
+}
+
+// MoveUploadOwners changes the paths of the files at srcPath and in it to dstPath,
+// the owners of the files overwritten at dstPath are deleted and returned
+func MoveUploadOwners(srcPath, dstPath string) (overwritten []model.UploadOwner, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var owners []model.UploadOwner
+		if err := uploadOwnersIn(tx, srcPath).Find(&owners).Error; err != nil {
+			return err
+		}
+		if len(owners) == 0 {
+			return nil
+		}
+		if err := uploadOwnersIn(tx, dstPath).Find(&overwritten).Error; err != nil {
+			return err
+		}
+		if len(overwritten) > 0 {
+			if err := tx.Delete(&overwritten).Error; err != nil {
+				return err
+			}
+		}
+		for _, owner := range owners {
+			path := dstPath + strings.TrimPrefix(owner.Path, srcPath)
+			if err := tx.Model(&owner).Update("path", path).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return overwritten, errors.WithStack(err)
+}
+
+func ResetUserUsage(userId uint) error {
+	return errors.WithStack(db.Delete(&model.UserUsage{}, userId).Error)
+}
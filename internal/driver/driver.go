@@ -47,6 +47,11 @@ type Getter interface {
 	Get(ctx context.Context, path string) (model.Obj, error)
 }
 
+// WithDetails is the driver which can tell the space usage of the storage
+type WithDetails interface {
+	GetDetails(ctx context.Context) (*model.StorageDetails, error)
+}
+
 //type Writer interface {
 //	Mkdir
 //	Move
@@ -80,6 +85,15 @@ type Put interface {
 	Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up UpdateProgress) error
 }
 
+// PutResume is implemented by the drivers which keep the data of an interrupted Put,
+// so the upload can continue from where it stopped instead of starting over
+type PutResume interface {
+	// Uploaded returns the size of the interrupted Put of stream in dstDir, 0 if there is none
+	Uploaded(ctx context.Context, dstDir model.Obj, stream model.FileStreamer) (int64, error)
+	// PutResume uploads the rest of stream after offset and finishes the interrupted Put
+	PutResume(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, offset int64, up UpdateProgress) error
+}
+
 //type WriteResult interface {
 //	MkdirResult
 //	MoveResult
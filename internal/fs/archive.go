@@ -0,0 +1,350 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/archive"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// the entries of an archive are reached by the path of the archive followed by the path in it,
+// such as /local/foo.zip/bar/baz.txt, the archive is read with ranged reads of its link.
+
+const (
+	archiveBlockSize = 1024 * 1024
+	archiveMaxBlocks = 4
+	archiveCacheTime = 30 * time.Minute
+	// archiveCacheCount bounds the cached archives, each of them keeps its index and the blocks read recently
+	archiveCacheCount = 16
+)
+
+var archiveCache = cache.NewMemCache(cache.WithShards[*archive.Archive](16))
+var archiveG singleflight.Group[*archive.Archive]
+
+var (
+	archiveKeysMu sync.Mutex
+	// archiveKeys the keys of the cached archives, the most recently used last
+	archiveKeys []string
+)
+
+// touchArchive marks the archive of key as the most recently used one,
+// the least recently used archives are dropped if there are too many
+func touchArchive(key string) {
+	archiveKeysMu.Lock()
+	defer archiveKeysMu.Unlock()
+	for i, k := range archiveKeys {
+		if k == key {
+			archiveKeys = append(archiveKeys[:i:i], archiveKeys[i+1:]...)
+			break
+		}
+	}
+	archiveKeys = append(archiveKeys, key)
+	for len(archiveKeys) > archiveCacheCount {
+		archiveCache.Del(archiveKeys[0])
+		archiveKeys = archiveKeys[1:]
+	}
+}
+
+type archiveEntry struct {
+	storage     driver.Driver
+	archivePath string // the actual path of the archive in the storage
+	innerPath   string // the path of the entry in the archive, / is the root
+	file        model.Obj
+}
+
+// IsArchive returns whether obj is an archive file which can be browsed as a folder
+func IsArchive(obj model.Obj) bool {
+	return !obj.IsDir() && archive.IsArchive(obj.GetName())
+}
+
+// findArchive returns the archive in the path and the path in the archive,
+// ok is false if the path isn't in an archive
+func findArchive(ctx context.Context, path string) (entry *archiveEntry, ok bool) {
+	path = utils.FixAndCleanPath(path)
+	if !strings.Contains(path, ".") {
+		return nil, false
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	// the archives in the recycle bin are rejected as the other objects in it
+	if err != nil || isInTrash(actualPath) {
+		return nil, false
+	}
+	names := strings.Split(strings.TrimPrefix(actualPath, "/"), "/")
+	for i, name := range names {
+		if !archive.IsArchive(name) {
+			continue
+		}
+		archivePath := "/" + stdpath.Join(names[:i+1]...)
+		file, err := op.Get(ctx, storage, archivePath)
+		if err != nil {
+			return nil, false
+		}
+		// a folder may be named like an archive
+		if file.IsDir() {
+			continue
+		}
+		return &archiveEntry{
+			storage:     storage,
+			archivePath: archivePath,
+			innerPath:   "/" + stdpath.Join(names[i+1:]...),
+			file:        file,
+		}, true
+	}
+	return nil, false
+}
+
+func getArchive(ctx context.Context, e *archiveEntry) (*archive.Archive, error) {
+	key := fmt.Sprintf("%s:%d:%d", op.Key(e.storage, e.archivePath), e.file.GetSize(), e.file.ModTime().Unix())
+	if a, ok := archiveCache.Get(key); ok {
+		touchArchive(key)
+		return a, nil
+	}
+	a, err, _ := archiveG.Do(key, func() (*archive.Archive, error) {
+		r := &linkReaderAt{mountPath: e.storage.GetStorage().MountPath, path: e.archivePath, size: e.file.GetSize()}
+		a, err := archive.Open(e.file.GetName(), r, e.file.GetSize())
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed open archive %s", e.archivePath)
+		}
+		archiveCache.Set(key, a, cache.WithEx[*archive.Archive](archiveCacheTime))
+		touchArchive(key)
+		return a, nil
+	})
+	return a, err
+}
+
+// wrapArchiveObj returns a copy of the entry with the actual path in the storage
+func wrapArchiveObj(e *archiveEntry, obj model.Obj) model.Obj {
+	return &model.Object{
+		Name:     obj.GetName(),
+		Path:     stdpath.Join(e.archivePath, obj.GetPath()),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+	}
+}
+
+func listArchive(ctx context.Context, e *archiveEntry) ([]model.Obj, error) {
+	a, err := getArchive(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := a.List(e.innerPath)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed list %s in archive", e.innerPath)
+	}
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		res = append(res, wrapArchiveObj(e, obj))
+	}
+	return res, nil
+}
+
+func getInArchive(ctx context.Context, e *archiveEntry) (model.Obj, error) {
+	a, err := getArchive(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := a.Get(e.innerPath)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed get %s in archive", e.innerPath)
+	}
+	return wrapArchiveObj(e, obj), nil
+}
+
+// linkInArchive returns a link which streams the entry out of the archive
+func linkInArchive(ctx context.Context, e *archiveEntry) (*model.Link, model.Obj, error) {
+	a, err := getArchive(ctx, e)
+	if err != nil {
+		return nil, nil, err
+	}
+	obj, err := a.Get(e.innerPath)
+	if err != nil {
+		return nil, nil, errors.WithMessagef(err, "failed get %s in archive", e.innerPath)
+	}
+	if obj.IsDir() {
+		return nil, nil, errors.WithStack(errs.NotFile)
+	}
+	innerPath := e.innerPath
+	return &model.Link{
+		RangeReadCloser: &model.RangeReadCloser{
+			RangeReader: func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+				return a.RangeRead(innerPath, httpRange)
+			},
+		},
+	}, wrapArchiveObj(e, obj), nil
+}
+
+// linkReaderAt reads the file with ranged reads of its link, the recent blocks are cached
+// as the archive readers do many small reads
+type linkReaderAt struct {
+	mountPath string
+	path      string
+	size      int64
+
+	mu     sync.Mutex
+	blocks []*archiveBlock // the most recently used is the last
+}
+
+type archiveBlock struct {
+	offset int64
+	data   []byte
+}
+
+func (r *linkReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) && off < r.size {
+		block, err := r.getBlock(off / archiveBlockSize * archiveBlockSize)
+		if err != nil {
+			return n, err
+		}
+		c := copy(p[n:], block.data[off-block.offset:])
+		n += c
+		off += int64(c)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *linkReaderAt) getBlock(offset int64) (*archiveBlock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, b := range r.blocks {
+		if b.offset == offset {
+			r.blocks = append(append(r.blocks[:i:i], r.blocks[i+1:]...), b)
+			return b, nil
+		}
+	}
+	data, err := r.read(offset, utils.Min(archiveBlockSize, r.size-offset))
+	if err != nil {
+		return nil, err
+	}
+	b := &archiveBlock{offset: offset, data: data}
+	if len(r.blocks) >= archiveMaxBlocks {
+		r.blocks = r.blocks[1:]
+	}
+	r.blocks = append(r.blocks, b)
+	return b, nil
+}
+
+// read gets the storage and the link each time, as the storage may be reloaded
+// while the archive is cached, and the links of many drivers expire
+func (r *linkReaderAt) read(offset, length int64) ([]byte, error) {
+	ctx := context.Background()
+	storage, err := op.GetStorageByMountPath(r.mountPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get archive storage")
+	}
+	link, _, err := op.Link(ctx, storage, r.path, model.LinkArgs{})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed link archive")
+	}
+	buf := make([]byte, length)
+	if link.MFile != nil {
+		defer link.MFile.Close()
+		if _, err := link.MFile.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return nil, errors.WithStack(err)
+		}
+		return buf, nil
+	}
+	rrc := link.RangeReadCloser
+	if rrc == nil {
+		if rrc, err = stream.GetRangeReadCloserFromLink(r.size, link); err != nil {
+			return nil, err
+		}
+	}
+	rc, err := rrc.RangeRead(ctx, http_range.Range{Start: offset, Length: length})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed range read archive")
+	}
+	defer rc.Close()
+	if _, err := io.ReadFull(rc, buf); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf, nil
+}
+
+type WriteArchiveArgs struct {
+	Format string // zip or tar.gz
+	// Skip is called before each file or folder is added, a skipped folder isn't walked
+	Skip func(path string, obj model.Obj) bool
+	// Written is called after each file or folder is added
+	Written func(obj model.Obj)
+}
+
+// WriteArchive streams the files and folders at the names in srcDir into an archive,
+// the files are read one by one with their links, so nothing is cached but the buffers.
+func WriteArchive(ctx context.Context, w io.Writer, srcDir string, names []string, args WriteArchiveArgs) error {
+	aw, err := archive.NewWriter(args.Format, w)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if !utils.IsValidName(name) {
+			return errors.Errorf("invalid name: %s", name)
+		}
+		path := stdpath.Join(srcDir, name)
+		obj, err := get(ctx, path)
+		if err != nil {
+			return errors.WithMessagef(err, "failed get [%s]", path)
+		}
+		err = WalkFS(ctx, -1, path, obj, func(reqPath string, obj model.Obj) error {
+			if utils.IsCanceled(ctx) {
+				return ctx.Err()
+			}
+			if args.Skip != nil && args.Skip(reqPath, obj) {
+				if obj.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if err := addToArchive(ctx, aw, strings.TrimPrefix(reqPath, srcDir), reqPath, obj); err != nil {
+				return err
+			}
+			if args.Written != nil {
+				args.Written(obj)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return aw.Close()
+}
+
+func addToArchive(ctx context.Context, aw archive.Writer, name, path string, obj model.Obj) error {
+	if obj.IsDir() {
+		return aw.Add(name, obj, nil)
+	}
+	l, _, err := link(ctx, path, model.LinkArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed link [%s]", path)
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Ctx: ctx, Obj: obj}, l)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] stream", path)
+	}
+	defer ss.Close()
+	return aw.Add(name, obj, ss)
+}
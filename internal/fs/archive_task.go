@@ -0,0 +1,323 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/archive"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+type ExtractTask struct {
+	tache.Base
+	task.Creator
+	Status       string `json:"status"`
+	SrcStorageMp string `json:"src_storage_mp"`
+	DstStorageMp string `json:"dst_storage_mp"`
+	SrcObjPath   string `json:"src_path"`   // the archive
+	InnerPath    string `json:"inner_path"` // the folder in the archive to extract
+	DstDirPath   string `json:"dst_path"`
+}
+
+func (t *ExtractTask) GetName() string {
+	return fmt.Sprintf("extract [%s](%s) to [%s](%s)", t.SrcStorageMp, stdpath.Join(t.SrcObjPath, t.InnerPath), t.DstStorageMp, t.DstDirPath)
+}
+
+func (t *ExtractTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *ExtractTask) Run() error {
+	// the storages are got each time, as a storage may be reloaded while the task is queued
+	srcStorage, err := op.GetStorageByMountPath(t.SrcStorageMp)
+	if err != nil {
+		return errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, err := op.GetStorageByMountPath(t.DstStorageMp)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst storage")
+	}
+	t.Status = "reading archive"
+	file, err := op.Get(t.Ctx(), srcStorage, t.SrcObjPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get src [%s] file", t.SrcObjPath)
+	}
+	a, err := getArchive(t.Ctx(), &archiveEntry{
+		storage:     srcStorage,
+		archivePath: t.SrcObjPath,
+		innerPath:   t.InnerPath,
+		file:        file,
+	})
+	if err != nil {
+		return err
+	}
+	total, err := archiveDirSize(a, t.InnerPath)
+	if err != nil {
+		return err
+	}
+	var done int64
+	err = a.Walk(t.InnerPath, func(obj model.Obj, r io.Reader) error {
+		if utils.IsCanceled(t.Ctx()) {
+			return t.Ctx().Err()
+		}
+		dstPath := stdpath.Join(t.DstDirPath, strings.TrimPrefix(obj.GetPath(), utils.FixAndCleanPath(t.InnerPath)))
+		if obj.IsDir() {
+			return op.MakeDir(t.Ctx(), dstStorage, dstPath)
+		}
+		t.Status = "extracting " + obj.GetPath()
+		s := &stream.FileStream{
+			Ctx:      t.Ctx(),
+			Obj:      &model.Object{Name: obj.GetName(), Size: obj.GetSize(), Modified: obj.ModTime()},
+			Reader:   r,
+			Mimetype: utils.GetMimeType(obj.GetName()),
+		}
+		err := op.Put(t.Ctx(), dstStorage, stdpath.Dir(dstPath), s, func(p float64) {
+			if total > 0 {
+				t.SetProgress((float64(done) + p/100*float64(obj.GetSize())) / float64(total) * 100)
+			}
+		})
+		if err != nil {
+			return errors.WithMessagef(err, "failed put [%s]", dstPath)
+		}
+		done += obj.GetSize()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	t.SetProgress(100)
+	return nil
+}
+
+// archiveDirSize is the total size of the files under the folder of the archive
+func archiveDirSize(a *archive.Archive, path string) (int64, error) {
+	objs, err := a.List(path)
+	if err != nil {
+		return 0, errors.WithMessagef(err, "failed list %s in archive", path)
+	}
+	var size int64
+	for _, obj := range objs {
+		if !obj.IsDir() {
+			size += obj.GetSize()
+			continue
+		}
+		s, err := archiveDirSize(a, obj.GetPath())
+		if err != nil {
+			return 0, err
+		}
+		size += s
+	}
+	return size, nil
+}
+
+var ExtractTaskManager *tache.Manager[*ExtractTask]
+
+// extract adds a task to extract the archive, or the folder in the archive, at srcPath into dstDirPath
+func extract(ctx context.Context, srcPath, dstDirPath string) (tache.TaskWithInfo, error) {
+	e, ok := findArchive(ctx, srcPath)
+	if !ok {
+		return nil, errors.Errorf("%s is not in an archive", srcPath)
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get dst storage")
+	}
+	if err = checkNotInTrash(dstDirActualPath); err != nil {
+		return nil, err
+	}
+	a, err := getArchive(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := a.Get(e.innerPath)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed get %s in archive", e.innerPath)
+	}
+	if !obj.IsDir() {
+		return nil, errors.WithStack(errs.NotFolder)
+	}
+	t := &ExtractTask{
+		SrcStorageMp: e.storage.GetStorage().MountPath,
+		DstStorageMp: dstStorage.GetStorage().MountPath,
+		SrcObjPath:   e.archivePath,
+		InnerPath:    e.innerPath,
+		DstDirPath:   dstDirActualPath,
+		Creator:      task.CreatorFromCtx(ctx),
+	}
+	ExtractTaskManager.Add(t)
+	return t, nil
+}
+
+type CompressTask struct {
+	tache.Base
+	task.Creator
+	Status     string   `json:"status"`
+	SrcDir     string   `json:"src_dir"` // the paths are mount paths, the files may be in different storages
+	Names      []string `json:"names"`
+	DstDirPath string   `json:"dst_path"`
+	Name       string   `json:"name"`   // the name of the archive
+	Format     string   `json:"format"` // zip or tar.gz
+	// Password is the meta password of the src dir given by the creator
+	Password string `json:"-"`
+}
+
+func (t *CompressTask) GetName() string {
+	return fmt.Sprintf("compress [%s](%s) to (%s)", t.SrcDir, strings.Join(t.Names, ","), stdpath.Join(t.DstDirPath, t.Name))
+}
+
+func (t *CompressTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *CompressTask) Run() error {
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(t.DstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst storage")
+	}
+	skip, err := t.skipFunc()
+	if err != nil {
+		return err
+	}
+	t.Status = "getting src objects"
+	total, err := t.totalSize(skip)
+	if err != nil {
+		return err
+	}
+	// most of the drivers need the size before uploading, so the archive is written to a temp file first
+	tmpFile, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	s := &stream.FileStream{
+		Ctx:      t.Ctx(),
+		Mimetype: utils.GetMimeType(t.Name),
+	}
+	// the temp file is removed when the stream is closed
+	s.SetTmpFile(tmpFile)
+	defer func() {
+		_ = tmpFile.Close()
+		_ = s.Close()
+	}()
+	var done int64
+	err = WriteArchive(t.Ctx(), tmpFile, t.SrcDir, t.Names, WriteArchiveArgs{
+		Format: t.Format,
+		Skip:   skip,
+		Written: func(obj model.Obj) {
+			if obj.IsDir() {
+				return
+			}
+			t.Status = "compressing " + obj.GetName()
+			done += obj.GetSize()
+			if total > 0 {
+				t.SetProgress(float64(done) / float64(total) * 50)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+	info, err := tmpFile.Stat()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err = tmpFile.Seek(0, io.SeekStart); err != nil {
+		return errors.WithStack(err)
+	}
+	s.Obj = &model.Object{Name: t.Name, Size: info.Size(), Modified: time.Now()}
+	t.Status = "uploading"
+	return op.Put(t.Ctx(), dstStorage, dstDirActualPath, s, func(p float64) {
+		t.SetProgress(50 + p/2)
+	})
+}
+
+// skipFunc skips the files and folders which the creator can't read, as the archive download does
+func (t *CompressTask) skipFunc() (func(path string, obj model.Obj) bool, error) {
+	if t.UserID == 0 {
+		return nil, nil
+	}
+	user, err := op.GetUserById(t.UserID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get the creator")
+	}
+	return func(path string, obj model.Obj) bool {
+		ok, err := common.CanReadPath(user, path, t.Password)
+		return err != nil || !ok
+	}, nil
+}
+
+func (t *CompressTask) totalSize(skip func(path string, obj model.Obj) bool) (int64, error) {
+	var total int64
+	for _, name := range t.Names {
+		path := stdpath.Join(t.SrcDir, name)
+		obj, err := get(t.Ctx(), path)
+		if err != nil {
+			return 0, errors.WithMessagef(err, "failed get [%s]", path)
+		}
+		err = WalkFS(t.Ctx(), -1, path, obj, func(reqPath string, obj model.Obj) error {
+			if skip != nil && skip(reqPath, obj) {
+				if obj.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !obj.IsDir() {
+				total += obj.GetSize()
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+var CompressTaskManager *tache.Manager[*CompressTask]
+
+// compress adds a task to compress the names in srcDir into an archive named name in dstDirPath
+func compress(ctx context.Context, srcDir string, names []string, dstDirPath, name, format, password string) (tache.TaskWithInfo, error) {
+	if format != archive.FormatZip && format != archive.FormatTarGz {
+		return nil, errors.Errorf("unsupported archive format: %s", format)
+	}
+	if !utils.IsValidName(name) {
+		return nil, errors.Errorf("invalid archive name: %s", name)
+	}
+	for _, n := range names {
+		if !utils.IsValidName(n) {
+			return nil, errors.Errorf("invalid name: %s", n)
+		}
+	}
+	_, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get dst storage")
+	}
+	if err = checkNotInTrash(stdpath.Join(dstDirActualPath, name)); err != nil {
+		return nil, err
+	}
+	t := &CompressTask{
+		SrcDir:     srcDir,
+		Names:      names,
+		DstDirPath: dstDirPath,
+		Name:       name,
+		Format:     format,
+		Password:   password,
+		Creator:    task.CreatorFromCtx(ctx),
+	}
+	CompressTaskManager.Add(t)
+	return t, nil
+}
@@ -0,0 +1,100 @@
+package fs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/archive"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestCompressSkipsUnreadable(t *testing.T) {
+	_, root := newLocalStorage(t, "/archive")
+	for _, name := range []string{"a/ok.txt", "a/secret/x.txt"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	user := &model.User{Username: "archive", Role: model.GENERAL, BasePath: "/"}
+	if err := op.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	if err := op.CreateAclRule(&model.AclRule{SubjectType: model.AclSubjectUser, SubjectID: user.ID,
+		Path: "/archive/a/secret", Deny: model.AclRead}); err != nil {
+		t.Fatal(err)
+	}
+
+	ct := &CompressTask{Creator: task.Creator{UserID: user.ID}}
+	skip, err := ct.skipFunc()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = WriteArchive(ctx, &buf, "/archive", []string{"a"}, WriteArchiveArgs{Format: archive.FormatZip, Skip: skip})
+	if err != nil {
+		t.Fatalf("failed write archive: %+v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "a/,a/ok.txt" {
+		t.Errorf("the unreadable folder should be skipped, got %v", names)
+	}
+
+	if _, err := compress(ctx, "/archive", []string{"../etc"}, "/archive", "a.zip", archive.FormatZip, ""); err == nil {
+		t.Errorf("the names out of the src dir should be rejected")
+	}
+}
+
+func TestArchiveCacheBounded(t *testing.T) {
+	key := func(i int) string { return "bounded:" + strconv.Itoa(i) }
+	for i := 0; i <= archiveCacheCount; i++ {
+		archiveCache.Set(key(i), &archive.Archive{})
+		touchArchive(key(i))
+		if i == 1 {
+			// the first one is used again, so the second is the least recently used
+			touchArchive(key(0))
+		}
+	}
+	if archiveCache.Exists(key(1)) {
+		t.Errorf("the least recently used archive should be dropped")
+	}
+	if !archiveCache.Exists(key(0)) || !archiveCache.Exists(key(archiveCacheCount)) {
+		t.Errorf("the recently used archives should be kept")
+	}
+}
@@ -5,26 +5,50 @@ import (
 	"fmt"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	"github.com/xhofe/tache"
 	"net/http"
 	stdpath "path"
+	"strings"
 )
 
 type CopyTask struct {
 	tache.Base
-	Status                 string `json:"status"`
-	srcStorage, dstStorage driver.Driver
-	srcObjPath, dstDirPath string
+	task.Creator
+	Status       string `json:"status"`
+	SrcStorageMp string `json:"src_storage_mp"`
+	DstStorageMp string `json:"dst_storage_mp"`
+	SrcObjPath   string `json:"src_path"`
+	DstDirPath   string `json:"dst_path"`
+	// ConflictPolicy decides what to do if a different file with the same name is in the dst dir
+	ConflictPolicy ConflictPolicy `json:"conflict_policy"`
+}
+
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	ConflictRename    ConflictPolicy = "rename"
+	ConflictNewer     ConflictPolicy = "newer"
+)
+
+// conflictPolicyFromCtx the policy is passed with conf.ConflictPolicyKey, overwrite by default
+func conflictPolicyFromCtx(ctx context.Context) ConflictPolicy {
+	if p, ok := ctx.Value(conf.ConflictPolicyKey).(ConflictPolicy); ok && p != "" {
+		return p
+	}
+	return ConflictOverwrite
 }
 
 func (t *CopyTask) GetName() string {
-	return fmt.Sprintf("copy [%s](%s) to [%s](%s)",
-		t.srcStorage.GetStorage().MountPath, t.srcObjPath, t.dstStorage.GetStorage().MountPath, t.dstDirPath)
+	return fmt.Sprintf("copy [%s](%s) to [%s](%s)", t.SrcStorageMp, t.SrcObjPath, t.DstStorageMp, t.DstDirPath)
 }
 
 func (t *CopyTask) GetStatus() string {
@@ -32,7 +56,35 @@ func (t *CopyTask) GetStatus() string {
 }
 
 func (t *CopyTask) Run() error {
-	return copyBetween2Storages(t, t.srcStorage, t.dstStorage, t.srcObjPath, t.dstDirPath)
+	// the storages are got each time, as a storage may be reloaded while the task is queued
+	srcStorage, err := op.GetStorageByMountPath(t.SrcStorageMp)
+	if err != nil {
+		return errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, err := op.GetStorageByMountPath(t.DstStorageMp)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst storage")
+	}
+	return copyBetween2Storages(t, srcStorage, dstStorage, t.SrcObjPath, t.DstDirPath)
+}
+
+func (t *CopyTask) OnSucceeded() {
+	op.HandleEventHook(model.EventCopySucceeded, t.eventData())
+}
+
+func (t *CopyTask) OnFailed() {
+	data := t.eventData()
+	if err := t.GetErr(); err != nil {
+		data["error"] = err.Error()
+	}
+	op.HandleEventHook(model.EventCopyFailed, data)
+}
+
+func (t *CopyTask) eventData() map[string]interface{} {
+	return map[string]interface{}{
+		"path":    stdpath.Join(t.SrcStorageMp, t.SrcObjPath),
+		"dst_dir": stdpath.Join(t.DstStorageMp, t.DstDirPath),
+	}
 }
 
 var CopyTaskManager *tache.Manager[*CopyTask]
@@ -48,10 +100,15 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get dst storage")
 	}
+	err = checkNotInTrash(srcObjActualPath, dstDirActualPath, stdpath.Join(dstDirActualPath, stdpath.Base(srcObjActualPath)))
+	if err != nil {
+		return nil, err
+	}
 	// copy if in the same storage, just call driver.Copy
 	if srcStorage.GetStorage() == dstStorage.GetStorage() {
 		return nil, op.Copy(ctx, srcStorage, srcObjActualPath, dstDirActualPath, lazyCache...)
 	}
+	policy := conflictPolicyFromCtx(ctx)
 	if ctx.Value(conf.NoTaskKey) != nil {
 		srcObj, err := op.Get(ctx, srcStorage, srcObjActualPath)
 		if err != nil {
@@ -59,30 +116,17 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 		}
 		if !srcObj.IsDir() {
 			// copy file directly
-			link, _, err := op.Link(ctx, srcStorage, srcObjActualPath, model.LinkArgs{
-				Header: http.Header{},
-			})
-			if err != nil {
-				return nil, errors.WithMessagef(err, "failed get [%s] link", srcObjPath)
-			}
-			fs := stream.FileStream{
-				Obj: srcObj,
-				Ctx: ctx,
-			}
-			// any link provided is seekable
-			ss, err := stream.NewSeekableStream(fs, link)
-			if err != nil {
-				return nil, errors.WithMessagef(err, "failed get [%s] stream", srcObjPath)
-			}
-			return nil, op.Put(ctx, dstStorage, dstDirActualPath, ss, nil, false)
+			return nil, copyFile(ctx, srcStorage, dstStorage, srcObj, srcObjActualPath, dstDirActualPath, policy, nil)
 		}
 	}
 	// not in the same storage
 	t := &CopyTask{
-		srcStorage: srcStorage,
-		dstStorage: dstStorage,
-		srcObjPath: srcObjActualPath,
-		dstDirPath: dstDirActualPath,
+		SrcStorageMp:   srcStorage.GetStorage().MountPath,
+		DstStorageMp:   dstStorage.GetStorage().MountPath,
+		SrcObjPath:     srcObjActualPath,
+		DstDirPath:     dstDirActualPath,
+		ConflictPolicy: policy,
+		Creator:        task.CreatorFromCtx(ctx),
 	}
 	CopyTaskManager.Add(t)
 	return t, nil
@@ -95,6 +139,12 @@ func copyBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, src
 		return errors.WithMessagef(err, "failed get src [%s] file", srcObjPath)
 	}
 	if srcObj.IsDir() {
+		// make the dst dir first, so an empty dir is copied too
+		dstObjPath := stdpath.Join(dstDirPath, srcObj.GetName())
+		t.Status = "src object is dir, making dst dir"
+		if err := op.MakeDir(t.Ctx(), dstStorage, dstObjPath); err != nil {
+			return errors.WithMessagef(err, "failed make dst [%s] dir", dstObjPath)
+		}
 		t.Status = "src object is dir, listing objs"
 		objs, err := op.List(t.Ctx(), srcStorage, srcObjPath, model.ListArgs{})
 		if err != nil {
@@ -105,12 +155,13 @@ func copyBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, src
 				return nil
 			}
 			srcObjPath := stdpath.Join(srcObjPath, obj.GetName())
-			dstObjPath := stdpath.Join(dstDirPath, srcObj.GetName())
 			CopyTaskManager.Add(&CopyTask{
-				srcStorage: srcStorage,
-				dstStorage: dstStorage,
-				srcObjPath: srcObjPath,
-				dstDirPath: dstObjPath,
+				SrcStorageMp:   srcStorage.GetStorage().MountPath,
+				DstStorageMp:   dstStorage.GetStorage().MountPath,
+				SrcObjPath:     srcObjPath,
+				DstDirPath:     dstObjPath,
+				ConflictPolicy: t.ConflictPolicy,
+				Creator:        t.Creator,
 			})
 		}
 		t.Status = "src object is dir, added all copy tasks of objs"
@@ -124,20 +175,113 @@ func copyFileBetween2Storages(tsk *CopyTask, srcStorage, dstStorage driver.Drive
 	if err != nil {
 		return errors.WithMessagef(err, "failed get src [%s] file", srcFilePath)
 	}
-	link, _, err := op.Link(tsk.Ctx(), srcStorage, srcFilePath, model.LinkArgs{
+	policy := tsk.ConflictPolicy
+	if policy == "" {
+		policy = ConflictOverwrite
+	}
+	return copyFile(tsk.Ctx(), srcStorage, dstStorage, srcFile, srcFilePath, dstDirPath, policy, func(status string) {
+		tsk.Status = status
+	}, tsk.SetProgress)
+}
+
+// copyFile puts srcFile into dstDirPath, the conflicts with the files in the dst dir are resolved by policy.
+// An interrupted copy can be resumed by copying again with the skip or newer policy, the copied files
+// are skipped and the partly uploaded file continues if the dst storage supports it.
+func copyFile(ctx context.Context, srcStorage, dstStorage driver.Driver, srcFile model.Obj, srcFilePath, dstDirPath string,
+	policy ConflictPolicy, setStatus func(string), up ...driver.UpdateProgress) error {
+	if setStatus == nil {
+		setStatus = func(string) {}
+	}
+	name, skip, err := resolveConflict(ctx, dstStorage, srcFile, dstDirPath, policy)
+	if err != nil {
+		return err
+	}
+	if skip {
+		setStatus("skipped, the file exists in the dst dir")
+		if len(up) > 0 {
+			up[0](100)
+		}
+		return nil
+	}
+	link, _, err := op.Link(ctx, srcStorage, srcFilePath, model.LinkArgs{
 		Header: http.Header{},
 	})
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] link", srcFilePath)
 	}
+	var obj model.Obj = srcFile
+	if name != srcFile.GetName() {
+		obj = &model.ObjWrapName{Name: name, Obj: srcFile}
+	}
 	fs := stream.FileStream{
-		Obj: srcFile,
-		Ctx: tsk.Ctx(),
+		Obj: obj,
+		Ctx: ctx,
 	}
 	// any link provided is seekable
 	ss, err := stream.NewSeekableStream(fs, link)
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] stream", srcFilePath)
 	}
-	return op.Put(tsk.Ctx(), dstStorage, dstDirPath, ss, tsk.SetProgress, true)
+	setStatus("uploading")
+	// continue the upload interrupted by the last copy if the dst storage supports it
+	ctx = context.WithValue(ctx, conf.ResumeUploadKey, true)
+	if len(up) > 0 {
+		return op.Put(ctx, dstStorage, dstDirPath, ss, up[0], true)
+	}
+	return op.Put(ctx, dstStorage, dstDirPath, ss, nil, false)
+}
+
+// resolveConflict returns the name to put srcFile with, or skip if it should not be put
+func resolveConflict(ctx context.Context, dstStorage driver.Driver, srcFile model.Obj, dstDirPath string, policy ConflictPolicy) (name string, skip bool, err error) {
+	name = srcFile.GetName()
+	dstObjs, err := op.List(ctx, dstStorage, dstDirPath, model.ListArgs{})
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			return name, false, nil
+		}
+		return "", false, errors.WithMessagef(err, "failed list dst [%s] objs", dstDirPath)
+	}
+	var dstFile model.Obj
+	names := make(map[string]struct{}, len(dstObjs))
+	for _, obj := range dstObjs {
+		names[obj.GetName()] = struct{}{}
+		if obj.GetName() == name {
+			dstFile = obj
+		}
+	}
+	if dstFile == nil {
+		return name, false, nil
+	}
+	// the copied file is skipped by any policy, so a resumed copy doesn't upload it again
+	if !dstFile.IsDir() && isSameFile(srcFile, dstFile) {
+		return name, true, nil
+	}
+	// the file of another size may be left by an interrupted copy, it's never skipped
+	sizeDiffers := !dstFile.IsDir() && dstFile.GetSize() != srcFile.GetSize()
+	switch policy {
+	case ConflictSkip:
+		return name, !sizeDiffers, nil
+	case ConflictNewer:
+		return name, !sizeDiffers && !srcFile.ModTime().After(dstFile.ModTime()), nil
+	case ConflictRename:
+		ext := stdpath.Ext(name)
+		base := strings.TrimSuffix(name, ext)
+		for i := 1; ; i++ {
+			newName := fmt.Sprintf("%s (%d)%s", base, i, ext)
+			if _, ok := names[newName]; !ok {
+				return newName, false, nil
+			}
+		}
+	default:
+		return name, false, nil
+	}
+}
+
+// isSameFile compares name, size and hash, the files are different if they have no same type of hash
+func isSameFile(src, dst model.Obj) bool {
+	if src.GetName() != dst.GetName() || src.GetSize() != dst.GetSize() {
+		return false
+	}
+	equal, ok := src.GetHash().Compare(dst.GetHash())
+	return equal && ok
 }
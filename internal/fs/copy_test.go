@@ -0,0 +1,146 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+// newLocalStorage mounts a temp dir with the local driver at mountPath
+func newLocalStorage(t *testing.T, mountPath string) (driver.Driver, string) {
+	root := t.TempDir()
+	_, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: mountPath,
+		Addition: `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath(mountPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return storage, root
+}
+
+func TestCopyFileConflict(t *testing.T) {
+	ctx := context.Background()
+	srcStorage, srcRoot := newLocalStorage(t, "/copy_src")
+	dstStorage, dstRoot := newLocalStorage(t, "/copy_dst")
+	src, dst := filepath.Join(srcRoot, "a.txt"), filepath.Join(dstRoot, "a.txt")
+	tests := []struct {
+		policy ConflictPolicy
+		dst    string
+		// the dst file is newer than the src one
+		newer  bool
+		result string
+	}{
+		// a changed file of the same size without hashes is not the same file
+		{policy: ConflictOverwrite, dst: "old", result: "new"},
+		{policy: ConflictSkip, dst: "old", result: "old"},
+		{policy: ConflictNewer, dst: "old", result: "new"},
+		{policy: ConflictNewer, dst: "old", newer: true, result: "old"},
+	}
+	for i, tt := range tests {
+		now := time.Now()
+		if err := os.WriteFile(src, []byte("new"), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dst, []byte(tt.dst), 0666); err != nil {
+			t.Fatal(err)
+		}
+		srcTime, dstTime := now, now.Add(-time.Hour)
+		if tt.newer {
+			srcTime, dstTime = dstTime, srcTime
+		}
+		_ = os.Chtimes(src, srcTime, srcTime)
+		_ = os.Chtimes(dst, dstTime, dstTime)
+		op.ClearCache(srcStorage, "/")
+		op.ClearCache(dstStorage, "/")
+		srcFile, err := op.Get(ctx, srcStorage, "/a.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := copyFile(ctx, srcStorage, dstStorage, srcFile, "/a.txt", "/", tt.policy, nil); err != nil {
+			t.Fatalf("%d: failed copy: %+v", i, err)
+		}
+		data, _ := os.ReadFile(dst)
+		if string(data) != tt.result {
+			t.Errorf("%d: the dst should be %s with the %s policy, got %s", i, tt.result, tt.policy, data)
+		}
+	}
+}
+
+func TestCopyFilePartialDst(t *testing.T) {
+	ctx := context.Background()
+	srcStorage, srcRoot := newLocalStorage(t, "/partial_src")
+	dstStorage, dstRoot := newLocalStorage(t, "/partial_dst")
+	if err := os.WriteFile(filepath.Join(srcRoot, "c.txt"), []byte("0123456789"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	srcTime := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(filepath.Join(srcRoot, "c.txt"), srcTime, srcTime)
+	srcFile, err := op.Get(ctx, srcStorage, "/c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		policy ConflictPolicy
+		// the file the src is copied to
+		name string
+	}{
+		{policy: ConflictOverwrite, name: "c.txt"},
+		{policy: ConflictSkip, name: "c.txt"},
+		{policy: ConflictNewer, name: "c.txt"},
+		{policy: ConflictRename, name: "c (1).txt"},
+	}
+	for _, tt := range tests {
+		// the truncated file left by an interrupted copy is newer than the src
+		for _, name := range []string{"c.txt", "c (1).txt"} {
+			_ = os.Remove(filepath.Join(dstRoot, name))
+		}
+		if err := os.WriteFile(filepath.Join(dstRoot, "c.txt"), []byte("01234"), 0666); err != nil {
+			t.Fatal(err)
+		}
+		op.ClearCache(dstStorage, "/")
+		if err := copyFile(ctx, srcStorage, dstStorage, srcFile, "/c.txt", "/", tt.policy, nil); err != nil {
+			t.Fatalf("failed copy with the %s policy: %+v", tt.policy, err)
+		}
+		if data, _ := os.ReadFile(filepath.Join(dstRoot, tt.name)); string(data) != "0123456789" {
+			t.Errorf("the partial dst should not be skipped with the %s policy, got %s", tt.policy, data)
+		}
+	}
+}
+
+func TestCopyFileResume(t *testing.T) {
+	ctx := context.Background()
+	srcStorage, srcRoot := newLocalStorage(t, "/resume_src")
+	dstStorage, dstRoot := newLocalStorage(t, "/resume_dst")
+	if err := os.WriteFile(filepath.Join(srcRoot, "b.txt"), []byte("0123456789"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	// the first half was uploaded by the interrupted copy,
+	partial := filepath.Join(dstRoot, ".b.txt.10.alist_partial")
+	// the uploaded part differs from the src so it shows that only the rest is copied
+	if err := os.WriteFile(partial, []byte("abcde"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	srcFile, err := op.Get(ctx, srcStorage, "/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := copyFile(ctx, srcStorage, dstStorage, srcFile, "/b.txt", "/", ConflictSkip, nil); err != nil {
+		t.Fatalf("failed copy: %+v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dstRoot, "b.txt"))
+	if string(data) != "abcde56789" {
+		t.Errorf("only the rest of the file should be copied, got %s", data)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("the partial file should be moved to the dst")
+	}
+}
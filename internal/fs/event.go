@@ -0,0 +1,20 @@
+package fs
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+// emitEvent passes an event on path done in ctx to the event hooks
+func emitEvent(ctx context.Context, event, path string, data map[string]interface{}) {
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	data["path"] = path
+	if user, ok := ctx.Value("user").(*model.User); ok && user != nil {
+		data["user"] = user.Username
+	}
+	op.HandleEventHook(event, data)
+}
@@ -2,9 +2,13 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
 	log "github.com/sirupsen/logrus"
 	"github.com/xhofe/tache"
 )
@@ -58,6 +62,7 @@ func MakeDir(ctx context.Context, path string, lazyCache ...bool) error {
 	if err != nil {
 		log.Errorf("failed make dir %s: %+v", path, err)
 	}
+	audit.Log(ctx, model.AuditMkdir, path, "", err)
 	return err
 }
 
@@ -66,6 +71,11 @@ func Move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) er
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
 	}
+	audit.Log(ctx, model.AuditMove, srcPath, dstDirPath, err)
+	if err == nil {
+		emitEvent(ctx, model.EventObjMoved, srcPath, map[string]interface{}{"dst_dir": dstDirPath})
+		op.MoveUploads(srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
+	}
 	return err
 }
 
@@ -74,6 +84,25 @@ func Copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool)
 	if err != nil {
 		log.Errorf("failed copy %s to %s: %+v", srcObjPath, dstDirPath, err)
 	}
+	audit.Log(ctx, model.AuditCopy, srcObjPath, dstDirPath, err)
+	return res, err
+}
+
+func Extract(ctx context.Context, srcPath, dstDirPath string) (tache.TaskWithInfo, error) {
+	res, err := extract(ctx, srcPath, dstDirPath)
+	if err != nil {
+		log.Errorf("failed extract %s to %s: %+v", srcPath, dstDirPath, err)
+	}
+	audit.Log(ctx, model.AuditExtract, srcPath, dstDirPath, err)
+	return res, err
+}
+
+func Compress(ctx context.Context, srcDir string, names []string, dstDirPath, name, format, password string) (tache.TaskWithInfo, error) {
+	res, err := compress(ctx, srcDir, names, dstDirPath, name, format, password)
+	if err != nil {
+		log.Errorf("failed compress %v in %s to %s: %+v", names, srcDir, stdpath.Join(dstDirPath, name), err)
+	}
+	audit.Log(ctx, model.AuditCompress, srcDir, stdpath.Join(dstDirPath, name), err)
 	return res, err
 }
 
@@ -82,14 +111,30 @@ func Rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 	if err != nil {
 		log.Errorf("failed rename %s to %s: %+v", srcPath, dstName, err)
 	}
+	audit.Log(ctx, model.AuditRename, srcPath, dstName, err)
+	if err == nil {
+		emitEvent(ctx, model.EventObjRenamed, srcPath, map[string]interface{}{"new_name": dstName})
+		op.MoveUploads(srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
+	}
 	return err
 }
 
 func Remove(ctx context.Context, path string) error {
+	obj, _ := get(ctx, path)
 	err := remove(ctx, path)
 	if err != nil {
 		log.Errorf("failed remove %s: %+v", path, err)
 	}
+	audit.Log(ctx, model.AuditRemove, path, "", err)
+	if err == nil {
+		emitEvent(ctx, model.EventObjRemoved, path, nil)
+		// the usage is given back to the uploaders, a folder may have many of them so it's done in background
+		if obj != nil && obj.IsDir() {
+			go op.RemoveUploads(path)
+		} else {
+			op.RemoveUploads(path)
+		}
+	}
 	return err
 }
 
@@ -98,14 +143,21 @@ func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
+	audit.Log(ctx, model.AuditPut, stdpath.Join(dstDirPath, file.GetName()), "", err)
+	if err == nil {
+		emitEvent(ctx, model.EventUploadFinished, stdpath.Join(dstDirPath, file.GetName()),
+			map[string]interface{}{"size": file.GetSize()})
+		op.AddUpload(task.CreatorFromCtx(ctx).UserID, stdpath.Join(dstDirPath, file.GetName()), file.GetSize())
+	}
 	return err
 }
 
-func PutAsTask(dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo, error) {
-	t, err := putAsTask(dstDirPath, file)
+func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo, error) {
+	t, err := putAsTask(ctx, dstDirPath, file)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
+	audit.Log(ctx, model.AuditPut, stdpath.Join(dstDirPath, file.GetName()), "", err)
 	return t, err
 }
 
@@ -120,6 +172,11 @@ func GetStorage(path string, args *GetStoragesArgs) (driver.Driver, error) {
 	return storageDriver, nil
 }
 
+// GetDetails returns the space usage of the storage at path, or the sum of the storages under a virtual folder
+func GetDetails(ctx context.Context, path string) (*model.StorageDetails, error) {
+	return op.GetDetailsByPath(ctx, path)
+}
+
 func Other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
 	res, err := other(ctx, args)
 	if err != nil {
@@ -0,0 +1,88 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+)
+
+func TestUsage(t *testing.T) {
+	_, root := newLocalStorage(t, "/usage")
+	if err := os.MkdirAll(filepath.Join(root, "dir"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "dir", "other.txt"), []byte("other"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{Username: "usage", Role: model.GENERAL, BasePath: "/", Permission: 0xff, QuotaBytes: 10}
+	if err := op.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), "user", user)
+	usageOf := func() (int64, int64) {
+		usage, err := op.GetUserUsage(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return usage.Bytes, usage.Files
+	}
+
+	file := &stream.FileStream{Obj: &model.Object{Name: "a.txt", Size: 6}, Reader: strings.NewReader("upload")}
+	if err := PutDirectly(ctx, "/usage_not_found", file); err == nil {
+		t.Errorf("the upload to no storage should fail")
+	}
+	if bytes, files := usageOf(); bytes != 0 || files != 0 {
+		t.Errorf("the failed upload should not be charged, got %d bytes of %d files", bytes, files)
+	}
+	file = &stream.FileStream{Obj: &model.Object{Name: "a.txt", Size: 6}, Reader: strings.NewReader("upload")}
+	if err := PutDirectly(ctx, "/usage/dir", file); err != nil {
+		t.Fatalf("failed put: %+v", err)
+	}
+	if bytes, files := usageOf(); bytes != 6 || files != 1 {
+		t.Errorf("the upload should be charged, got %d bytes of %d files", bytes, files)
+	}
+	if err := op.CheckQuota(user, 5, 1); err == nil {
+		t.Errorf("the upload over the quota should be rejected")
+	}
+
+	// the usage is given back to the uploader, whoever removes the file
+	admin := &model.User{Username: "usage_admin", Role: model.ADMIN, BasePath: "/"}
+	adminCtx := context.WithValue(context.Background(), "user", admin)
+	if err := Rename(adminCtx, "/usage/dir/a.txt", "b.txt"); err != nil {
+		t.Fatalf("failed rename: %+v", err)
+	}
+	if err := Remove(adminCtx, "/usage/dir/b.txt"); err != nil {
+		t.Fatalf("failed remove: %+v", err)
+	}
+	if bytes, files := usageOf(); bytes != 0 || files != 0 {
+		t.Errorf("the removed file should be given back to the uploader, got %d bytes of %d files", bytes, files)
+	}
+
+	// the files in a removed folder are given back in background
+	file = &stream.FileStream{Obj: &model.Object{Name: "c.txt", Size: 6}, Reader: strings.NewReader("upload")}
+	if err := PutDirectly(ctx, "/usage/dir", file); err != nil {
+		t.Fatalf("failed put: %+v", err)
+	}
+	if err := Remove(adminCtx, "/usage/dir"); err != nil {
+		t.Fatalf("failed remove: %+v", err)
+	}
+	for i := 0; i < 100; i++ {
+		if bytes, files := usageOf(); bytes == 0 && files == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if bytes, files := usageOf(); bytes != 0 || files != 0 {
+		t.Errorf("the files in the removed folder should be given back, got %d bytes of %d files", bytes, files)
+	}
+	if err := op.CheckQuota(user, 5, 1); err != nil {
+		t.Errorf("the upload should be allowed after removing: %+v", err)
+	}
+}
@@ -22,6 +22,9 @@ func get(ctx context.Context, path string) (model.Obj, error) {
 			}
 		}
 	}
+	if e, ok := findArchive(ctx, path); ok && e.innerPath != "/" {
+		return getInArchive(ctx, e)
+	}
 	storage, actualPath, err := op.GetStorageAndActualPath(path)
 	if err != nil {
 		// if there are no storage prefix with path, maybe root folder
@@ -35,5 +38,8 @@ func get(ctx context.Context, path string) (model.Obj, error) {
 		}
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkNotInTrash(actualPath); err != nil {
+		return nil, err
+	}
 	return op.Get(ctx, storage, actualPath)
 }
@@ -9,14 +9,32 @@ import (
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 func link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
+	if e, ok := findArchive(ctx, path); ok && e.innerPath != "/" {
+		return linkInArchive(ctx, e)
+	}
 	storage, actualPath, err := op.GetStorageAndActualPath(path)
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkNotInTrash(actualPath); err != nil {
+		return nil, nil, err
+	}
 	l, obj, err := op.Link(ctx, storage, actualPath, args)
+	if err != nil {
+		// fail over to the other storages of the balance group
+		for _, s := range op.GetBalanceFallbacks(storage) {
+			var e error
+			if l, obj, e = op.Link(ctx, s, actualPath, args); e == nil {
+				log.Warnf("failed link %s with %s, fail over to %s: %v", path, storage.GetStorage().MountPath, s.GetStorage().MountPath, err)
+				err = nil
+				break
+			}
+		}
+	}
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "failed link")
 	}
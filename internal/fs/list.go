@@ -14,6 +14,17 @@ import (
 func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error) {
 	meta, _ := ctx.Value("meta").(*model.Meta)
 	user, _ := ctx.Value("user").(*model.User)
+	if e, ok := findArchive(ctx, path); ok {
+		objs, err := listArchive(ctx, e)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed list archive")
+		}
+		om := model.NewObjMerge()
+		if whetherHide(user, meta, path) {
+			om.InitHideReg(meta.Hide)
+		}
+		return om.Merge(objs), nil
+	}
 	virtualFiles := op.GetStorageVirtualFilesByPath(path)
 	storage, actualPath, err := op.GetStorageAndActualPath(path)
 	if err != nil && len(virtualFiles) == 0 {
@@ -22,6 +33,9 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 
 	var _objs []model.Obj
 	if storage != nil {
+		if err = checkNotInTrash(actualPath); err != nil {
+			return nil, err
+		}
 		_objs, err = op.List(ctx, storage, actualPath, model.ListArgs{
 			ReqPath: path,
 		}, args.Refresh)
@@ -33,6 +47,10 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 				return nil, errors.WithMessage(err, "failed get objs")
 			}
 		}
+		// the recycle bin is only reachable by the trash api
+		_objs = utils.SliceFilter(_objs, func(obj model.Obj) bool {
+			return !isTrashDir(actualPath, obj)
+		})
 	}
 
 	om := model.NewObjMerge()
@@ -2,6 +2,7 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
 
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -14,6 +15,9 @@ func makeDir(ctx context.Context, path string, lazyCache ...bool) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkNotInTrash(actualPath); err != nil {
+		return err
+	}
 	return op.MakeDir(ctx, storage, actualPath, lazyCache...)
 }
 
@@ -26,6 +30,9 @@ func move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) er
 	if err != nil {
 		return errors.WithMessage(err, "failed get dst storage")
 	}
+	if err = checkNotInTrash(srcActualPath, dstDirActualPath, stdpath.Join(dstDirActualPath, stdpath.Base(srcActualPath))); err != nil {
+		return err
+	}
 	if srcStorage.GetStorage() != dstStorage.GetStorage() {
 		return errors.WithStack(errs.MoveBetweenTwoStorages)
 	}
@@ -37,6 +44,9 @@ func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkNotInTrash(srcActualPath, stdpath.Join(stdpath.Dir(srcActualPath), dstName)); err != nil {
+		return err
+	}
 	return op.Rename(ctx, storage, srcActualPath, dstName, lazyCache...)
 }
 
@@ -45,6 +55,12 @@ func remove(ctx context.Context, path string) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkNotInTrash(actualPath); err != nil {
+		return err
+	}
+	if storage.GetStorage().RecycleBin && actualPath != "/" {
+		return moveToTrash(ctx, storage, actualPath)
+	}
 	return op.Remove(ctx, storage, actualPath)
 }
 
@@ -53,6 +69,9 @@ func other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkNotInTrash(actualPath); err != nil {
+		return nil, err
+	}
 	args.Path = actualPath
 	return op.Other(ctx, storage, args)
 }
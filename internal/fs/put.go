@@ -3,41 +3,66 @@ package fs
 import (
 	"context"
 	"fmt"
-	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/pkg/errors"
 	"github.com/xhofe/tache"
+	stdpath "path"
 )
 
 type UploadTask struct {
 	tache.Base
-	storage          driver.Driver
+	task.Creator
+	storageMp        string
 	dstDirActualPath string
 	file             model.FileStreamer
 }
 
 func (t *UploadTask) GetName() string {
-	return fmt.Sprintf("upload %s to [%s](%s)", t.file.GetName(), t.storage.GetStorage().MountPath, t.dstDirActualPath)
+	return fmt.Sprintf("upload %s to [%s](%s)", t.file.GetName(), t.storageMp, t.dstDirActualPath)
 }
 
 func (t *UploadTask) GetStatus() string {
 	return "uploading"
 }
 
+// Recoverable the uploaded stream is gone after restart, so the task can't be resumed
+func (t *UploadTask) Recoverable() bool {
+	return false
+}
+
 func (t *UploadTask) Run() error {
-	return op.Put(t.Ctx(), t.storage, t.dstDirActualPath, t.file, t.SetProgress, true)
+	// the storage is got when the task runs, as it may be reloaded while the task is queued
+	storage, err := op.GetStorageByMountPath(t.storageMp)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	return op.Put(t.Ctx(), storage, t.dstDirActualPath, t.file, t.SetProgress, true)
+}
+
+// OnSucceeded charges the upload to the quota of the creator, the queued uploads which fail are not charged
+func (t *UploadTask) OnSucceeded() {
+	path := stdpath.Join(t.storageMp, t.dstDirActualPath, t.file.GetName())
+	op.AddUpload(t.UserID, path, t.file.GetSize())
+	op.HandleEventHook(model.EventUploadFinished, map[string]interface{}{
+		"path": path,
+		"size": t.file.GetSize(),
+	})
 }
 
 var UploadTaskManager *tache.Manager[*UploadTask]
 
 // putAsTask add as a put task and return immediately
-func putAsTask(dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo, error) {
+func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo, error) {
 	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkNotInTrash(stdpath.Join(dstDirActualPath, file.GetName())); err != nil {
+		return nil, err
+	}
 	if storage.Config().NoUpload {
 		return nil, errors.WithStack(errs.UploadNotSupported)
 	}
@@ -50,9 +75,10 @@ func putAsTask(dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo,
 		//file.SetTmpFile(tempFile)
 	}
 	t := &UploadTask{
-		storage:          storage,
+		storageMp:        storage.GetStorage().MountPath,
 		dstDirActualPath: dstDirActualPath,
 		file:             file,
+		Creator:          task.CreatorFromCtx(ctx),
 	}
 	UploadTaskManager.Add(t)
 	return t, nil
@@ -64,6 +90,9 @@ func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkNotInTrash(stdpath.Join(dstDirActualPath, file.GetName())); err != nil {
+		return err
+	}
 	if storage.Config().NoUpload {
 		return errors.WithStack(errs.UploadNotSupported)
 	}
@@ -0,0 +1,226 @@
+package fs
+
+import (
+	"context"
+	stdpath "path"
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// TrashDirName is the recycle bin dir in the root of a storage,
+// each removed object is put into a sub dir of it named by the remove time,
+// so objects with the same name never conflict.
+const TrashDirName = ".alist_trash"
+
+// isInTrash checks whether the actual path is the recycle bin or in it
+func isInTrash(actualPath string) bool {
+	return utils.IsSubPath("/"+TrashDirName, actualPath)
+}
+
+// IsTrashPath checks whether the path is the recycle bin of its storage or in it
+func IsTrashPath(path string) bool {
+	_, actualPath, err := op.GetStorageAndActualPath(path)
+	return err == nil && isInTrash(actualPath)
+}
+
+// checkNotInTrash rejects the actual paths in the recycle bin, which is only reachable by the trash api,
+// so the objects in it are never changed without their trash items
+func checkNotInTrash(actualPaths ...string) error {
+	for _, actualPath := range actualPaths {
+		if isInTrash(actualPath) {
+			return errors.Wrapf(errs.ObjectNotFound, "%s is in the recycle bin", actualPath)
+		}
+	}
+	return nil
+}
+
+// isTrashDir checks whether obj is the recycle bin dir listed in actualDirPath
+func isTrashDir(actualDirPath string, obj model.Obj) bool {
+	return obj.IsDir() && obj.GetName() == TrashDirName && utils.PathEqual(actualDirPath, "/")
+}
+
+// moveToTrash moves the object at actualPath into the recycle bin of storage
+func moveToTrash(ctx context.Context, storage driver.Driver, actualPath string) error {
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			return nil
+		}
+		return errors.WithMessage(err, "failed to get object")
+	}
+	removedAt := time.Now()
+	trashDir := stdpath.Join("/", TrashDirName, strconv.FormatInt(removedAt.UnixNano(), 10))
+	if err = op.MakeDir(ctx, storage, trashDir); err != nil {
+		return errors.WithMessage(err, "failed make trash dir")
+	}
+	if err = moveInStorage(ctx, storage, actualPath, trashDir); err != nil {
+		_ = op.Remove(ctx, storage, trashDir)
+		return errors.WithMessage(err, "failed move to trash")
+	}
+	s := storage.GetStorage()
+	return db.CreateTrashItem(&model.TrashItem{
+		StorageID: s.ID,
+		MountPath: s.MountPath,
+		Path:      actualPath,
+		TrashPath: stdpath.Join(trashDir, obj.GetName()),
+		Name:      obj.GetName(),
+		Size:      obj.GetSize(),
+		IsDir:     obj.IsDir(),
+		RemovedAt: removedAt,
+	})
+}
+
+// moveInStorage moves srcPath into dstDirPath, if the driver can't move
+// the object is copied then removed.
+func moveInStorage(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string) error {
+	err := op.Move(ctx, storage, srcPath, dstDirPath)
+	if !errs.IsNotImplement(err) {
+		return err
+	}
+	if err = copyInStorage(ctx, storage, srcPath, dstDirPath); err != nil {
+		return err
+	}
+	return op.Remove(ctx, storage, srcPath)
+}
+
+// copyInStorage copies srcPath into dstDirPath with the driver,
+// or by streaming the files one by one if the driver can't copy
+func copyInStorage(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string) error {
+	err := op.Copy(ctx, storage, srcPath, dstDirPath)
+	if !errs.IsNotImplement(err) {
+		return err
+	}
+	obj, err := op.Get(ctx, storage, srcPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s]", srcPath)
+	}
+	if !obj.IsDir() {
+		return copyFile(ctx, storage, storage, obj, srcPath, dstDirPath, ConflictOverwrite, nil)
+	}
+	dstPath := stdpath.Join(dstDirPath, obj.GetName())
+	if err = op.MakeDir(ctx, storage, dstPath); err != nil {
+		return errors.WithMessagef(err, "failed make dir [%s]", dstPath)
+	}
+	objs, err := op.List(ctx, storage, srcPath, model.ListArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list [%s]", srcPath)
+	}
+	for _, o := range objs {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		if err = copyInStorage(ctx, storage, stdpath.Join(srcPath, o.GetName()), dstPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getTrashStorage returns the storage of item, nil if the storage has been deleted
+func getTrashStorage(item *model.TrashItem) (driver.Driver, error) {
+	s, err := db.GetStorageById(item.StorageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return op.GetStorageByMountPath(s.MountPath)
+}
+
+// RestoreTrash moves the trash item back to its original path
+func RestoreTrash(ctx context.Context, id uint) error {
+	item, err := db.GetTrashItemById(id)
+	if err != nil {
+		return err
+	}
+	storage, err := getTrashStorage(item)
+	if err != nil {
+		return err
+	}
+	if storage == nil {
+		return errors.New("the storage of the trash item has been deleted")
+	}
+	if _, err = op.Get(ctx, storage, item.Path); err == nil {
+		return errors.Errorf("[%s] already exists", item.Path)
+	} else if !errs.IsObjectNotFound(err) {
+		return err
+	}
+	dstDirPath := stdpath.Dir(item.Path)
+	if err = op.MakeDir(ctx, storage, dstDirPath); err != nil {
+		return errors.WithMessagef(err, "failed make dir [%s]", dstDirPath)
+	}
+	if err = moveInStorage(ctx, storage, item.TrashPath, dstDirPath); err != nil {
+		return errors.WithMessage(err, "failed restore from trash")
+	}
+	if err = op.Remove(ctx, storage, stdpath.Dir(item.TrashPath)); err != nil {
+		log.Warnf("failed remove trash dir of [%s]: %+v", item.Path, err)
+	}
+	return db.DeleteTrashItemById(item.ID)
+}
+
+// PurgeTrash removes the trash item permanently
+func PurgeTrash(ctx context.Context, id uint) error {
+	item, err := db.GetTrashItemById(id)
+	if err != nil {
+		return err
+	}
+	return purgeTrashItem(ctx, item)
+}
+
+// PurgeStorageTrash removes all trash items of a storage permanently
+func PurgeStorageTrash(ctx context.Context, storageId uint) error {
+	items, err := db.GetTrashItemsByStorage(storageId)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		if err := purgeTrashItem(ctx, &items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func purgeTrashItem(ctx context.Context, item *model.TrashItem) error {
+	storage, err := getTrashStorage(item)
+	if err != nil {
+		return err
+	}
+	if storage != nil {
+		if err = op.Remove(ctx, storage, stdpath.Dir(item.TrashPath)); err != nil {
+			return errors.WithMessagef(err, "failed purge [%s]", item.Path)
+		}
+	}
+	return db.DeleteTrashItemById(item.ID)
+}
+
+// CleanExpiredTrash purges the trash items removed before the retention days
+func CleanExpiredTrash() {
+	days := setting.GetInt(conf.RecycleBinRetention, 30)
+	if days <= 0 {
+		return
+	}
+	items, err := db.GetTrashItemsRemovedBefore(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Errorf("failed get expired trash items: %+v", err)
+		return
+	}
+	for i := range items {
+		if err := purgeTrashItem(context.Background(), &items[i]); err != nil {
+			log.Errorf("failed purge expired trash item: %+v", err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package fs
+
+import (
+	"context"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+)
+
+func TestTrashPathRejected(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	id, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/trash", RecycleBin: true,
+		Addition: `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(name), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Remove(ctx, "/trash/a.txt"); err != nil {
+		t.Fatalf("failed remove: %+v", err)
+	}
+	items, err := db.GetTrashItemsByStorage(id)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("the removed file should be in the recycle bin, got %d items: %+v", len(items), err)
+	}
+	trashPath := stdpath.Join("/trash", items[0].TrashPath)
+
+	objs, err := List(ctx, "/trash", &ListArgs{})
+	if err != nil {
+		t.Fatalf("failed list: %+v", err)
+	}
+	for _, obj := range objs {
+		if obj.GetName() == TrashDirName {
+			t.Errorf("the recycle bin should not be listed")
+		}
+	}
+	newFile := func(name string) model.FileStreamer {
+		return &stream.FileStream{Obj: &model.Object{Name: name, Size: 1}, Reader: strings.NewReader("x")}
+	}
+	checks := map[string]func() error{
+		"list": func() error {
+			_, err := List(ctx, "/trash/"+TrashDirName, &ListArgs{NoLog: true})
+			return err
+		},
+		"get": func() error {
+			_, err := Get(ctx, trashPath, &GetArgs{NoLog: true})
+			return err
+		},
+		"link": func() error {
+			_, _, err := Link(ctx, trashPath, model.LinkArgs{})
+			return err
+		},
+		"remove":        func() error { return Remove(ctx, trashPath) },
+		"rename":        func() error { return Rename(ctx, trashPath, "c.txt") },
+		"rename to bin": func() error { return Rename(ctx, "/trash/b.txt", TrashDirName) },
+		"mkdir":         func() error { return MakeDir(ctx, "/trash/"+TrashDirName+"/d") },
+		"move out":      func() error { return Move(ctx, trashPath, "/trash") },
+		"move in":       func() error { return Move(ctx, "/trash/b.txt", "/trash/"+TrashDirName) },
+		"put in":        func() error { return PutDirectly(ctx, "/trash/"+TrashDirName, newFile("c.txt")) },
+		"put bin":       func() error { return PutDirectly(ctx, "/trash", newFile(TrashDirName)) },
+	}
+	for name, check := range checks {
+		if err := check(); !errs.IsObjectNotFound(err) {
+			t.Errorf("%s in the recycle bin should be rejected, got %v", name, err)
+		}
+	}
+
+	if err := RestoreTrash(ctx, items[0].ID); err != nil {
+		t.Fatalf("failed restore: %+v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "a.txt")); err != nil || string(data) != "a.txt" {
+		t.Errorf("the file should be restored by the trash api: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "b.txt")); string(data) != "b.txt" {
+		t.Errorf("the file moved into the recycle bin should be kept")
+	}
+}
@@ -0,0 +1,146 @@
+//go:build fuse
+
+package fuse
+
+import (
+	"container/list"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// BlockCache keeps fixed size blocks of remote files on the local disk,
+// the least recently used blocks are evicted once MaxSize is exceeded.
+type BlockCache struct {
+	BlockSize int64
+	MaxSize   int64
+
+	dir   string
+	mu    sync.Mutex
+	size  int64
+	lru   *list.List
+	items map[string]*list.Element
+}
+
+type cacheBlock struct {
+	name string
+	size int64
+}
+
+// NewBlockCache creates a block cache in its own dir under the temp dir,
+// so the mounts running at the same time don't share or drop the blocks of each other.
+// The dir is removed by Clear.
+func NewBlockCache(blockSize, maxSize int64) (*BlockCache, error) {
+	if blockSize <= 0 {
+		return nil, errors.New("block size must be positive")
+	}
+	if err := os.MkdirAll(conf.Conf.TempDir, 0777); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	dir, err := os.MkdirTemp(conf.Conf.TempDir, "fuse_cache_")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &BlockCache{
+		BlockSize: blockSize,
+		MaxSize:   maxSize,
+		dir:       dir,
+		lru:       list.New(),
+		items:     make(map[string]*list.Element),
+	}, nil
+}
+
+// blockName identifies a block, modified time and size are part of it,
+// so a changed remote file never reads stale blocks
+func blockName(path string, obj model.Obj, index int64) string {
+	sum := md5.Sum([]byte(fmt.Sprintf("%s:%d:%d", path, obj.GetSize(), obj.ModTime().UnixNano())))
+	return fmt.Sprintf("%s_%d", hex.EncodeToString(sum[:]), index)
+}
+
+func (c *BlockCache) Get(name string) ([]byte, bool) {
+	c.mu.Lock()
+	e, ok := c.items[name]
+	if ok {
+		c.lru.MoveToFront(e)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil {
+		log.Warnf("fuse: failed read cache block %s: %+v", name, err)
+		c.remove(name)
+		return nil, false
+	}
+	return data, true
+}
+
+func (c *BlockCache) Put(name string, data []byte) {
+	if int64(len(data)) > c.MaxSize {
+		return
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, name), data, 0666); err != nil {
+		log.Warnf("fuse: failed write cache block %s: %+v", name, err)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[name]; ok {
+		c.size -= e.Value.(*cacheBlock).size
+		c.lru.Remove(e)
+	}
+	c.items[name] = c.lru.PushFront(&cacheBlock{name: name, size: int64(len(data))})
+	c.size += int64(len(data))
+	for c.size > c.MaxSize {
+		e := c.lru.Back()
+		if e == nil {
+			break
+		}
+		c.evict(e)
+	}
+}
+
+func (c *BlockCache) remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[name]; ok {
+		c.evict(e)
+	}
+}
+
+// evict must be called with the lock held
+func (c *BlockCache) evict(e *list.Element) {
+	b := e.Value.(*cacheBlock)
+	c.lru.Remove(e)
+	delete(c.items, b.name)
+	c.size -= b.size
+	if err := os.Remove(filepath.Join(c.dir, b.name)); err != nil && !os.IsNotExist(err) {
+		log.Warnf("fuse: failed remove cache block %s: %+v", b.name, err)
+	}
+}
+
+// Size returns the bytes used by the cached blocks
+func (c *BlockCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Clear removes all the cached blocks
+func (c *BlockCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.items = make(map[string]*list.Element)
+	c.size = 0
+	return errors.WithStack(os.RemoveAll(c.dir))
+}
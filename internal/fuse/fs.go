@@ -1,170 +1,491 @@
-package fuse
+//go:build fuse
 
-import "github.com/winfsp/cgofuse/fuse"
+package fuse
 
+import (
+	"context"
+	"fmt"
+	"os"
+	stdpath "path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// Fs exposes the alist path RootFolder as a fuse file system.
+// Every operation is passed to the internal/fs package, so the
+// mounted tree is exactly what the web or webdav users see.
 type Fs struct {
 	RootFolder string
+	// Cache is optional, read only handles read through it if present
+	Cache *BlockCache
 	fuse.FileSystemBase
-}
-
-func (fs *Fs) Init() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Destroy() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mknod(path string, mode uint32, dev uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mkdir(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Unlink(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rmdir(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Link(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Symlink(target string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readlink(path string) (int, string) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rename(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chmod(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chown(path string, uid uint32, gid uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Access(path string, mask uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Open(path string, flags int) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Truncate(path string, size int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Flush(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Release(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsync(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Opendir(path string) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Releasedir(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Setxattr(path string, name string, value []byte, flags int) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getxattr(path string, name string) (int, []byte) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Removexattr(path string, name string) int {
-	//TODO implement me
-	panic("implement me")
-}
 
-func (fs *Fs) Listxattr(path string, fill func(name string) bool) int {
-	//TODO implement me
-	panic("implement me")
+	ctx     context.Context
+	cancel  context.CancelFunc
+	uid     uint32
+	gid     uint32
+	handles sync.Map // map[uint64]*fileHandle
+	lastFh  uint64
+}
+
+func (f *Fs) Init() {
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+	f.uid, f.gid = uint32(os.Getuid()), uint32(os.Getgid())
+}
+
+func (f *Fs) Destroy() {
+	f.handles.Range(func(key, value any) bool {
+		h := value.(*fileHandle)
+		h.mu.Lock()
+		if err := h.upload(f.ctx); err != nil {
+			log.Errorf("fuse: failed upload %s on destroy: %+v", h.path, err)
+		}
+		_ = h.close()
+		h.mu.Unlock()
+		f.handles.Delete(key)
+		return true
+	})
+	if f.cancel != nil {
+		f.cancel()
+	}
+}
+
+// alistPath converts a fuse path to the mount path in alist
+func (f *Fs) alistPath(path string) string {
+	return utils.FixAndCleanPath(stdpath.Join(f.RootFolder, path))
+}
+
+func (f *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
+	const blockSize = 4096
+	// most drivers can't tell their capacity, so report a large free space
+	// to avoid tools refusing to write into the mount
+	var total, free uint64 = 1 << 40, 1 << 40
+	if details, err := fs.GetDetails(f.ctx, f.alistPath(path)); err == nil && details.TotalSpace > 0 {
+		total, free = uint64(details.TotalSpace), uint64(max(details.FreeSpace, 0))
+	}
+	*stat = fuse.Statfs_t{
+		Bsize:   blockSize,
+		Frsize:  blockSize,
+		Blocks:  total / blockSize,
+		Bfree:   free / blockSize,
+		Bavail:  free / blockSize,
+		Namemax: 255,
+	}
+	return 0
+}
+
+func (f *Fs) Mknod(path string, mode uint32, dev uint64) int {
+	return -fuse.ENOSYS
+}
+
+func (f *Fs) Mkdir(path string, mode uint32) int {
+	return errno(fs.MakeDir(f.ctx, f.alistPath(path)))
+}
+
+func (f *Fs) Unlink(path string) int {
+	obj, err := fs.Get(f.ctx, f.alistPath(path), &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	if obj.IsDir() {
+		return -fuse.EISDIR
+	}
+	return errno(fs.Remove(f.ctx, f.alistPath(path)))
+}
+
+func (f *Fs) Rmdir(path string) int {
+	objs, err := fs.List(f.ctx, f.alistPath(path), &fs.ListArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	if len(objs) > 0 {
+		return -fuse.ENOTEMPTY
+	}
+	return errno(fs.Remove(f.ctx, f.alistPath(path)))
+}
+
+func (f *Fs) Link(oldpath string, newpath string) int {
+	return -fuse.ENOSYS
+}
+
+func (f *Fs) Symlink(target string, newpath string) int {
+	return -fuse.ENOSYS
+}
+
+func (f *Fs) Readlink(path string) (int, string) {
+	return -fuse.ENOSYS, ""
+}
+
+func (f *Fs) Rename(oldpath string, newpath string) int {
+	src, dst := f.alistPath(oldpath), f.alistPath(newpath)
+	if src == dst {
+		return 0
+	}
+	// rename(2) replaces the target, alist drivers usually don't,
+	// so the target is put aside and only removed after the move succeeds
+	dstDir, dstName := stdpath.Split(dst)
+	backupName := ""
+	if _, err := fs.Get(f.ctx, dst, &fs.GetArgs{NoLog: true}); err == nil {
+		backupName = fmt.Sprintf(".%s.%d.alist_replaced", dstName, time.Now().UnixNano())
+		if err = fs.Rename(f.ctx, dst, backupName); err != nil {
+			return errno(err)
+		}
+	}
+	err := f.move(src, dst)
+	if backupName != "" {
+		backup := stdpath.Join(dstDir, backupName)
+		if err != nil {
+			if restoreErr := fs.Rename(f.ctx, backup, dstName); restoreErr != nil {
+				log.Errorf("fuse: failed restore %s from %s: %+v", dst, backup, restoreErr)
+			}
+		} else if removeErr := fs.Remove(f.ctx, backup); removeErr != nil {
+			log.Warnf("fuse: failed remove the replaced %s: %+v", backup, removeErr)
+		}
+	}
+	return errno(err)
+}
+
+// move moves src to dst, which doesn't exist
+func (f *Fs) move(src, dst string) error {
+	srcDir, srcName := stdpath.Split(src)
+	dstDir, dstName := stdpath.Split(dst)
+	if utils.PathEqual(srcDir, dstDir) {
+		return fs.Rename(f.ctx, src, dstName)
+	}
+	if err := fs.Move(f.ctx, src, dstDir); err != nil {
+		return err
+	}
+	if srcName != dstName {
+		return fs.Rename(f.ctx, stdpath.Join(dstDir, srcName), dstName)
+	}
+	return nil
+}
+
+func (f *Fs) Chmod(path string, mode uint32) int {
+	return 0
+}
+
+func (f *Fs) Chown(path string, uid uint32, gid uint32) int {
+	return 0
+}
+
+func (f *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
+	return 0
+}
+
+func (f *Fs) Access(path string, mask uint32) int {
+	return 0
+}
+
+func (f *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
+	h := &fileHandle{path: f.alistPath(path)}
+	if err := h.newTempFile(); err != nil {
+		return errno(err), ^uint64(0)
+	}
+	// the file is only uploaded when the handle is flushed,
+	// it's dirty so an empty new file is uploaded too,
+	// getattr finds it through the writable handle in the meantime
+	h.dirty = true
+	return 0, f.addHandle(h)
+}
+
+func (f *Fs) Open(path string, flags int) (int, uint64) {
+	h := &fileHandle{path: f.alistPath(path)}
+	obj, err := fs.Get(f.ctx, h.path, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if obj.IsDir() {
+		return -fuse.EISDIR, ^uint64(0)
+	}
+	h.obj = obj
+	if flags&fuse.O_ACCMODE == fuse.O_RDONLY {
+		h.cache = f.Cache
+	} else {
+		if err = h.newTempFile(); err != nil {
+			return errno(err), ^uint64(0)
+		}
+		if flags&fuse.O_TRUNC != 0 {
+			h.dirty = true
+		} else if err = h.fetch(f.ctx); err != nil {
+			_ = h.close()
+			return errno(err), ^uint64(0)
+		}
+	}
+	return 0, f.addHandle(h)
+}
+
+func (f *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
+	if h, ok := f.getHandle(fh); ok && h.writable() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		f.fillFileStat(stat, h.size(), h.obj)
+		return 0
+	}
+	// a file still being written may not exist on the storage yet
+	if h := f.findWritableHandle(f.alistPath(path)); h != nil {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		f.fillFileStat(stat, h.size(), h.obj)
+		return 0
+	}
+	obj, err := fs.Get(f.ctx, f.alistPath(path), &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	f.fillStat(stat, obj)
+	return 0
+}
+
+func (f *Fs) Truncate(path string, size int64, fh uint64) int {
+	h, ok := f.getHandle(fh)
+	if !ok {
+		h = f.findWritableHandle(f.alistPath(path))
+	}
+	if h != nil && h.writable() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return errno(h.truncate(size))
+	}
+	// only truncating a closed file to zero is supported, by putting an empty file
+	if size != 0 {
+		return -fuse.ENOSYS
+	}
+	dir, name := stdpath.Split(f.alistPath(path))
+	fsStream := &stream.FileStream{
+		Obj:      &model.Object{Name: name},
+		Reader:   strings.NewReader(""),
+		Mimetype: utils.GetMimeType(name),
+	}
+	return errno(fs.PutDirectly(f.ctx, dir, fsStream))
+}
+
+func (f *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
+	h, ok := f.getHandle(fh)
+	if !ok {
+		return -fuse.EBADF
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n, err := h.readAt(f.ctx, buff, ofst)
+	if err != nil {
+		log.Errorf("fuse: failed read %s at %d: %+v", h.path, ofst, err)
+		return errno(err)
+	}
+	return n
+}
+
+func (f *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
+	h, ok := f.getHandle(fh)
+	if !ok {
+		return -fuse.EBADF
+	}
+	if !h.writable() {
+		return -fuse.EBADF
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n, err := h.writeAt(buff, ofst)
+	if err != nil {
+		return errno(err)
+	}
+	return n
+}
+
+// Flush is called on each close of the file, the written file is uploaded here
+// so that close reports the failed upload, which the result of Release can't do
+func (f *Fs) Flush(path string, fh uint64) int {
+	h, ok := f.getHandle(fh)
+	if !ok {
+		return -fuse.EBADF
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	err := h.upload(f.ctx)
+	if err != nil {
+		log.Errorf("fuse: failed upload %s: %+v", h.path, err)
+	}
+	return errno(err)
+}
+
+// Release only cleans up the handle, its result is ignored by the kernel
+func (f *Fs) Release(path string, fh uint64) int {
+	h, ok := f.getHandle(fh)
+	if !ok {
+		return -fuse.EBADF
+	}
+	f.handles.Delete(fh)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.dirty {
+		log.Errorf("fuse: the changes of %s are dropped as they failed to upload", h.path)
+	}
+	_ = h.close()
+	return 0
+}
+
+func (f *Fs) Fsync(path string, datasync bool, fh uint64) int {
+	h, ok := f.getHandle(fh)
+	if !ok {
+		return -fuse.EBADF
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return errno(h.upload(f.ctx))
+}
+
+func (f *Fs) Opendir(path string) (int, uint64) {
+	obj, err := fs.Get(f.ctx, f.alistPath(path), &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if !obj.IsDir() {
+		return -fuse.ENOTDIR, ^uint64(0)
+	}
+	return 0, 0
+}
+
+func (f *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
+	objs, err := fs.List(f.ctx, f.alistPath(path), &fs.ListArgs{})
+	if err != nil {
+		return errno(err)
+	}
+	fill(".", nil, 0)
+	fill("..", nil, 0)
+	for _, obj := range objs {
+		stat := &fuse.Stat_t{}
+		f.fillStat(stat, obj)
+		if !fill(obj.GetName(), stat, 0) {
+			break
+		}
+	}
+	return 0
+}
+
+func (f *Fs) Releasedir(path string, fh uint64) int {
+	return 0
+}
+
+func (f *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
+	return 0
+}
+
+func (f *Fs) Setxattr(path string, name string, value []byte, flags int) int {
+	return -fuse.ENOSYS
+}
+
+func (f *Fs) Getxattr(path string, name string) (int, []byte) {
+	return -fuse.ENOSYS, nil
+}
+
+func (f *Fs) Removexattr(path string, name string) int {
+	return -fuse.ENOSYS
+}
+
+func (f *Fs) Listxattr(path string, fill func(name string) bool) int {
+	return -fuse.ENOSYS
+}
+
+func (f *Fs) addHandle(h *fileHandle) uint64 {
+	fh := atomic.AddUint64(&f.lastFh, 1)
+	f.handles.Store(fh, h)
+	return fh
+}
+
+func (f *Fs) getHandle(fh uint64) (*fileHandle, bool) {
+	v, ok := f.handles.Load(fh)
+	if !ok {
+		return nil, false
+	}
+	return v.(*fileHandle), true
+}
+
+func (f *Fs) findWritableHandle(path string) *fileHandle {
+	var res *fileHandle
+	f.handles.Range(func(key, value any) bool {
+		h := value.(*fileHandle)
+		if h.path == path && h.writable() {
+			res = h
+			return false
+		}
+		return true
+	})
+	return res
+}
+
+func (f *Fs) fillStat(stat *fuse.Stat_t, obj model.Obj) {
+	if obj.IsDir() {
+		*stat = fuse.Stat_t{
+			Mode:  fuse.S_IFDIR | 0755,
+			Nlink: 2,
+			Uid:   f.uid,
+			Gid:   f.gid,
+			Mtim:  fuse.NewTimespec(obj.ModTime()),
+			Ctim:  fuse.NewTimespec(obj.ModTime()),
+			Atim:  fuse.NewTimespec(obj.ModTime()),
+		}
+		if !obj.CreateTime().IsZero() {
+			stat.Birthtim = fuse.NewTimespec(obj.CreateTime())
+		}
+		return
+	}
+	f.fillFileStat(stat, obj.GetSize(), obj)
+}
+
+func (f *Fs) fillFileStat(stat *fuse.Stat_t, size int64, obj model.Obj) {
+	*stat = fuse.Stat_t{
+		Mode:    fuse.S_IFREG | 0644,
+		Nlink:   1,
+		Uid:     f.uid,
+		Gid:     f.gid,
+		Size:    size,
+		Blksize: 4096,
+		Blocks:  (size + 511) / 512,
+	}
+	if obj == nil {
+		now := fuse.Now()
+		stat.Mtim, stat.Ctim, stat.Atim = now, now, now
+		return
+	}
+	stat.Mtim = fuse.NewTimespec(obj.ModTime())
+	stat.Ctim, stat.Atim = stat.Mtim, stat.Mtim
+	if !obj.CreateTime().IsZero() {
+		stat.Birthtim = fuse.NewTimespec(obj.CreateTime())
+	}
+}
+
+// errno maps the errors of alist to the negative errno that fuse expects
+func errno(err error) int {
+	if err == nil {
+		return 0
+	}
+	switch cause := errors.Cause(err); {
+	case errs.IsNotFoundError(err):
+		return -fuse.ENOENT
+	case errors.Is(cause, errs.NotFolder):
+		return -fuse.ENOTDIR
+	case errors.Is(cause, errs.NotFile):
+		return -fuse.EISDIR
+	case errors.Is(cause, errs.PermissionDenied), errors.Is(cause, errs.UploadNotSupported):
+		return -fuse.EACCES
+	case errs.IsNotSupportError(err), errs.IsNotImplement(err):
+		return -fuse.ENOSYS
+	case errors.Is(cause, os.ErrNotExist):
+		return -fuse.ENOENT
+	default:
+		return -fuse.EIO
+	}
 }
 
 var _ fuse.FileSystemInterface = (*Fs)(nil)
@@ -0,0 +1,252 @@
+//go:build fuse
+
+package fuse
+
+import (
+	"context"
+	"io"
+	"os"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// fileHandle is an opened file of the mounted alist.
+// A read only handle reads the remote file through its link,
+// a writable handle buffers everything in a temp file and
+// uploads it with fs.PutDirectly when the handle is flushed.
+type fileHandle struct {
+	mu    sync.Mutex
+	path  string
+	obj   model.Obj
+	cache *BlockCache
+
+	// read
+	link *model.Link
+	rrc  model.RangeReadCloserIF
+	rc   io.ReadCloser
+	pos  int64
+
+	// write
+	tmpFile *os.File
+	dirty   bool
+}
+
+func (h *fileHandle) writable() bool {
+	return h.tmpFile != nil
+}
+
+func (h *fileHandle) size() int64 {
+	if h.tmpFile != nil {
+		if info, err := h.tmpFile.Stat(); err == nil {
+			return info.Size()
+		}
+	}
+	if h.obj != nil {
+		return h.obj.GetSize()
+	}
+	return 0
+}
+
+// newTempFile creates the temp file that a writable handle buffers into
+func (h *fileHandle) newTempFile() error {
+	f, err := os.CreateTemp(conf.Conf.TempDir, "fuse-*")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	h.tmpFile = f
+	return nil
+}
+
+// fetch copies the current remote content into the temp file,
+// so that a file opened for writing without O_TRUNC keeps its data
+func (h *fileHandle) fetch(ctx context.Context) error {
+	if h.obj == nil || h.obj.GetSize() == 0 {
+		return nil
+	}
+	rc, err := h.rangeRead(ctx, http_range.Range{Start: 0, Length: -1})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	_, err = utils.CopyWithBuffer(h.tmpFile, rc)
+	return errors.WithStack(err)
+}
+
+func (h *fileHandle) rangeRead(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+	if h.link == nil {
+		link, obj, err := fs.Link(ctx, h.path, model.LinkArgs{})
+		if err != nil {
+			return nil, err
+		}
+		h.link = link
+		if h.obj == nil {
+			h.obj = obj
+		}
+	}
+	size := h.obj.GetSize()
+	if r.Length < 0 || r.Start+r.Length > size {
+		r.Length = size - r.Start
+	}
+	if h.link.MFile != nil {
+		return io.NopCloser(io.NewSectionReader(h.link.MFile, r.Start, r.Length)), nil
+	}
+	if h.rrc == nil {
+		if h.link.RangeReadCloser != nil {
+			h.rrc = h.link.RangeReadCloser
+		} else {
+			rrc, err := stream.GetRangeReadCloserFromLink(size, h.link)
+			if err != nil {
+				return nil, err
+			}
+			h.rrc = rrc
+		}
+	}
+	return h.rrc.RangeRead(ctx, r)
+}
+
+// readAt keeps one sequential reader open and only reopens it when
+// the kernel reads at an offset different from where the last read ended
+func (h *fileHandle) readAt(ctx context.Context, buff []byte, ofst int64) (int, error) {
+	if h.writable() {
+		n, err := h.tmpFile.ReadAt(buff, ofst)
+		if err == io.EOF {
+			err = nil
+		}
+		return n, err
+	}
+	size := h.obj.GetSize()
+	if ofst >= size {
+		return 0, nil
+	}
+	if h.cache != nil {
+		return h.readAtCached(ctx, buff, ofst)
+	}
+	if h.rc == nil || h.pos != ofst {
+		if h.rc != nil {
+			_ = h.rc.Close()
+			h.rc = nil
+		}
+		rc, err := h.rangeRead(ctx, http_range.Range{Start: ofst, Length: -1})
+		if err != nil {
+			return 0, err
+		}
+		h.rc = rc
+		h.pos = ofst
+	}
+	n, err := io.ReadFull(h.rc, buff)
+	h.pos += int64(n)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return n, err
+}
+
+// readAtCached reads the whole blocks covering the requested range,
+// every block is fetched from remote only once until it's evicted
+func (h *fileHandle) readAtCached(ctx context.Context, buff []byte, ofst int64) (int, error) {
+	size := h.obj.GetSize()
+	bs := h.cache.BlockSize
+	n := 0
+	for n < len(buff) && ofst < size {
+		index := ofst / bs
+		name := blockName(h.path, h.obj, index)
+		block, ok := h.cache.Get(name)
+		if !ok {
+			start := index * bs
+			length := bs
+			if start+length > size {
+				length = size - start
+			}
+			rc, err := h.rangeRead(ctx, http_range.Range{Start: start, Length: length})
+			if err != nil {
+				return n, err
+			}
+			block = make([]byte, length)
+			_, err = io.ReadFull(rc, block)
+			_ = rc.Close()
+			if err != nil {
+				return n, errors.WithStack(err)
+			}
+			h.cache.Put(name, block)
+		}
+		c := copy(buff[n:], block[ofst-index*bs:])
+		if c == 0 {
+			break
+		}
+		n += c
+		ofst += int64(c)
+	}
+	return n, nil
+}
+
+func (h *fileHandle) writeAt(buff []byte, ofst int64) (int, error) {
+	h.dirty = true
+	return h.tmpFile.WriteAt(buff, ofst)
+}
+
+func (h *fileHandle) truncate(size int64) error {
+	h.dirty = true
+	return h.tmpFile.Truncate(size)
+}
+
+// upload puts the buffered temp file to the parent folder of the handle
+func (h *fileHandle) upload(ctx context.Context) error {
+	if !h.dirty {
+		return nil
+	}
+	if _, err := h.tmpFile.Seek(0, io.SeekStart); err != nil {
+		return errors.WithStack(err)
+	}
+	dir, name := stdpath.Split(h.path)
+	obj := &model.Object{
+		Name:     name,
+		Size:     h.size(),
+		Modified: time.Now(),
+	}
+	if h.obj != nil {
+		obj.Ctime = h.obj.CreateTime()
+	}
+	fsStream := &stream.FileStream{
+		Obj:      obj,
+		Reader:   h.tmpFile,
+		Mimetype: utils.GetMimeType(name),
+	}
+	err := fs.PutDirectly(ctx, dir, fsStream)
+	if err != nil {
+		return err
+	}
+	h.dirty = false
+	h.obj = obj
+	return nil
+}
+
+func (h *fileHandle) close() error {
+	var err error
+	if h.rc != nil {
+		err = h.rc.Close()
+		h.rc = nil
+	}
+	if h.rrc != nil {
+		_ = h.rrc.Close()
+		h.rrc = nil
+	}
+	if h.link != nil && h.link.MFile != nil {
+		_ = h.link.MFile.Close()
+	}
+	h.link = nil
+	if h.tmpFile != nil {
+		_ = h.tmpFile.Close()
+		_ = os.Remove(h.tmpFile.Name())
+		h.tmpFile = nil
+	}
+	return err
+}
@@ -1,9 +1,20 @@
+//go:build fuse
+
 package fuse
 
 import "github.com/winfsp/cgofuse/fuse"
 
-func Mount(mountSrc, mountDst string, opts []string) {
-	fs := &Fs{RootFolder: mountSrc}
+// Mount mounts the alist path mountSrc to the local mountDst in background,
+// call Unmount on the returned host to release it.
+// cache can be nil to read from the storages directly.
+// The returned channel receives the result of the mount once it's unmounted,
+// false means the mount failed or ended abnormally.
+func Mount(mountSrc, mountDst string, opts []string, cache *BlockCache) (*fuse.FileSystemHost, <-chan bool) {
+	fs := &Fs{RootFolder: mountSrc, Cache: cache}
 	host := fuse.NewFileSystemHost(fs)
-	go host.Mount(mountDst, opts)
+	done := make(chan bool, 1)
+	go func() {
+		done <- host.Mount(mountDst, opts)
+	}()
+	return host, done
 }
@@ -0,0 +1,194 @@
+// Package health probes the loaded storages on a schedule. A storage failing the probe
+// is marked degraded and initialized again with exponential backoff until it recovers.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	probeTimeout = 30 * time.Second
+	minBackoff   = time.Minute
+	maxBackoff   = time.Hour
+	// historyRetention is how long the health history is kept
+	historyRetention = 7 * 24 * time.Hour
+)
+
+// State is the current health of a storage
+type State struct {
+	StorageID uint      `json:"storage_id"`
+	MountPath string    `json:"mount_path"`
+	Status    string    `json:"status"`
+	Healthy   bool      `json:"healthy"`
+	Latency   int64     `json:"latency"` // milliseconds of the last probe
+	LastError string    `json:"last_error"`
+	LastCheck time.Time `json:"last_check"`
+	Failures  int       `json:"failures"`  // consecutive failed checks
+	NextInit  time.Time `json:"next_init"` // when the storage will be initialized again if it's still failing
+}
+
+var (
+	states  generic_sync.MapOf[uint, *State]
+	checkMu sync.Mutex
+)
+
+// Check probes the storages which are due, it's called every minute,
+// the interval of each storage is the storage_health_check_interval setting in minutes.
+func Check() {
+	if !conf.StoragesLoaded || !checkMu.TryLock() {
+		return
+	}
+	defer checkMu.Unlock()
+	interval := time.Duration(setting.GetInt(conf.StorageHealthCheckInterval, 5)) * time.Minute
+	if interval <= 0 {
+		return
+	}
+	storages := op.GetAllStorages()
+	loaded := make(map[uint]struct{}, len(storages))
+	var wg sync.WaitGroup
+	for _, storage := range storages {
+		id := storage.GetStorage().ID
+		loaded[id] = struct{}{}
+		state, _ := states.LoadOrStore(id, &State{StorageID: id})
+		if time.Since(state.LastCheck) < interval {
+			continue
+		}
+		wg.Add(1)
+		go func(storage driver.Driver, state State) {
+			defer wg.Done()
+			// the states are replaced instead of modified so that GetStates can read them
+			states.Store(state.StorageID, check(storage, state))
+		}(storage, *state)
+	}
+	wg.Wait()
+	// forget the storages which are disabled or deleted
+	states.Range(func(id uint, _ *State) bool {
+		if _, ok := loaded[id]; !ok {
+			states.Delete(id)
+		}
+		return true
+	})
+}
+
+func check(storage driver.Driver, state State) *State {
+	s := storage.GetStorage()
+	now := time.Now()
+	h := &model.StorageHealth{
+		StorageID: s.ID,
+		MountPath: s.MountPath,
+		Created:   now,
+	}
+	// a storage which failed to init isn't probed, it may panic without the init
+	var err error
+	if op.IsWorking(s.Status) {
+		h.Latency, err = probe(storage)
+	} else {
+		err = errors.New(s.Status)
+	}
+	// the first failure only marks the storage degraded which still serves requests,
+	// the reinit is scheduled with backoff
+	if err != nil && !state.NextInit.IsZero() && !now.Before(state.NextInit) {
+		h.Reinit = true
+		var reinited driver.Driver
+		if reinited, err = op.ReinitStorage(context.Background(), storage); reinited != nil {
+			storage, s = reinited, reinited.GetStorage()
+		}
+		if err == nil {
+			h.Latency, err = probe(storage)
+		}
+	}
+	if err == nil {
+		h.Healthy = true
+		state.Failures = 0
+		state.NextInit = time.Time{}
+		op.SetStorageStatus(storage, op.WORK)
+	} else {
+		h.Error = err.Error()
+		state.Failures++
+		if h.Reinit || state.NextInit.IsZero() {
+			state.NextInit = now.Add(backoff(state.Failures))
+		}
+		if op.IsWorking(s.Status) {
+			op.SetStorageStatus(storage, fmt.Sprintf("%s: %s", op.DEGRADED, h.Error))
+		}
+		log.Warnf("storage %s is unhealthy: %s", s.MountPath, h.Error)
+	}
+	state.MountPath = s.MountPath
+	state.Status = s.Status
+	state.Healthy = h.Healthy
+	state.Latency = h.Latency
+	state.LastError = h.Error
+	state.LastCheck = now
+	if err := db.CreateStorageHealth(h); err != nil {
+		log.Errorf("failed create storage health: %+v", err)
+	}
+	return &state
+}
+
+// probe gets the root of the storage and lists it, it returns the latency in milliseconds
+func probe(storage driver.Driver) (latency int64, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	defer func() {
+		if e := recover(); e != nil {
+			err = errors.Errorf("panic while probing: %v", e)
+		}
+	}()
+	start := time.Now()
+	root, err := op.GetUnwrap(ctx, storage, "/")
+	if err == nil {
+		_, err = storage.List(ctx, root, model.ListArgs{})
+	}
+	if err == nil {
+		op.RecordLatency(storage, time.Since(start))
+	}
+	return time.Since(start).Milliseconds(), err
+}
+
+// backoff doubles the interval of the reinit with each failure
+func backoff(failures int) time.Duration {
+	d := minBackoff
+	for i := 1; i < failures && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
+
+// GetStates returns the current health of the loaded storages which have been checked
+func GetStates() []State {
+	res := make([]State, 0)
+	states.Range(func(_ uint, state *State) bool {
+		if !state.LastCheck.IsZero() {
+			res = append(res, *state)
+		}
+		return true
+	})
+	return res
+}
+
+func GetHistory(storageId uint, pageIndex, pageSize int) ([]model.StorageHealth, int64, error) {
+	return db.GetStorageHealths(storageId, pageIndex, pageSize)
+}
+
+// CleanHistory deletes the expired health history
+func CleanHistory() {
+	if err := db.DeleteStorageHealthsBefore(time.Now().Add(-historyRetention)); err != nil {
+		log.Errorf("failed clean storage health history: %+v", err)
+	}
+}
@@ -0,0 +1,166 @@
+// Package metrics collects the prometheus metrics of requests, proxied bytes,
+// list cache, driver calls, tasks, storages and the search index.
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "alist"
+
+type protocolKey struct{}
+
+var (
+	registry = prometheus.NewRegistry()
+
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "The handled http requests by protocol, route, method and status code.",
+	}, []string{"protocol", "route", "method", "code"})
+	proxyBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "proxy_bytes_total",
+		Help:      "The bytes sent by proxied downloads.",
+	}, []string{"protocol"})
+	listCache = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "list_cache_total",
+		Help:      "The list cache lookups by storage and result (hit or miss).",
+	}, []string{"storage", "result"})
+	driverCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "driver_call_duration_seconds",
+		Help:      "The latency of driver calls.",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"driver", "method"})
+	driverCallErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "driver_call_errors_total",
+		Help:      "The failed driver calls.",
+	}, []string{"driver", "method"})
+
+	// the gauges below are refreshed at scrape time
+
+	Tasks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tasks",
+		Help:      "The tasks in the task managers by state.",
+	}, []string{"manager", "state"})
+	StorageStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "storage_status",
+		Help:      "The current status of the storages, the value is always 1.",
+	}, []string{"storage", "driver", "status"})
+	StorageUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "storage_up",
+		Help:      "Whether the storages are working.",
+	}, []string{"storage", "driver"})
+	IndexObjects = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "search_index_objects",
+		Help:      "The objects indexed by the last or the running index build.",
+	})
+	IndexDone = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "search_index_done",
+		Help:      "Whether the index build is done.",
+	})
+	IndexLastDone = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "search_index_last_done_timestamp_seconds",
+		Help:      "The unix time of the last finished index build.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requests, proxyBytes, listCache, driverCallDuration, driverCallErrors,
+		Tasks, StorageStatus, StorageUp, IndexObjects, IndexDone, IndexLastDone,
+	)
+}
+
+// Handler serves the metrics in the prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+func ObserveRequest(protocol, route, method, code string) {
+	requests.WithLabelValues(protocol, route, method, code).Inc()
+}
+
+func ObserveListCache(storage string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	listCache.WithLabelValues(storage, result).Inc()
+}
+
+// ObserveDriverCall records a call of the driver started at start, err is the result of the call
+func ObserveDriverCall(driver, method string, start time.Time, err error) {
+	driverCallDuration.WithLabelValues(driver, method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		driverCallErrors.WithLabelValues(driver, method).Inc()
+	}
+}
+
+// WithProtocol puts the protocol of a request into ctx, the proxied bytes are counted by it
+func WithProtocol(ctx context.Context, protocol string) context.Context {
+	return context.WithValue(ctx, protocolKey{}, protocol)
+}
+
+func protocolOf(ctx context.Context) string {
+	if protocol, ok := ctx.Value(protocolKey{}).(string); ok {
+		return protocol
+	}
+	return "other"
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	counter prometheus.Counter
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.counter.Add(float64(n))
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// NewProxyWriter counts the bytes written to w as proxied bytes of the protocol in ctx
+func NewProxyWriter(ctx context.Context, w http.ResponseWriter) http.ResponseWriter {
+	return &responseWriter{ResponseWriter: w, counter: proxyBytes.WithLabelValues(protocolOf(ctx))}
+}
+
+type readCloser struct {
+	io.ReadCloser
+	counter prometheus.Counter
+}
+
+func (r *readCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.counter.Add(float64(n))
+	return n, err
+}
+
+// NewProxyReadCloser counts the bytes read from rc as proxied bytes of the protocol in ctx
+func NewProxyReadCloser(ctx context.Context, rc io.ReadCloser) io.ReadCloser {
+	return &readCloser{ReadCloser: rc, counter: proxyBytes.WithLabelValues(protocolOf(ctx))}
+}
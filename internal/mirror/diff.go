@@ -0,0 +1,150 @@
+package mirror
+
+import (
+	"context"
+	stdpath "path"
+	"sort"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	ActionCopy     = "copy"
+	ActionDelete   = "delete"
+	ActionConflict = "conflict"
+)
+
+// Action is one step to make the two trees the same.
+// For copy, Src is copied into the dir Dst, a folder is copied with all its
+// content, and for delete Src is removed.
+type Action struct {
+	Type   string `json:"type"`
+	Src    string `json:"src"`
+	Dst    string `json:"dst"`
+	Reason string `json:"reason"`
+}
+
+// modTimeTolerance ignores the precision lost by some storages
+const modTimeTolerance = 2 * time.Second
+
+// Diff walks the two trees of job and returns the actions to sync them.
+// Two way sync never deletes, since it can't tell a deleted file from a new one.
+func Diff(ctx context.Context, job *model.SyncJob) ([]Action, error) {
+	d := &differ{ctx: ctx, job: job}
+	src, dst := utils.FixAndCleanPath(job.SrcPath), utils.FixAndCleanPath(job.DstPath)
+	if err := d.diff(src, dst); err != nil {
+		return nil, err
+	}
+	return d.actions, nil
+}
+
+type differ struct {
+	ctx     context.Context
+	job     *model.SyncJob
+	actions []Action
+}
+
+func (d *differ) add(typ, src, dst, reason string) {
+	d.actions = append(d.actions, Action{Type: typ, Src: src, Dst: dst, Reason: reason})
+}
+
+func (d *differ) list(path string) (map[string]model.Obj, error) {
+	objs, err := fs.List(d.ctx, path, &fs.ListArgs{Refresh: true, NoLog: true})
+	if err != nil {
+		if errs.IsNotFoundError(err) {
+			return map[string]model.Obj{}, nil
+		}
+		return nil, errors.WithMessagef(err, "failed list %s", path)
+	}
+	res := make(map[string]model.Obj, len(objs))
+	for _, obj := range objs {
+		res[obj.GetName()] = obj
+	}
+	return res, nil
+}
+
+func (d *differ) diff(srcDir, dstDir string) error {
+	if utils.IsCanceled(d.ctx) {
+		return d.ctx.Err()
+	}
+	srcObjs, err := d.list(srcDir)
+	if err != nil {
+		return err
+	}
+	dstObjs, err := d.list(dstDir)
+	if err != nil {
+		return err
+	}
+	twoWay := d.job.Mode == model.SyncTwoWay
+	for _, name := range sortedNames(srcObjs) {
+		srcObj := srcObjs[name]
+		srcPath, dstPath := stdpath.Join(srcDir, name), stdpath.Join(dstDir, name)
+		dstObj, ok := dstObjs[name]
+		if !ok {
+			d.add(ActionCopy, srcPath, dstDir, "not in dst")
+			continue
+		}
+		if srcObj.IsDir() != dstObj.IsDir() {
+			d.add(ActionConflict, srcPath, dstPath, "one is a file and the other is a folder")
+			continue
+		}
+		if srcObj.IsDir() {
+			if err := d.diff(srcPath, dstPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if same(srcObj, dstObj, twoWay) {
+			continue
+		}
+		switch {
+		case !twoWay:
+			d.add(ActionCopy, srcPath, dstDir, "changed in src")
+		case srcObj.ModTime().After(dstObj.ModTime()):
+			d.add(ActionCopy, srcPath, dstDir, "src is newer")
+		case dstObj.ModTime().After(srcObj.ModTime()):
+			d.add(ActionCopy, dstPath, srcDir, "dst is newer")
+		default:
+			d.add(ActionConflict, srcPath, dstPath, "changed on both sides")
+		}
+	}
+	for _, name := range sortedNames(dstObjs) {
+		if _, ok := srcObjs[name]; ok {
+			continue
+		}
+		dstPath := stdpath.Join(dstDir, name)
+		if twoWay {
+			d.add(ActionCopy, dstPath, srcDir, "not in src")
+		} else if d.job.Delete {
+			d.add(ActionDelete, dstPath, "", "not in src")
+		}
+	}
+	return nil
+}
+
+func sortedNames(objs map[string]model.Obj) []string {
+	names := make([]string, 0, len(objs))
+	for name := range objs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// same compares the size and the hash of two files. The modified time is
+// only used by one way sync if there is no hash to compare, two way sync can't
+// use it since an upload usually resets it, and the files would be copied back.
+func same(src, dst model.Obj, twoWay bool) bool {
+	if src.GetSize() != dst.GetSize() {
+		return false
+	}
+	if equal, ok := src.GetHash().Compare(dst.GetHash()); ok {
+		return equal
+	}
+	return twoWay || !src.ModTime().After(dst.ModTime().Add(modTimeTolerance))
+}
@@ -0,0 +1,97 @@
+package mirror
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func mount(t *testing.T, mountPath string, files map[string]string) string {
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if content == "/" {
+			if err := os.MkdirAll(path, 0777); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: mountPath,
+		Addition: `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	return root
+}
+
+func TestDiff(t *testing.T) {
+	// a content of "/" means an empty folder
+	srcRoot := mount(t, "/sync_src", map[string]string{
+		"same.txt":       "same",
+		"edited.txt":     "new",
+		"dir/a.txt":      "a",
+		"empty":          "/",
+		"only_src/b.txt": "b",
+	})
+	dstRoot := mount(t, "/sync_dst", map[string]string{
+		"same.txt":   "same",
+		"edited.txt": "old",
+		"dir/a.txt":  "a",
+		"extra.txt":  "extra",
+	})
+	now := time.Now()
+	for _, root := range []string{srcRoot, dstRoot} {
+		for _, name := range []string{"same.txt", "dir/a.txt"} {
+			_ = os.Chtimes(filepath.Join(root, name), now, now)
+		}
+	}
+	// an edited file of the same size is newer in src
+	_ = os.Chtimes(filepath.Join(dstRoot, "edited.txt"), now.Add(-time.Hour), now.Add(-time.Hour))
+
+	actions, err := Diff(context.Background(), &model.SyncJob{SrcPath: "/sync_src", DstPath: "/sync_dst",
+		Mode: model.SyncOneWay, Delete: true})
+	if err != nil {
+		t.Fatalf("failed diff: %+v", err)
+	}
+	expected := []Action{
+		{Type: ActionCopy, Src: "/sync_src/edited.txt", Dst: "/sync_dst"},
+		{Type: ActionCopy, Src: "/sync_src/empty", Dst: "/sync_dst"},
+		{Type: ActionCopy, Src: "/sync_src/only_src", Dst: "/sync_dst"},
+		{Type: ActionDelete, Src: "/sync_dst/extra.txt"},
+	}
+	if len(actions) != len(expected) {
+		t.Fatalf("expected %d actions, got %+v", len(expected), actions)
+	}
+	for i, a := range actions {
+		if a.Type != expected[i].Type || a.Src != expected[i].Src || a.Dst != expected[i].Dst {
+			t.Errorf("action %d should be %+v, got %+v", i, expected[i], a)
+		}
+	}
+}
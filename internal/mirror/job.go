@@ -0,0 +1,111 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	mu      sync.Mutex
+	crons   = make(map[uint]*cron.Cron)
+	running = make(map[uint]struct{})
+)
+
+// Init schedules all the enabled sync jobs
+func Init() {
+	jobs, err := db.GetEnabledSyncJobs()
+	if err != nil {
+		log.Errorf("failed get enabled sync jobs: %+v", err)
+		return
+	}
+	for i := range jobs {
+		Schedule(jobs[i])
+	}
+}
+
+// Schedule (re)starts the cron of job, a disabled or manual job is only unscheduled
+func Schedule(job model.SyncJob) {
+	Unschedule(job.ID)
+	if job.Disabled || job.Interval <= 0 {
+		return
+	}
+	c := cron.NewCron(time.Duration(job.Interval) * time.Minute)
+	c.Do(func() {
+		j, err := db.GetSyncJobById(job.ID)
+		if err != nil {
+			log.Errorf("failed get sync job [%d]: %+v", job.ID, err)
+			return
+		}
+		if _, err := Run(context.Background(), j, false); err != nil {
+			log.Errorf("failed run sync job [%s]: %+v", j.Name, err)
+		}
+	})
+	mu.Lock()
+	crons[job.ID] = c
+	mu.Unlock()
+}
+
+func Unschedule(id uint) {
+	mu.Lock()
+	defer mu.Unlock()
+	if c, ok := crons[id]; ok {
+		c.Stop()
+		delete(crons, id)
+	}
+}
+
+// Run diffs the trees of job and queues the copy and delete tasks
+// it needs, with dryRun the actions are only returned.
+func Run(ctx context.Context, job *model.SyncJob, dryRun bool) ([]Action, error) {
+	if !conf.StoragesLoaded {
+		return nil, errors.New("storages are not loaded yet")
+	}
+	mu.Lock()
+	if _, ok := running[job.ID]; ok {
+		mu.Unlock()
+		return nil, errors.Errorf("sync job [%s] is running", job.Name)
+	}
+	running[job.ID] = struct{}{}
+	mu.Unlock()
+	defer func() {
+		mu.Lock()
+		delete(running, job.ID)
+		mu.Unlock()
+	}()
+	actions, err := Diff(ctx, job)
+	if err != nil || dryRun {
+		return actions, err
+	}
+	// the copies are queued as tasks too, so a copy in the same storage doesn't run in the caller
+	var copies, deletes, conflicts int
+	for _, a := range actions {
+		switch a.Type {
+		case ActionCopy:
+			copies++
+		case ActionDelete:
+			deletes++
+		case ActionConflict:
+			conflicts++
+			log.Warnf("sync job [%s] conflict: %s <-> %s, %s", job.Name, a.Src, a.Dst, a.Reason)
+			continue
+		}
+		TaskManager.Add(&SyncTask{Job: job.Name, Action: a, Creator: task.CreatorFromCtx(ctx)})
+	}
+	now := time.Now()
+	job.LastRunAt = &now
+	job.LastResult = fmt.Sprintf("queued %d copies and %d deletes, conflicts %d", copies, deletes, conflicts)
+	if err := db.UpdateSyncJob(job); err != nil {
+		log.Errorf("failed update sync job [%s]: %+v", job.Name, err)
+	}
+	return actions, nil
+}
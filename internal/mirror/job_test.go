@@ -0,0 +1,52 @@
+package mirror
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/xhofe/tache"
+)
+
+func TestRunNewFolder(t *testing.T) {
+	conf.StoragesLoaded = true
+	TaskManager = tache.NewManager[*SyncTask](tache.WithWorks(2))
+	fs.CopyTaskManager = tache.NewManager[*fs.CopyTask](tache.WithWorks(2))
+	mount(t, "/run_src", map[string]string{
+		"new/a.txt":     "a",
+		"new/sub/b.txt": "b",
+		"new/empty":     "/",
+	})
+	dstRoot := mount(t, "/run_dst", map[string]string{})
+	actions, err := Run(context.Background(), &model.SyncJob{Name: "run", SrcPath: "/run_src", DstPath: "/run_dst",
+		Mode: model.SyncOneWay}, false)
+	if err != nil {
+		t.Fatalf("failed run: %+v", err)
+	}
+	if len(actions) != 1 || actions[0].Type != ActionCopy || actions[0].Src != "/run_src/new" {
+		t.Fatalf("a new folder should be copied as a whole, got %+v", actions)
+	}
+	expected := map[string]string{"new/a.txt": "a", "new/sub/b.txt": "b", "new/empty": "/"}
+	deadline := time.Now().Add(10 * time.Second)
+	for name, content := range expected {
+		for {
+			path := filepath.Join(dstRoot, name)
+			if content == "/" {
+				if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+					break
+				}
+			} else if data, err := os.ReadFile(path); err == nil && string(data) == content {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s is not copied to dst", name)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+}
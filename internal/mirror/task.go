@@ -0,0 +1,69 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+// SyncTask runs a copy or delete action of a sync job, a copy between
+// two storages is queued as a copy task
+type SyncTask struct {
+	tache.Base
+	task.Creator
+	Status string `json:"status"`
+	Job    string `json:"job"`
+	Action Action `json:"action"`
+}
+
+func (t *SyncTask) GetName() string {
+	if t.Action.Type == ActionCopy {
+		return fmt.Sprintf("sync [%s] copy (%s) to (%s)", t.Job, t.Action.Src, t.Action.Dst)
+	}
+	return fmt.Sprintf("sync [%s] %s (%s)", t.Job, t.Action.Type, t.Action.Src)
+}
+
+func (t *SyncTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *SyncTask) Run() error {
+	switch t.Action.Type {
+	case ActionCopy:
+		t.Status = "copying"
+		// the dst files are changed ones, so they are always overwritten
+		ctx := context.WithValue(t.creatorCtx(), conf.ConflictPolicyKey, fs.ConflictOverwrite)
+		copyTask, err := fs.Copy(ctx, t.Action.Src, t.Action.Dst)
+		if err == nil && copyTask != nil {
+			t.Status = "queued the copy task"
+		}
+		return err
+	case ActionDelete:
+		t.Status = "removing"
+		return fs.Remove(t.creatorCtx(), t.Action.Src)
+	default:
+		return errors.Errorf("unsupported sync action: %s", t.Action.Type)
+	}
+}
+
+// creatorCtx puts the creator into the ctx of the task, so the copy tasks
+// it queues belong to the creator too
+func (t *SyncTask) creatorCtx() context.Context {
+	ctx := t.Ctx()
+	if t.UserID == 0 {
+		return ctx
+	}
+	user, err := op.GetUserById(t.UserID)
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, "user", user)
+}
+
+var TaskManager *tache.Manager[*SyncTask]
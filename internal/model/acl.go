@@ -0,0 +1,31 @@
+package model
+
+const (
+	AclSubjectUser  = "user"
+	AclSubjectGroup = "group"
+)
+
+// the actions of acl rules, determined by bit
+const (
+	AclRead int32 = 1 << iota
+	AclList
+	AclWrite
+	AclDelete
+	AclRename
+	AclOfflineDownload
+)
+
+// AclRule allows or denies the actions on Path and all the sub paths of it.
+// The rule with the longest path wins, so rules of sub paths override the inherited ones.
+type AclRule struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	SubjectType string `json:"subject_type" gorm:"index:idx_acl_subject" binding:"required"` // user or group
+	SubjectID   uint   `json:"subject_id" gorm:"index:idx_acl_subject"`
+	Path        string `json:"path" binding:"required"`
+	Allow       int32  `json:"allow"` // allowed actions
+	Deny        int32  `json:"deny"`  // denied actions, deny wins if both are set
+}
+
+func (r *AclRule) IsUser() bool {
+	return r.SubjectType == AclSubjectUser
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// the protocols an operation can come from
+const (
+	ProtocolWeb    = "web"
+	ProtocolWebDAV = "webdav"
+	ProtocolS3     = "s3"
+)
+
+// the actions of the file operations and logins,
+// the changes of the settings are named like storage.create
+const (
+	AuditMkdir           = "mkdir"
+	AuditRename          = "rename"
+	AuditMove            = "move"
+	AuditCopy            = "copy"
+	AuditRemove          = "remove"
+	AuditPut             = "put"
+	AuditOfflineDownload = "offline_download"
+	AuditExtract         = "extract"
+	AuditCompress        = "compress"
+	AuditLogin           = "login"
+
+	AuditStorageCreate  = "storage.create"
+	AuditStorageUpdate  = "storage.update"
+	AuditStorageDelete  = "storage.delete"
+	AuditStorageEnable  = "storage.enable"
+	AuditStorageDisable = "storage.disable"
+	AuditUserCreate     = "user.create"
+	AuditUserUpdate     = "user.update"
+	AuditUserDelete     = "user.delete"
+	AuditUserCancel2FA  = "user.cancel_2fa"
+	AuditMetaCreate     = "meta.create"
+	AuditMetaUpdate     = "meta.update"
+	AuditMetaDelete     = "meta.delete"
+	AuditSettingSave    = "setting.save"
+	AuditSettingDelete  = "setting.delete"
+	AuditTokenReset     = "setting.reset_token"
+)
+
+// AuditLog records who did an operation, from where and whether it succeeded
+type AuditLog struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	UserID   uint      `json:"user_id"`
+	Username string    `json:"username" gorm:"index"`
+	IP       string    `json:"ip"`
+	Protocol string    `json:"protocol"`
+	Action   string    `json:"action" gorm:"index"`
+	Path     string    `json:"path"`
+	Target   string    `json:"target"` // the dst dir of move and copy, the new name of rename
+	Success  bool      `json:"success"`
+	Error    string    `json:"error"`
+	Created  time.Time `json:"created" gorm:"index"`
+}
@@ -0,0 +1,13 @@
+package model
+
+// Group gives its rights to all the users in it,
+// a user gets the union of the rights of its own and all its groups.
+type Group struct {
+	ID         uint     `json:"id" gorm:"primaryKey"`
+	Name       string   `json:"name" gorm:"unique" binding:"required"`
+	Permission int32    `json:"permission"`                         // the same bits as User.Permission
+	BasePaths  []string `json:"base_paths" gorm:"serializer:json"`  // paths in the base paths of the users they can see, empty for all
+	StorageIds []uint   `json:"storage_ids" gorm:"serializer:json"` // storages the users can see, empty for all
+	QuotaBytes int64    `json:"quota_bytes"`                        // max bytes all the users can upload together, 0 for no limit
+	QuotaFiles int64    `json:"quota_files"`                        // max files all the users can upload together, 0 for no limit
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// StorageHealth is the result of a health check of a storage
+type StorageHealth struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	StorageID uint      `json:"storage_id" gorm:"index"`
+	MountPath string    `json:"mount_path"`
+	Healthy   bool      `json:"healthy"`
+	Latency   int64     `json:"latency"` // milliseconds of the probe
+	Error     string    `json:"error"`
+	Reinit    bool      `json:"reinit"` // whether the storage was initialized again in this check
+	Created   time.Time `json:"created" gorm:"index"`
+}
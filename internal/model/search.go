@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -17,14 +18,40 @@ type SearchReq struct {
 	Keywords string `json:"keywords"`
 	// 0 for all, 1 for dir, 2 for file
 	Scope int `json:"scope"`
+	// extensions without dot, such as mp4
+	Exts []string `json:"exts"`
+	// categories of the nodes, such as conf.VIDEO
+	Categories []int `json:"categories"`
+	// 0 for no limit
+	MinSize int64 `json:"min_size"`
+	MaxSize int64 `json:"max_size"`
+	// nil for no limit
+	ModifiedFrom *time.Time `json:"modified_from"`
+	ModifiedTo   *time.Time `json:"modified_to"`
+	// empty for relevance, name, size or modified
+	OrderBy string `json:"order_by"`
+	// asc or desc, asc by default
+	OrderDirection string `json:"order_direction"`
 	PageReq
 }
 
+const (
+	SearchOrderByRelevance = ""
+	SearchOrderByName      = "name"
+	SearchOrderBySize      = "size"
+	SearchOrderByModified  = "modified"
+)
+
 type SearchNode struct {
-	Parent string `json:"parent" gorm:"index"`
-	Name   string `json:"name"`
-	IsDir  bool   `json:"is_dir"`
-	Size   int64  `json:"size"`
+	Parent   string    `json:"parent" gorm:"index"`
+	Name     string    `json:"name"`
+	IsDir    bool      `json:"is_dir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	// the type of the node when it's indexed, such as conf.VIDEO
+	Category int `json:"category" gorm:"index"`
+	// the plain text of the document, only stored by the searchers indexing the contents
+	Content string `json:"-" gorm:"-"`
 }
 
 func (p *SearchReq) Validate() error {
@@ -34,9 +61,33 @@ func (p *SearchReq) Validate() error {
 	if p.PerPage < 1 {
 		return fmt.Errorf("per_page can't < 1")
 	}
+	if p.MinSize < 0 || p.MaxSize < 0 || (p.MaxSize > 0 && p.MaxSize < p.MinSize) {
+		return fmt.Errorf("invalid size range")
+	}
+	if p.ModifiedFrom != nil && p.ModifiedTo != nil && p.ModifiedTo.Before(*p.ModifiedFrom) {
+		return fmt.Errorf("invalid modified range")
+	}
+	switch p.OrderBy {
+	case SearchOrderByRelevance, SearchOrderByName, SearchOrderBySize, SearchOrderByModified:
+	default:
+		return fmt.Errorf("unsupported order_by: %s", p.OrderBy)
+	}
+	switch p.OrderDirection {
+	case "", "asc", "desc":
+	default:
+		return fmt.Errorf("unsupported order_direction: %s", p.OrderDirection)
+	}
+	for i := range p.Exts {
+		p.Exts[i] = strings.ToLower(strings.TrimPrefix(p.Exts[i], "."))
+	}
 	return nil
 }
 
+// Desc reports whether the results are sorted in descending order
+func (p *SearchReq) Desc() bool {
+	return p.OrderDirection == "desc"
+}
+
 func (s *SearchNode) Type() string {
 	return "SearchNode"
 }
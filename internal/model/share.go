@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Share is a public link to a file or a folder, folders are browsed read-only
+type Share struct {
+	ID           string     `json:"id" gorm:"primaryKey;size:32"` // random short id used in /s/:id
+	UserID       uint       `json:"user_id" gorm:"index"`         // the user who created the share
+	Path         string     `json:"path"`                         // full path of the shared object
+	Password     string     `json:"-"`
+	HasPassword  bool       `json:"has_password" gorm:"-"`
+	Expires      *time.Time `json:"expires"`       // nil for never
+	MaxDownloads int64      `json:"max_downloads"` // 0 for no limit
+	Downloads    int64      `json:"downloads"`
+	Created      time.Time  `json:"created"`
+}
+
+func (s *Share) IsExpired() bool {
+	return s.Expires != nil && time.Now().After(*s.Expires)
+}
+
+func (s *Share) IsExhausted() bool {
+	return s.MaxDownloads > 0 && s.Downloads >= s.MaxDownloads
+}
@@ -14,8 +14,25 @@ type Storage struct {
 	Modified        time.Time `json:"modified"`
 	Disabled        bool      `json:"disabled"` // if disabled
 	EnableSign      bool      `json:"enable_sign"`
+	RecycleBin      bool      `json:"recycle_bin"`     // move removed objects to the recycle bin of the storage
+	BandwidthLimit  int64     `json:"bandwidth_limit"` // transfer rate limit in KB/s, 0 for no limit
 	Sort
 	Proxy
+	Balance
+}
+
+// StorageDetails is the space usage of a storage in bytes
+type StorageDetails struct {
+	TotalSpace int64 `json:"total_space"`
+	UsedSpace  int64 `json:"used_space"`
+	FreeSpace  int64 `json:"free_space"`
+}
+
+// Add sums the space of the storages
+func (d *StorageDetails) Add(other *StorageDetails) {
+	d.TotalSpace += other.TotalSpace
+	d.UsedSpace += other.UsedSpace
+	d.FreeSpace += other.FreeSpace
 }
 
 type Sort struct {
@@ -24,6 +41,20 @@ type Sort struct {
 	ExtractFolder  string `json:"extract_folder"`
 }
 
+// the strategies to choose a storage of the balance group, such as /a, /a.balance and /a.balance1
+const (
+	BalanceRoundRobin       = "round_robin"
+	BalanceWeighted         = "weighted"
+	BalanceLeastConnections = "least_connections"
+	BalanceLowestLatency    = "lowest_latency"
+	BalanceFailover         = "failover"
+)
+
+type Balance struct {
+	BalanceStrategy string `json:"balance_strategy"` // the strategy of the group, set on the storage without the .balance suffix
+	BalanceWeight   int    `json:"balance_weight"`   // the weight in the weighted strategy, 1 if not positive
+}
+
 type Proxy struct {
 	WebProxy     bool   `json:"web_proxy"`
 	WebdavPolicy string `json:"webdav_policy"`
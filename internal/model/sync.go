@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+const (
+	SyncOneWay = "one_way"
+	SyncTwoWay = "two_way"
+)
+
+type SyncJob struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Name    string `json:"name"`
+	SrcPath string `json:"src_path" binding:"required"`
+	DstPath string `json:"dst_path" binding:"required"`
+	// Mode is one_way or two_way
+	Mode string `json:"mode"`
+	// Delete removes the files not in src from dst, only for one_way
+	Delete bool `json:"delete"`
+	// Interval in minutes, 0 means the job only runs manually
+	Interval   int        `json:"interval"`
+	Disabled   bool       `json:"disabled"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+	LastResult string     `json:"last_result"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// TaskItem is the persisted state of a tache task,
+// Data is the json of the task which is used to recover it
+type TaskItem struct {
+	ID        string    `json:"id" gorm:"primaryKey;size:64"`
+	Type      string    `json:"type" gorm:"index;size:64"`
+	UserID    uint      `json:"user_id" gorm:"index"` // the creator of the task
+	Name      string    `json:"name"`
+	State     int       `json:"state" gorm:"index"`
+	Status    string    `json:"status"`
+	Progress  float64   `json:"progress"`
+	Error     string    `json:"error"`
+	Data      string    `json:"-" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// TrashItem is an object moved to the recycle bin of a storage,
+// the paths are actual paths in the storage.
+type TrashItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	StorageID uint      `json:"storage_id" gorm:"index"`
+	MountPath string    `json:"mount_path"` // mount path of the storage when removed
+	Path      string    `json:"path"`       // the original path
+	TrashPath string    `json:"trash_path"` // where the object is in the recycle bin
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	IsDir     bool      `json:"is_dir"`
+	RemovedAt time.Time `json:"removed_at" gorm:"index"`
+}
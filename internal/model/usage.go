@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// UserUsage is what a user has uploaded, it's checked against the quotas
+type UserUsage struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Bytes     int64     `json:"bytes"`
+	Files     int64     `json:"files"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UploadOwner records who uploaded the file at Path, the usage is given back to the owner when it's removed
+type UploadOwner struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	Path   string `json:"path" gorm:"unique"` // the mount path of the file
+	UserID uint   `json:"user_id" gorm:"index"`
+	Size   int64  `json:"size"`
+}
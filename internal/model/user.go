@@ -46,6 +46,15 @@ type User struct {
 	OtpSecret  string `json:"-"`
 	SsoID      string `json:"sso_id"` // unique by sso platform
 	Authn      string `gorm:"type:text" json:"-"`
+	GroupIds   []uint `json:"group_ids" gorm:"serializer:json"`
+	QuotaBytes int64  `json:"quota_bytes"` // max bytes the user can upload, 0 for no limit
+	QuotaFiles int64  `json:"quota_files"` // max files the user can upload, 0 for no limit
+	// transfer rate limit in KB/s, 0 for the default user limit, negative for no limit
+	BandwidthLimit int64 `json:"bandwidth_limit"`
+	// the rights from the groups, filled when the user is loaded, see op.GetUserByName
+	GroupPermission int32    `json:"-" gorm:"-"`
+	Roots           []string `json:"-" gorm:"-"` // the paths in BasePath the user can see, nil for all
+	StorageIds      []uint   `json:"-" gorm:"-"` // the storages the user can see, nil for all
 }
 
 func (u *User) IsGuest() bool {
@@ -77,50 +86,72 @@ func (u *User) SetPassword(pwd string) *User {
 	return u
 }
 
+// GetPermission returns the permission of user and its groups
+func (u *User) GetPermission() int32 {
+	return u.Permission | u.GroupPermission
+}
+
 func (u *User) CanSeeHides() bool {
-	return u.IsAdmin() || u.Permission&1 == 1
+	return u.IsAdmin() || u.GetPermission()&1 == 1
 }
 
 func (u *User) CanAccessWithoutPassword() bool {
-	return u.IsAdmin() || (u.Permission>>1)&1 == 1
+	return u.IsAdmin() || (u.GetPermission()>>1)&1 == 1
 }
 
 func (u *User) CanAddOfflineDownloadTasks() bool {
-	return u.IsAdmin() || (u.Permission>>2)&1 == 1
+	return u.IsAdmin() || (u.GetPermission()>>2)&1 == 1
 }
 
 func (u *User) CanWrite() bool {
-	return u.IsAdmin() || (u.Permission>>3)&1 == 1
+	return u.IsAdmin() || (u.GetPermission()>>3)&1 == 1
 }
 
 func (u *User) CanRename() bool {
-	return u.IsAdmin() || (u.Permission>>4)&1 == 1
+	return u.IsAdmin() || (u.GetPermission()>>4)&1 == 1
 }
 
 func (u *User) CanMove() bool {
-	return u.IsAdmin() || (u.Permission>>5)&1 == 1
+	return u.IsAdmin() || (u.GetPermission()>>5)&1 == 1
 }
 
 func (u *User) CanCopy() bool {
-	return u.IsAdmin() || (u.Permission>>6)&1 == 1
+	return u.IsAdmin() || (u.GetPermission()>>6)&1 == 1
 }
 
 func (u *User) CanRemove() bool {
-	return u.IsAdmin() || (u.Permission>>7)&1 == 1
+	return u.IsAdmin() || (u.GetPermission()>>7)&1 == 1
 }
 
 func (u *User) CanWebdavRead() bool {
-	return u.IsAdmin() || (u.Permission>>8)&1 == 1
+	return u.IsAdmin() || (u.GetPermission()>>8)&1 == 1
 }
 
 func (u *User) CanWebdavManage() bool {
-	return u.IsAdmin() || (u.Permission>>9)&1 == 1
+	return u.IsAdmin() || (u.GetPermission()>>9)&1 == 1
 }
 
 func (u *User) JoinPath(reqPath string) (string, error) {
 	return utils.JoinBasePath(u.BasePath, reqPath)
 }
 
+// InRoots checks whether reqPath is in one of the Roots of user,
+// ancestor is true if reqPath is a parent dir of one of them.
+func (u *User) InRoots(reqPath string) (in bool, ancestor bool) {
+	if u.Roots == nil {
+		return true, false
+	}
+	for _, root := range u.Roots {
+		if utils.IsSubPath(root, reqPath) {
+			return true, false
+		}
+		if utils.IsSubPath(reqPath, root) {
+			ancestor = true
+		}
+	}
+	return false, ancestor
+}
+
 func StaticHash(password string) string {
 	return utils.HashData(utils.SHA256, []byte(fmt.Sprintf("%s-%s", password, StaticHashSalt)))
 }
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// the events sent to the webhooks
+const (
+	EventUploadFinished          = "upload.finished"
+	EventObjRemoved              = "object.removed"
+	EventObjRenamed              = "object.renamed"
+	EventObjMoved                = "object.moved"
+	EventCopySucceeded           = "copy.succeeded"
+	EventCopyFailed              = "copy.failed"
+	EventOfflineDownloadFinished = "offline_download.finished"
+	EventStorageStatus           = "storage.status"
+)
+
+type Webhook struct {
+	ID       uint     `json:"id" gorm:"primaryKey"`
+	Name     string   `json:"name"`
+	URL      string   `json:"url" binding:"required"`
+	Secret   string   `json:"secret"`                        // the key of the hmac-sha256 signature, no signature if empty
+	Events   []string `json:"events" gorm:"serializer:json"` // the events to send, empty for all
+	Paths    []string `json:"paths" gorm:"serializer:json"`  // only the events in the paths are sent, empty for all
+	MaxRetry int      `json:"max_retry"`                     // retries after the first failed delivery
+	Disabled bool     `json:"disabled"`
+}
+
+// WebhookDelivery is the log of sending an event to a webhook
+type WebhookDelivery struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WebhookID  uint      `json:"webhook_id" gorm:"index"`
+	Event      string    `json:"event"`
+	Payload    string    `json:"payload" gorm:"type:text"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"status_code"`
+	Response   string    `json:"response" gorm:"type:text"`
+	Error      string    `json:"error"`
+	Success    bool      `json:"success"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
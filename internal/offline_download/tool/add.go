@@ -2,9 +2,12 @@ package tool
 
 import (
 	"context"
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/xhofe/tache"
@@ -28,6 +31,12 @@ type AddURLArgs struct {
 }
 
 func AddURL(ctx context.Context, args *AddURLArgs) (tache.TaskWithInfo, error) {
+	t, err := addURL(ctx, args)
+	audit.Log(ctx, model.AuditOfflineDownload, args.DstDirPath, args.URL, err)
+	return t, err
+}
+
+func addURL(ctx context.Context, args *AddURLArgs) (tache.TaskWithInfo, error) {
 	// get tool
 	tool, err := Tools.Get(args.Tool)
 	if err != nil {
@@ -69,6 +78,8 @@ func AddURL(ctx context.Context, args *AddURLArgs) (tache.TaskWithInfo, error) {
 		DstDirPath:   args.DstDirPath,
 		TempDir:      tempDir,
 		DeletePolicy: args.DeletePolicy,
+		Toolname:     args.Tool,
+		Creator:      task.CreatorFromCtx(ctx),
 		tool:         tool,
 	}
 	DownloadTaskManager.Add(t)
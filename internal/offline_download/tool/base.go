@@ -47,7 +47,7 @@ type GetFileser interface {
 
 type File struct {
 	// ReadCloser for http client
-	ReadCloser io.ReadCloser
+	ReadCloser io.ReadCloser `json:"-"`
 	Name       string
 	Size       int64
 	Path       string
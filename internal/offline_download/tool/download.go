@@ -7,6 +7,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/xhofe/tache"
@@ -14,10 +15,12 @@ import (
 
 type DownloadTask struct {
 	tache.Base
+	task.Creator
 	Url          string       `json:"url"`
 	DstDirPath   string       `json:"dst_dir_path"`
 	TempDir      string       `json:"temp_dir"`
 	DeletePolicy DeletePolicy `json:"delete_policy"`
+	Toolname     string       `json:"tool"`
 
 	Status            string   `json:"status"`
 	Signal            chan int `json:"-"`
@@ -26,7 +29,25 @@ type DownloadTask struct {
 	callStatusRetried int
 }
 
+// Recoverable the task is added again from the start with the same tool after restart
+func (t *DownloadTask) Recoverable() bool {
+	if t.tool != nil {
+		return true
+	}
+	tool, err := Tools.Get(t.Toolname)
+	if err != nil {
+		return false
+	}
+	t.tool = tool
+	return true
+}
+
 func (t *DownloadTask) Run() error {
+	if !t.tool.IsReady() {
+		if _, err := t.tool.Init(); err != nil {
+			return errors.Wrapf(err, "failed init tool %s", t.Toolname)
+		}
+	}
 	if err := t.tool.Run(t); !errs.IsNotSupportError(err) {
 		if err == nil {
 			return t.Complete()
@@ -135,10 +156,11 @@ func (t *DownloadTask) Complete() error {
 	for i, _ := range files {
 		file := files[i]
 		TransferTaskManager.Add(&TransferTask{
-			file:         file,
-			dstDirPath:   t.DstDirPath,
-			tempDir:      t.TempDir,
-			deletePolicy: t.DeletePolicy,
+			File:         file,
+			DstDirPath:   t.DstDirPath,
+			TempDir:      t.TempDir,
+			DeletePolicy: t.DeletePolicy,
+			Creator:      t.Creator,
 		})
 	}
 	return nil
@@ -5,55 +5,76 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/xhofe/tache"
 	"os"
+	stdpath "path"
 	"path/filepath"
 )
 
 type TransferTask struct {
 	tache.Base
-	file         File
-	dstDirPath   string
-	tempDir      string
-	deletePolicy DeletePolicy
+	task.Creator
+	File         File         `json:"file"`
+	DstDirPath   string       `json:"dst_dir_path"`
+	TempDir      string       `json:"temp_dir"`
+	DeletePolicy DeletePolicy `json:"delete_policy"`
+}
+
+// Recoverable only the downloaded local file can be transferred again after restart
+func (t *TransferTask) Recoverable() bool {
+	return t.File.ReadCloser == nil && utils.Exists(t.File.Path)
 }
 
 func (t *TransferTask) Run() error {
 	// check dstDir again
-	storage, dstDirActualPath, err := op.GetStorageAndActualPath(t.dstDirPath)
+	storage, dstDirActualPath, err := op.GetStorageAndActualPath(t.DstDirPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	mimetype := utils.GetMimeType(t.file.Path)
-	rc, err := t.file.GetReadCloser()
+	if t.UserID != 0 {
+		user, err := op.GetUserById(t.UserID)
+		if err != nil {
+			return errors.WithMessage(err, "failed get user")
+		}
+		if err := op.CheckQuota(user, t.File.Size, 1); err != nil {
+			return err
+		}
+	}
+	mimetype := utils.GetMimeType(t.File.Path)
+	rc, err := t.File.GetReadCloser()
 	if err != nil {
-		return errors.Wrapf(err, "failed to open file %s", t.file.Path)
+		return errors.Wrapf(err, "failed to open file %s", t.File.Path)
 	}
 	s := &stream.FileStream{
 		Ctx: nil,
 		Obj: &model.Object{
-			Name:     filepath.Base(t.file.Path),
-			Size:     t.file.Size,
-			Modified: t.file.Modified,
+			Name:     filepath.Base(t.File.Path),
+			Size:     t.File.Size,
+			Modified: t.File.Modified,
 			IsFolder: false,
 		},
 		Reader:   rc,
 		Mimetype: mimetype,
 		Closers:  utils.NewClosers(rc),
 	}
-	relDir, err := filepath.Rel(t.tempDir, filepath.Dir(t.file.Path))
+	relDir, err := filepath.Rel(t.TempDir, filepath.Dir(t.File.Path))
 	if err != nil {
 		log.Errorf("find relation directory error: %v", err)
 	}
 	newDistDir := filepath.Join(dstDirActualPath, relDir)
-	return op.Put(t.Ctx(), storage, newDistDir, s, t.SetProgress)
+	if err := op.Put(t.Ctx(), storage, newDistDir, s, t.SetProgress); err != nil {
+		return err
+	}
+	op.AddUpload(t.UserID, stdpath.Join(storage.GetStorage().MountPath, filepath.ToSlash(newDistDir), s.GetName()), t.File.Size)
+	return nil
 }
 
 func (t *TransferTask) GetName() string {
-	return fmt.Sprintf("transfer %s to [%s]", t.file.Path, t.dstDirPath)
+	return fmt.Sprintf("transfer %s to [%s]", t.File.Path, t.DstDirPath)
 }
 
 func (t *TransferTask) GetStatus() string {
@@ -61,19 +82,23 @@ func (t *TransferTask) GetStatus() string {
 }
 
 func (t *TransferTask) OnSucceeded() {
-	if t.deletePolicy == DeleteOnUploadSucceed || t.deletePolicy == DeleteAlways {
-		err := os.Remove(t.file.Path)
+	op.HandleEventHook(model.EventOfflineDownloadFinished, map[string]interface{}{
+		"path": stdpath.Join(t.DstDirPath, t.File.Name),
+		"size": t.File.Size,
+	})
+	if t.DeletePolicy == DeleteOnUploadSucceed || t.DeletePolicy == DeleteAlways {
+		err := os.Remove(t.File.Path)
 		if err != nil {
-			log.Errorf("failed to delete file %s, error: %s", t.file.Path, err.Error())
+			log.Errorf("failed to delete file %s, error: %s", t.File.Path, err.Error())
 		}
 	}
 }
 
 func (t *TransferTask) OnFailed() {
-	if t.deletePolicy == DeleteOnUploadFailed || t.deletePolicy == DeleteAlways {
-		err := os.Remove(t.file.Path)
+	if t.DeletePolicy == DeleteOnUploadFailed || t.DeletePolicy == DeleteAlways {
+		err := os.Remove(t.File.Path)
 		if err != nil {
-			log.Errorf("failed to delete file %s, error: %s", t.file.Path, err.Error())
+			log.Errorf("failed to delete file %s, error: %s", t.File.Path, err.Error())
 		}
 	}
 }
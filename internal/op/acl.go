@@ -0,0 +1,86 @@
+package op
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+var aclCache = cache.NewMemCache(cache.WithShards[[]model.AclRule](2))
+var aclG singleflight.Group[[]model.AclRule]
+
+func aclKey(subjectType string, subjectId uint) string {
+	return fmt.Sprintf("%s:%d", subjectType, subjectId)
+}
+
+func getAclRulesBySubject(subjectType string, subjectId uint) ([]model.AclRule, error) {
+	key := aclKey(subjectType, subjectId)
+	if rules, ok := aclCache.Get(key); ok {
+		return rules, nil
+	}
+	rules, err, _ := aclG.Do(key, func() ([]model.AclRule, error) {
+		_rules, err := db.GetAclRulesBySubject(subjectType, subjectId)
+		if err != nil {
+			return nil, err
+		}
+		aclCache.Set(key, _rules, cache.WithEx[[]model.AclRule](time.Hour))
+		return _rules, nil
+	})
+	return rules, err
+}
+
+// GetUserAclRules returns the acl rules applied to user, including the rules of its groups
+func GetUserAclRules(user *model.User) ([]model.AclRule, error) {
+	rules, err := getAclRulesBySubject(model.AclSubjectUser, user.ID)
+	if err != nil || len(user.GroupIds) == 0 {
+		return rules, err
+	}
+	res := append([]model.AclRule{}, rules...)
+	for _, id := range user.GroupIds {
+		groupRules, err := getAclRulesBySubject(model.AclSubjectGroup, id)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, groupRules...)
+	}
+	return res, nil
+}
+
+func GetAclRuleById(id uint) (*model.AclRule, error) {
+	return db.GetAclRuleById(id)
+}
+
+func GetAclRules(subjectType string, subjectId uint, pageIndex, pageSize int) ([]model.AclRule, int64, error) {
+	return db.GetAclRules(subjectType, subjectId, pageIndex, pageSize)
+}
+
+func CreateAclRule(r *model.AclRule) error {
+	r.Path = utils.FixAndCleanPath(r.Path)
+	aclCache.Del(aclKey(r.SubjectType, r.SubjectID))
+	return db.CreateAclRule(r)
+}
+
+func UpdateAclRule(r *model.AclRule) error {
+	r.Path = utils.FixAndCleanPath(r.Path)
+	old, err := db.GetAclRuleById(r.ID)
+	if err != nil {
+		return err
+	}
+	aclCache.Del(aclKey(old.SubjectType, old.SubjectID))
+	aclCache.Del(aclKey(r.SubjectType, r.SubjectID))
+	return db.UpdateAclRule(r)
+}
+
+func DeleteAclRuleById(id uint) error {
+	old, err := db.GetAclRuleById(id)
+	if err != nil {
+		return err
+	}
+	aclCache.Del(aclKey(old.SubjectType, old.SubjectID))
+	return db.DeleteAclRuleById(id)
+}
@@ -0,0 +1,224 @@
+package op
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+var (
+	// activeMap is the number of running driver calls and transfers of each storage
+	activeMap generic_sync.MapOf[string, *int64]
+
+	latencyMu sync.Mutex
+	// latencyMap is the moving average of the driver call latency of each storage
+	latencyMap = make(map[string]time.Duration)
+
+	weightMu sync.Mutex
+	// weightMap is the current weights of the smooth weighted round-robin of each balance group
+	weightMap = make(map[string]map[string]int)
+)
+
+// Acquire counts a running call or transfer of the storage for the least connections strategy,
+// the returned function must be called when it's done
+func Acquire(storage driver.Driver) (release func()) {
+	n, _ := activeMap.LoadOrStore(storage.GetStorage().MountPath, new(int64))
+	atomic.AddInt64(n, 1)
+	return func() {
+		atomic.AddInt64(n, -1)
+	}
+}
+
+func activeCount(storage driver.Driver) int64 {
+	if n, ok := activeMap.Load(storage.GetStorage().MountPath); ok {
+		return atomic.LoadInt64(n)
+	}
+	return 0
+}
+
+// RecordLatency adds a sample to the latency of the storage for the lowest latency strategy
+func RecordLatency(storage driver.Driver, d time.Duration) {
+	mountPath := storage.GetStorage().MountPath
+	latencyMu.Lock()
+	defer latencyMu.Unlock()
+	if old, ok := latencyMap[mountPath]; ok {
+		d = (old*4 + d) / 5
+	}
+	latencyMap[mountPath] = d
+}
+
+func getLatency(storage driver.Driver) time.Duration {
+	latencyMu.Lock()
+	defer latencyMu.Unlock()
+	return latencyMap[storage.GetStorage().MountPath]
+}
+
+// startCall is called before a driver call, the returned function records the call with its result
+func startCall(storage driver.Driver, method string) func(err error) {
+	release := Acquire(storage)
+	start := time.Now()
+	return func(err error) {
+		release()
+		metrics.ObserveDriverCall(storage.Config().Name, method, start, err)
+		if err == nil {
+			RecordLatency(storage, time.Since(start))
+		}
+	}
+}
+
+// balance chooses a storage from the balance group with the strategy of the group
+func balance(storages []driver.Driver) driver.Driver {
+	virtualPath := utils.GetActualMountPath(storages[0].GetStorage().MountPath)
+	strategy := balanceStrategy(virtualPath, storages)
+	storages = skipUnhealthy(storages)
+	if len(storages) == 1 {
+		return storages[0]
+	}
+	switch strategy {
+	case model.BalanceWeighted:
+		return weighted(virtualPath, storages)
+	case model.BalanceLeastConnections:
+		return leastConnections(virtualPath, storages)
+	case model.BalanceLowestLatency:
+		return lowestLatency(storages)
+	case model.BalanceFailover:
+		return failover(storages)
+	default:
+		return roundRobin(virtualPath, storages)
+	}
+}
+
+// balanceStrategy returns the strategy of the main storage, or the first one set in the group
+func balanceStrategy(virtualPath string, storages []driver.Driver) string {
+	strategy := ""
+	for _, s := range storages {
+		if s.GetStorage().MountPath == virtualPath {
+			return s.GetStorage().BalanceStrategy
+		}
+		if strategy == "" {
+			strategy = s.GetStorage().BalanceStrategy
+		}
+	}
+	return strategy
+}
+
+func roundRobin(virtualPath string, storages []driver.Driver) driver.Driver {
+	return storages[nextIndex(virtualPath, len(storages))]
+}
+
+func nextIndex(virtualPath string, n int) int {
+	i, _ := balanceMap.LoadOrStore(virtualPath, 0)
+	i = (i + 1) % n
+	balanceMap.Store(virtualPath, i)
+	return i
+}
+
+// weighted is the smooth weighted round-robin of nginx
+func weighted(virtualPath string, storages []driver.Driver) driver.Driver {
+	weightMu.Lock()
+	defer weightMu.Unlock()
+	current, ok := weightMap[virtualPath]
+	if !ok {
+		current = make(map[string]int)
+		weightMap[virtualPath] = current
+	}
+	var best driver.Driver
+	total := 0
+	for _, s := range storages {
+		weight := s.GetStorage().BalanceWeight
+		if weight <= 0 {
+			weight = 1
+		}
+		total += weight
+		mountPath := s.GetStorage().MountPath
+		current[mountPath] += weight
+		if best == nil || current[mountPath] > current[best.GetStorage().MountPath] {
+			best = s
+		}
+	}
+	current[best.GetStorage().MountPath] -= total
+	return best
+}
+
+// leastConnections chooses the storage with the fewest running calls, the ties are rotated
+func leastConnections(virtualPath string, storages []driver.Driver) driver.Driver {
+	start := nextIndex(virtualPath, len(storages))
+	var best driver.Driver
+	var bestCount int64
+	for i := range storages {
+		s := storages[(start+i)%len(storages)]
+		if count := activeCount(s); best == nil || count < bestCount {
+			best, bestCount = s, count
+		}
+	}
+	return best
+}
+
+// lowestLatency chooses the fastest storage, the ones without samples are tried first
+func lowestLatency(storages []driver.Driver) driver.Driver {
+	best := storages[0]
+	for _, s := range storages[1:] {
+		if getLatency(s) < getLatency(best) {
+			best = s
+		}
+	}
+	return best
+}
+
+// failover always chooses the first working storage by order
+func failover(storages []driver.Driver) driver.Driver {
+	sorted := make([]driver.Driver, len(storages))
+	copy(sorted, storages)
+	sortByOrder(sorted)
+	return sorted[0]
+}
+
+func sortByOrder(storages []driver.Driver) {
+	sort.SliceStable(storages, func(i, j int) bool {
+		if storages[i].GetStorage().Order == storages[j].GetStorage().Order {
+			return storages[i].GetStorage().MountPath < storages[j].GetStorage().MountPath
+		}
+		return storages[i].GetStorage().Order < storages[j].GetStorage().Order
+	})
+}
+
+// skipUnhealthy returns the working storages of the balanced ones, or all of them if none is working
+func skipUnhealthy(storages []driver.Driver) []driver.Driver {
+	working := make([]driver.Driver, 0, len(storages))
+	for _, s := range storages {
+		if s.GetStorage().Status == WORK {
+			working = append(working, s)
+		}
+	}
+	if len(working) == 0 {
+		return storages
+	}
+	return working
+}
+
+// GetBalanceFallbacks returns the other storages of the balance group of storage to fail over to,
+// the working ones are in front
+func GetBalanceFallbacks(storage driver.Driver) []driver.Driver {
+	virtualPath := utils.GetActualMountPath(storage.GetStorage().MountPath)
+	var working, others []driver.Driver
+	for _, s := range getStoragesByPath(virtualPath) {
+		if s == storage || utils.GetActualMountPath(s.GetStorage().MountPath) != virtualPath {
+			continue
+		}
+		if s.GetStorage().Status == WORK {
+			working = append(working, s)
+		} else {
+			others = append(others, s)
+		}
+	}
+	sortByOrder(working)
+	sortByOrder(others)
+	return append(working, others...)
+}
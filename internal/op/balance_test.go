@@ -0,0 +1,58 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// readOnlyDriver only gets and lists the objs, the paths in /new are not found
+type readOnlyDriver struct {
+	driver.Driver
+	storage model.Storage
+}
+
+func (d *readOnlyDriver) Config() driver.Config {
+	return driver.Config{Name: "ReadOnly"}
+}
+
+func (d *readOnlyDriver) GetStorage() *model.Storage {
+	return &d.storage
+}
+
+func (d *readOnlyDriver) Get(ctx context.Context, path string) (model.Obj, error) {
+	if strings.HasPrefix(path, "/new") {
+		return nil, errors.WithStack(errs.ObjectNotFound)
+	}
+	return &model.Object{Name: stdpath.Base(path), Path: path, IsFolder: true}, nil
+}
+
+func (d *readOnlyDriver) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	return nil, nil
+}
+
+func TestNotImplementReleased(t *testing.T) {
+	ctx := context.Background()
+	d := &readOnlyDriver{storage: model.Storage{MountPath: "/read_only"}}
+	calls := map[string]func() error{
+		"mkdir":  func() error { return MakeDir(ctx, d, "/new") },
+		"move":   func() error { return Move(ctx, d, "/a", "/b") },
+		"rename": func() error { return Rename(ctx, d, "/a", "c") },
+		"copy":   func() error { return Copy(ctx, d, "/a", "/b") },
+		"remove": func() error { return Remove(ctx, d, "/a") },
+	}
+	for name, call := range calls {
+		if err := call(); !errs.IsNotImplement(err) {
+			t.Errorf("%s should not be implemented, got %v", name, err)
+		}
+		if n := activeCount(d); n != 0 {
+			t.Errorf("the call of %s should be released, got %d active calls", name, n)
+		}
+	}
+}
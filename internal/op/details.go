@@ -0,0 +1,76 @@
+package op
+
+import (
+	"context"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// the space usage changes slowly and most of the drivers get it with an extra api request
+const detailsCacheExpiration = 5 * time.Minute
+
+var detailsCache = cache.NewMemCache(cache.WithShards[*model.StorageDetails](4))
+var detailsG singleflight.Group[*model.StorageDetails]
+
+// GetStorageDetails returns the space usage of the storage,
+// errs.NotImplement is returned if the driver can't tell it
+func GetStorageDetails(ctx context.Context, storage driver.Driver) (*model.StorageDetails, error) {
+	release, err := acquireStorage(storage)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	d, ok := storage.(driver.WithDetails)
+	if !ok {
+		return nil, errors.WithStack(errs.NotImplement)
+	}
+	key := storage.GetStorage().MountPath
+	if details, ok := detailsCache.Get(key); ok {
+		return details, nil
+	}
+	details, err, _ := detailsG.Do(key, func() (*model.StorageDetails, error) {
+		details, err := d.GetDetails(ctx)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed get storage details")
+		}
+		detailsCache.Set(key, details, cache.WithEx[*model.StorageDetails](detailsCacheExpiration))
+		return details, nil
+	})
+	return details, err
+}
+
+// GetDetailsByPath returns the space usage of the storage at path,
+// it's the sum of the storages mounted under path if path is a virtual folder
+func GetDetailsByPath(ctx context.Context, path string) (*model.StorageDetails, error) {
+	path = utils.FixAndCleanPath(path)
+	if storage := GetBalancedStorage(path); storage != nil {
+		return GetStorageDetails(ctx, storage)
+	}
+	var sum *model.StorageDetails
+	for _, storage := range GetAllStorages() {
+		mountPath := storage.GetStorage().MountPath
+		// the storages of a balance group share the same space
+		if utils.GetActualMountPath(mountPath) != mountPath || !utils.IsSubPath(path, mountPath) {
+			continue
+		}
+		details, err := GetStorageDetails(ctx, storage)
+		if err != nil {
+			continue
+		}
+		if sum == nil {
+			sum = &model.StorageDetails{}
+		}
+		sum.Add(details)
+	}
+	if sum == nil {
+		return nil, errors.WithStack(errs.NotImplement)
+	}
+	return sum, nil
+}
@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/generic_sync"
 	"github.com/alist-org/alist/v3/pkg/singleflight"
@@ -101,14 +103,18 @@ func Key(storage driver.Driver, path string) string {
 
 // List files in storage, not contains virtual file
 func List(ctx context.Context, storage driver.Driver, path string, args model.ListArgs, refresh ...bool) ([]model.Obj, error) {
-	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
-		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	release, err := acquireStorage(storage)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 	path = utils.FixAndCleanPath(path)
 	log.Debugf("op.List %s", path)
 	key := Key(storage, path)
 	if !utils.IsBool(refresh...) {
-		if files, ok := listCache.Get(key); ok {
+		files, ok := listCache.Get(key)
+		metrics.ObserveListCache(storage.GetStorage().MountPath, ok)
+		if ok {
 			log.Debugf("use cache when list %s", path)
 			return files, nil
 		}
@@ -122,7 +128,9 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return nil, errors.WithStack(errs.NotFolder)
 	}
 	objs, err, _ := listG.Do(key, func() ([]model.Obj, error) {
+		done := startCall(storage, "list")
 		files, err := storage.List(ctx, dir, args)
+		done(err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to list objs")
 		}
@@ -168,7 +176,9 @@ func Get(ctx context.Context, storage driver.Driver, path string) (model.Obj, er
 
 	// get the obj directly without list so that we can reduce the io
 	if g, ok := storage.(driver.Getter); ok {
+		done := startCall(storage, "get")
 		obj, err := g.Get(ctx, path)
+		done(err)
 		if err == nil {
 			return model.WrapObjName(obj), nil
 		}
@@ -178,7 +188,9 @@ func Get(ctx context.Context, storage driver.Driver, path string) (model.Obj, er
 	if utils.PathEqual(path, "/") {
 		var rootObj model.Obj
 		if getRooter, ok := storage.(driver.GetRooter); ok {
+			done := startCall(storage, "get_root")
 			obj, err := getRooter.GetRoot(ctx)
+			done(err)
 			if err != nil {
 				return nil, errors.WithMessage(err, "failed get root obj")
 			}
@@ -242,9 +254,11 @@ var linkG singleflight.Group[*model.Link]
 
 // Link get link, if is an url. should have an expiry time
 func Link(ctx context.Context, storage driver.Driver, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
-	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
-		return nil, nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	release, err := acquireStorage(storage)
+	if err != nil {
+		return nil, nil, err
 	}
+	defer release()
 	file, err := GetUnwrap(ctx, storage, path)
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "failed to get file")
@@ -257,7 +271,9 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return link, file, nil
 	}
 	fn := func() (*model.Link, error) {
+		done := startCall(storage, "link")
 		link, err := storage.Link(ctx, file, args)
+		done(err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed get link")
 		}
@@ -293,12 +309,14 @@ func Other(ctx context.Context, storage driver.Driver, args model.FsOtherArgs) (
 var mkdirG singleflight.Group[interface{}]
 
 func MakeDir(ctx context.Context, storage driver.Driver, path string, lazyCache ...bool) error {
-	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
-		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	release, err := acquireStorage(storage)
+	if err != nil {
+		return err
 	}
+	defer release()
 	path = utils.FixAndCleanPath(path)
 	key := Key(storage, path)
-	_, err, _ := mkdirG.Do(key, func() (interface{}, error) {
+	_, err, _ = mkdirG.Do(key, func() (interface{}, error) {
 		// check if dir exists
 		f, err := GetUnwrap(ctx, storage, path)
 		if err != nil {
@@ -314,9 +332,10 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string, lazyCache
 					return nil, errors.WithMessagef(err, "failed to get parent dir [%s]", parentPath)
 				}
 
+				done := startCall(storage, "mkdir")
+				var newObj model.Obj
 				switch s := storage.(type) {
 				case driver.MkdirResult:
-					var newObj model.Obj
 					newObj, err = s.MakeDir(ctx, parentDir, dirName)
 					if err == nil {
 						if newObj != nil {
@@ -331,8 +350,13 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string, lazyCache
 						ClearCache(storage, parentPath)
 					}
 				default:
+					done(errs.NotImplement)
 					return nil, errs.NotImplement
 				}
+				done(err)
+				if err == nil {
+					callObjChangeHooks(ObjChangeAdd, storage, path, "", newObj)
+				}
 				return nil, errors.WithStack(err)
 			}
 			return nil, errors.WithMessage(err, "failed to check if dir exists")
@@ -348,9 +372,11 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string, lazyCache
 }
 
 func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string, lazyCache ...bool) error {
-	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
-		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	release, err := acquireStorage(storage)
+	if err != nil {
+		return err
 	}
+	defer release()
 	srcPath = utils.FixAndCleanPath(srcPath)
 	dstDirPath = utils.FixAndCleanPath(dstDirPath)
 	srcRawObj, err := Get(ctx, storage, srcPath)
@@ -364,9 +390,10 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	}
 	srcDirPath := stdpath.Dir(srcPath)
 
+	done := startCall(storage, "move")
+	var newObj model.Obj
 	switch s := storage.(type) {
 	case driver.MoveResult:
-		var newObj model.Obj
 		newObj, err = s.Move(ctx, srcObj, dstDir)
 		if err == nil {
 			delCacheObj(storage, srcDirPath, srcRawObj)
@@ -385,15 +412,22 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 			}
 		}
 	default:
+		done(errs.NotImplement)
 		return errs.NotImplement
 	}
+	done(err)
+	if err == nil {
+		callObjChangeHooks(ObjChangeMove, storage, stdpath.Join(dstDirPath, srcRawObj.GetName()), srcPath, newObj)
+	}
 	return errors.WithStack(err)
 }
 
 func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string, lazyCache ...bool) error {
-	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
-		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	release, err := acquireStorage(storage)
+	if err != nil {
+		return err
 	}
+	defer release()
 	srcPath = utils.FixAndCleanPath(srcPath)
 	srcRawObj, err := Get(ctx, storage, srcPath)
 	if err != nil {
@@ -402,9 +436,10 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 	srcObj := model.UnwrapObj(srcRawObj)
 	srcDirPath := stdpath.Dir(srcPath)
 
+	done := startCall(storage, "rename")
+	var newObj model.Obj
 	switch s := storage.(type) {
 	case driver.RenameResult:
-		var newObj model.Obj
 		newObj, err = s.Rename(ctx, srcObj, dstName)
 		if err == nil {
 			if newObj != nil {
@@ -419,16 +454,23 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 			ClearCache(storage, srcDirPath)
 		}
 	default:
+		done(errs.NotImplement)
 		return errs.NotImplement
 	}
+	done(err)
+	if err == nil {
+		callObjChangeHooks(ObjChangeMove, storage, stdpath.Join(srcDirPath, dstName), srcPath, newObj)
+	}
 	return errors.WithStack(err)
 }
 
 // Copy Just copy file[s] in a storage
 func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string, lazyCache ...bool) error {
-	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
-		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	release, err := acquireStorage(storage)
+	if err != nil {
+		return err
 	}
+	defer release()
 	srcPath = utils.FixAndCleanPath(srcPath)
 	dstDirPath = utils.FixAndCleanPath(dstDirPath)
 	srcObj, err := GetUnwrap(ctx, storage, srcPath)
@@ -440,9 +482,10 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 		return errors.WithMessage(err, "failed to get dst dir")
 	}
 
+	done := startCall(storage, "copy")
+	var newObj model.Obj
 	switch s := storage.(type) {
 	case driver.CopyResult:
-		var newObj model.Obj
 		newObj, err = s.Copy(ctx, srcObj, dstDir)
 		if err == nil {
 			if newObj != nil {
@@ -457,15 +500,22 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 			ClearCache(storage, dstDirPath)
 		}
 	default:
+		done(errs.NotImplement)
 		return errs.NotImplement
 	}
+	done(err)
+	if err == nil {
+		callObjChangeHooks(ObjChangeAdd, storage, stdpath.Join(dstDirPath, stdpath.Base(srcPath)), "", newObj)
+	}
 	return errors.WithStack(err)
 }
 
 func Remove(ctx context.Context, storage driver.Driver, path string) error {
-	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
-		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	release, err := acquireStorage(storage)
+	if err != nil {
+		return err
 	}
+	defer release()
 	path = utils.FixAndCleanPath(path)
 	rawObj, err := Get(ctx, storage, path)
 	if err != nil {
@@ -478,6 +528,7 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 	}
 	dirPath := stdpath.Dir(path)
 
+	done := startCall(storage, "remove")
 	switch s := storage.(type) {
 	case driver.Remove:
 		err = s.Remove(ctx, model.UnwrapObj(rawObj))
@@ -489,15 +540,37 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 			}
 		}
 	default:
+		done(errs.NotImplement)
 		return errs.NotImplement
 	}
+	done(err)
+	if err == nil {
+		callObjChangeHooks(ObjChangeRemove, storage, path, "", nil)
+	}
 	return errors.WithStack(err)
 }
 
+// uploadedSize is the size of the interrupted upload of file to continue from,
+// it's only used if the put is asked to resume with conf.ResumeUploadKey
+func uploadedSize(ctx context.Context, storage driver.Driver, parentDir model.Obj, file model.FileStreamer) int64 {
+	r, ok := storage.(driver.PutResume)
+	if !ok || ctx.Value(conf.ResumeUploadKey) == nil {
+		return 0
+	}
+	offset, err := r.Uploaded(ctx, parentDir, file)
+	if err != nil {
+		log.Warnf("failed get the uploaded size of [%s], upload it from the start: %+v", file.GetName(), err)
+		return 0
+	}
+	return offset
+}
+
 func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file model.FileStreamer, up driver.UpdateProgress, lazyCache ...bool) error {
-	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
-		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	release, err := acquireStorage(storage)
+	if err != nil {
+		return err
 	}
+	defer release()
 	defer func() {
 		if err := file.Close(); err != nil {
 			log.Errorf("failed to close file streamer, %v", err)
@@ -539,26 +612,40 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 		up = func(p float64) {}
 	}
 
-	switch s := storage.(type) {
-	case driver.PutResult:
-		var newObj model.Obj
-		newObj, err = s.Put(ctx, parentDir, file, up)
-		if err == nil {
-			if newObj != nil {
-				addCacheObj(storage, dstDirPath, model.WrapObjName(newObj))
-			} else if !utils.IsBool(lazyCache...) {
-				ClearCache(storage, dstDirPath)
-			}
-		}
-	case driver.Put:
-		err = s.Put(ctx, parentDir, file, up)
+	done := startCall(storage, "put")
+	var newObj model.Obj
+	if offset := uploadedSize(ctx, storage, parentDir, file); offset > 0 {
+		log.Infof("resume uploading [%s] from %d", dstPath, offset)
+		err = storage.(driver.PutResume).PutResume(ctx, parentDir, file, offset, up)
 		if err == nil && !utils.IsBool(lazyCache...) {
 			ClearCache(storage, dstDirPath)
 		}
-	default:
-		return errs.NotImplement
+	} else {
+		switch s := storage.(type) {
+		case driver.PutResult:
+			newObj, err = s.Put(ctx, parentDir, file, up)
+			if err == nil {
+				if newObj != nil {
+					addCacheObj(storage, dstDirPath, model.WrapObjName(newObj))
+				} else if !utils.IsBool(lazyCache...) {
+					ClearCache(storage, dstDirPath)
+				}
+			}
+		case driver.Put:
+			err = s.Put(ctx, parentDir, file, up)
+			if err == nil && !utils.IsBool(lazyCache...) {
+				ClearCache(storage, dstDirPath)
+			}
+		default:
+			done(errs.NotImplement)
+			return errs.NotImplement
+		}
 	}
+	done(err)
 	log.Debugf("put file [%s] done", file.GetName())
+	if err == nil {
+		callObjChangeHooks(ObjChangeAdd, storage, dstPath, "", newObj)
+	}
 	if storage.Config().NoOverwriteUpload && fi != nil && fi.GetSize() > 0 {
 		if err != nil {
 			// upload failed, recover old obj
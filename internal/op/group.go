@@ -0,0 +1,114 @@
+package op
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// applyGroups fills the rights user gets from its groups
+func applyGroups(u *model.User) error {
+	u.GroupPermission, u.Roots, u.StorageIds = 0, nil, nil
+	groups, err := db.GetGroupsByIds(u.GroupIds)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get groups of user [%s]", u.Username)
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	// the request paths are still joined to BasePath, the base paths of the groups only limit what's seen in it
+	base := utils.FixAndCleanPath(u.BasePath)
+	var roots []string
+	var storageIds []uint
+	allRoots, allStorages := false, false
+	for _, g := range groups {
+		u.GroupPermission |= g.Permission
+		if len(g.BasePaths) == 0 {
+			allRoots = true
+		}
+		for _, p := range g.BasePaths {
+			p = utils.FixAndCleanPath(p)
+			if utils.IsSubPath(p, base) {
+				allRoots = true
+			} else if utils.IsSubPath(base, p) {
+				roots = append(roots, p)
+			}
+			// the paths out of BasePath can't be reached
+		}
+		if len(g.StorageIds) == 0 {
+			allStorages = true
+		}
+		storageIds = append(storageIds, g.StorageIds...)
+	}
+	if !allRoots {
+		// drop the roots in other roots
+		u.Roots = utils.SliceFilter(roots, func(root string) bool {
+			for _, r := range roots {
+				if r != root && utils.IsSubPath(r, root) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+	if !allStorages {
+		u.StorageIds = storageIds
+	}
+	return nil
+}
+
+func GetGroupById(id uint) (*model.Group, error) {
+	return db.GetGroupById(id)
+}
+
+func GetGroups(pageIndex, pageSize int) ([]model.Group, int64, error) {
+	return db.GetGroups(pageIndex, pageSize)
+}
+
+func CreateGroup(g *model.Group) error {
+	return db.CreateGroup(g)
+}
+
+// UpdateGroup saves g, the users in it get the new rights at once
+func UpdateGroup(g *model.Group) error {
+	if err := db.UpdateGroup(g); err != nil {
+		return err
+	}
+	return delGroupUsersCache(g.ID)
+}
+
+// DeleteGroupById deletes the group and removes it from its users
+func DeleteGroupById(id uint) error {
+	users, err := db.GetUsersByGroupId(id)
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		u := &users[i]
+		u.GroupIds = utils.SliceFilter(u.GroupIds, func(gid uint) bool {
+			return gid != id
+		})
+		if err := db.UpdateUser(u); err != nil {
+			return err
+		}
+		if err := DelUserCache(u.Username); err != nil {
+			return err
+		}
+	}
+	aclCache.Del(aclKey(model.AclSubjectGroup, id))
+	return db.DeleteGroupById(id)
+}
+
+func delGroupUsersCache(id uint) error {
+	users, err := db.GetUsersByGroupId(id)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if err := DelUserCache(u.Username); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package op_test
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestGroupRoots(t *testing.T) {
+	newGroup := func(name string, basePaths ...string) uint {
+		g := &model.Group{Name: name, Permission: 1, BasePaths: basePaths}
+		if err := op.CreateGroup(g); err != nil {
+			t.Fatal(err)
+		}
+		return g.ID
+	}
+	inside := newGroup("roots_inside", "/base/a", "/base/a/b", "/other")
+	more := newGroup("roots_more", "/base/c")
+	all := newGroup("roots_all", "/")
+	noPaths := newGroup("roots_none")
+
+	tests := []struct {
+		name     string
+		groupIds []uint
+		roots    []string
+	}{
+		{"roots_user_inside", []uint{inside}, []string{"/base/a"}},
+		{"roots_user_more", []uint{inside, more}, []string{"/base/a", "/base/c"}},
+		{"roots_user_all", []uint{inside, all}, nil},
+		{"roots_user_no_paths", []uint{noPaths}, nil},
+		{"roots_user_outside", []uint{newGroup("roots_outside", "/other")}, []string{}},
+	}
+	for _, test := range tests {
+		if err := op.CreateUser(&model.User{Username: test.name, BasePath: "/base", Role: model.GENERAL, GroupIds: test.groupIds}); err != nil {
+			t.Fatal(err)
+		}
+		user, err := op.GetUserByName(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (user.Roots == nil) != (test.roots == nil) || len(user.Roots) != len(test.roots) {
+			t.Errorf("%s should see %v, got %v", test.name, test.roots, user.Roots)
+			continue
+		}
+		for i := range test.roots {
+			if user.Roots[i] != test.roots[i] {
+				t.Errorf("%s should see %v, got %v", test.name, test.roots, user.Roots)
+			}
+		}
+		// the request paths are always relative to BasePath
+		if p, _ := user.JoinPath("/a/x"); p != "/base/a/x" {
+			t.Errorf("the path of %s should be joined to its base path, got %s", test.name, p)
+		}
+	}
+
+	user, _ := op.GetUserByName("roots_user_more")
+	for _, c := range []struct {
+		path         string
+		in, ancestor bool
+	}{
+		{"/base/a/x", true, false},
+		{"/base/c", true, false},
+		{"/base", false, true},
+		{"/base/d", false, false},
+	} {
+		if in, ancestor := user.InRoots(c.path); in != c.in || ancestor != c.ancestor {
+			t.Errorf("%s should be in: %t, ancestor: %t, got %t, %t", c.path, c.in, c.ancestor, in, ancestor)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package op
 
 import (
+	stdpath "path"
 	"regexp"
 	"strings"
 
@@ -29,6 +30,42 @@ func HandleObjsUpdateHook(parent string, objs []model.Obj) {
 	}
 }
 
+// ObjChange
+const (
+	ObjChangeAdd    = "add"    // the object at path is created or overwritten
+	ObjChangeRemove = "remove" // the object at path is removed
+	ObjChangeMove   = "move"   // the object at oldPath is moved or renamed to path
+)
+
+// ObjChangeHook is called after an object is changed through op,
+// the paths are full paths with the mount path, oldPath is only set for move,
+// obj is nil if the driver doesn't return the changed object
+type ObjChangeHook func(typ, path, oldPath string, obj model.Obj)
+
+var objChangeHooks = make([]ObjChangeHook, 0)
+
+func RegisterObjChangeHook(hook ObjChangeHook) {
+	objChangeHooks = append(objChangeHooks, hook)
+}
+
+// callObjChangeHooks takes the actual paths in storage
+func callObjChangeHooks(typ string, storage driver.Driver, path, oldPath string, obj model.Obj) {
+	if len(objChangeHooks) == 0 {
+		return
+	}
+	mountPath := utils.GetActualMountPath(storage.GetStorage().MountPath)
+	path = stdpath.Join(mountPath, path)
+	if oldPath != "" {
+		oldPath = stdpath.Join(mountPath, oldPath)
+	}
+	if obj != nil {
+		obj = model.WrapObjName(obj)
+	}
+	for _, hook := range objChangeHooks {
+		hook(typ, path, oldPath, obj)
+	}
+}
+
 // Setting
 type SettingItemHook func(item *model.SettingItem) error
 
@@ -109,3 +146,19 @@ func callStorageHooks(typ string, storage driver.Driver) {
 func RegisterStorageHook(hook StorageHook) {
 	storageHooks = append(storageHooks, hook)
 }
+
+// Event
+type EventHook func(event string, data map[string]interface{})
+
+var eventHooks = make([]EventHook, 0)
+
+func RegisterEventHook(hook EventHook) {
+	eventHooks = append(eventHooks, hook)
+}
+
+// HandleEventHook passes the event to the hooks, data["path"] is the path the event happened on
+func HandleEventHook(event string, data map[string]interface{}) {
+	for _, hook := range eventHooks {
+		hook(event, data)
+	}
+}
@@ -0,0 +1,131 @@
+package op
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// CheckQuota checks whether user can upload more bytes and files,
+// both the quota of user and the quotas shared by its groups are checked.
+func CheckQuota(user *model.User, bytes, files int64) error {
+	if user == nil || user.IsAdmin() {
+		return nil
+	}
+	if user.QuotaBytes > 0 || user.QuotaFiles > 0 {
+		usage, err := db.GetUserUsage(user.ID)
+		if err != nil {
+			return err
+		}
+		if exceeded(user.QuotaBytes, user.QuotaFiles, usage.Bytes+bytes, usage.Files+files) {
+			return errors.WithStack(errs.QuotaExceeded)
+		}
+	}
+	groups, err := db.GetGroupsByIds(user.GroupIds)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if g.QuotaBytes <= 0 && g.QuotaFiles <= 0 {
+			continue
+		}
+		usedBytes, usedFiles, err := GetGroupUsage(g.ID)
+		if err != nil {
+			return err
+		}
+		if exceeded(g.QuotaBytes, g.QuotaFiles, usedBytes+bytes, usedFiles+files) {
+			return errors.Wrapf(errs.QuotaExceeded, "of group %s", g.Name)
+		}
+	}
+	return nil
+}
+
+func exceeded(quotaBytes, quotaFiles, bytes, files int64) bool {
+	return (quotaBytes > 0 && bytes > quotaBytes) || (quotaFiles > 0 && files > quotaFiles)
+}
+
+// GetGroupUsage returns the total usage of the users in the group
+func GetGroupUsage(groupId uint) (bytes int64, files int64, err error) {
+	users, err := db.GetUsersByGroupId(groupId)
+	if err != nil {
+		return 0, 0, err
+	}
+	ids := make([]uint, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	return db.SumUserUsage(ids)
+}
+
+func GetUserUsage(userId uint) (*model.UserUsage, error) {
+	return db.GetUserUsage(userId)
+}
+
+// AddUsage records the uploaded bytes and files of user, 0 user id is ignored
+func AddUsage(userId uint, bytes, files int64) {
+	if userId == 0 {
+		return
+	}
+	if err := db.AddUserUsage(userId, bytes, files); err != nil {
+		log.Errorf("failed add usage of user [%d]: %+v", userId, err)
+	}
+}
+
+// AddUpload charges the file at path uploaded by user and records the user as its owner,
+// the usage of the file it overwrites is given back to the owner of that file
+func AddUpload(userId uint, path string, size int64) {
+	path = utils.FixAndCleanPath(path)
+	if userId == 0 {
+		RemoveUploads(path)
+		return
+	}
+	old, err := db.SetUploadOwner(&model.UploadOwner{Path: path, UserID: userId, Size: size})
+	if err != nil {
+		log.Errorf("failed set the owner of [%s]: %+v", path, err)
+	}
+	if old != nil {
+		giveBack([]model.UploadOwner{*old})
+	}
+	AddUsage(userId, size, 1)
+}
+
+// RemoveUploads gives the usage of the removed files at path and in it back to their owners
+func RemoveUploads(path string) {
+	owners, err := db.DeleteUploadOwners(utils.FixAndCleanPath(path))
+	if err != nil {
+		log.Errorf("failed delete the owners of [%s]: %+v", path, err)
+		return
+	}
+	giveBack(owners)
+}
+
+// giveBack gives the usage of the files back to their owners
+func giveBack(owners []model.UploadOwner) {
+	type usage struct{ bytes, files int64 }
+	usages := make(map[uint]usage)
+	for _, owner := range owners {
+		u := usages[owner.UserID]
+		usages[owner.UserID] = usage{bytes: u.bytes + owner.Size, files: u.files + 1}
+	}
+	for userId, u := range usages {
+		AddUsage(userId, -u.bytes, -u.files)
+	}
+}
+
+// MoveUploads keeps the owners of the files at srcPath and in it after they are moved or renamed to dstPath
+func MoveUploads(srcPath, dstPath string) {
+	srcPath, dstPath = utils.FixAndCleanPath(srcPath), utils.FixAndCleanPath(dstPath)
+	overwritten, err := db.MoveUploadOwners(srcPath, dstPath)
+	if err != nil {
+		log.Errorf("failed move the owners of [%s] to [%s]: %+v", srcPath, dstPath, err)
+		return
+	}
+	giveBack(overwritten)
+}
+
+func ResetUserUsage(userId uint) error {
+	return db.ResetUserUsage(userId)
+}
@@ -0,0 +1,45 @@
+package op_test
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestUploadOwners(t *testing.T) {
+	owner := &model.User{Username: "upload_owner", Role: model.GENERAL, BasePath: "/"}
+	other := &model.User{Username: "upload_other", Role: model.GENERAL, BasePath: "/"}
+	for _, u := range []*model.User{owner, other} {
+		if err := op.CreateUser(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	usageOf := func(u *model.User) (int64, int64) {
+		usage, err := op.GetUserUsage(u.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return usage.Bytes, usage.Files
+	}
+	op.AddUpload(owner.ID, "/owners/a/x", 1)
+	op.AddUpload(owner.ID, "/owners/a/y", 2)
+	op.AddUpload(owner.ID, "/owners/ab", 4)
+	// overwriting gives the usage of the old file back
+	op.AddUpload(other.ID, "/owners/a/y", 8)
+	if bytes, files := usageOf(owner); bytes != 5 || files != 2 {
+		t.Errorf("the overwritten file should be given back, got %d bytes of %d files", bytes, files)
+	}
+	op.MoveUploads("/owners/a", "/owners/c")
+	op.RemoveUploads("/owners/a")
+	if bytes, files := usageOf(owner); bytes != 5 || files != 2 {
+		t.Errorf("the moved files should not be removed with the old path, got %d bytes of %d files", bytes, files)
+	}
+	op.RemoveUploads("/owners/c")
+	if bytes, files := usageOf(owner); bytes != 4 || files != 1 {
+		t.Errorf("only the files in the folder should be given back, got %d bytes of %d files", bytes, files)
+	}
+	if bytes, files := usageOf(other); bytes != 0 || files != 0 {
+		t.Errorf("the files should be given back to their own uploaders, got %d bytes of %d files", bytes, files)
+	}
+}
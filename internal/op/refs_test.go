@@ -0,0 +1,63 @@
+package op
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+// checkedDriver is a driver which checks the status, only the methods used by the refs are implemented
+type checkedDriver struct {
+	driver.Driver
+	storage model.Storage
+	dropped bool
+}
+
+func (d *checkedDriver) Config() driver.Config {
+	return driver.Config{CheckStatus: true}
+}
+
+func (d *checkedDriver) GetStorage() *model.Storage {
+	return &d.storage
+}
+
+func (d *checkedDriver) Drop(ctx context.Context) error {
+	d.dropped = true
+	return nil
+}
+
+func TestAcquireStorage(t *testing.T) {
+	d := &checkedDriver{storage: model.Storage{MountPath: "/checked", Status: DEGRADED + ": timeout"}}
+	release, err := acquireStorage(d)
+	if err != nil {
+		t.Fatalf("the degraded storage should serve requests: %+v", err)
+	}
+	// a nested request of the same storage
+	releaseNested, err := acquireStorage(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropWhenIdle(d)
+	releaseNested()
+	if d.dropped {
+		t.Errorf("the storage should not be dropped while a request uses it")
+	}
+	release()
+	if !d.dropped {
+		t.Errorf("the storage should be dropped after the requests finish")
+	}
+	if len(refs) != 0 {
+		t.Errorf("the refs of the released storage should be removed")
+	}
+
+	d = &checkedDriver{storage: model.Storage{MountPath: "/checked", Status: "failed init"}}
+	if _, err := acquireStorage(d); err == nil {
+		t.Errorf("the storage which failed to init should not serve requests")
+	}
+	dropWhenIdle(d)
+	if !d.dropped {
+		t.Errorf("the idle storage should be dropped at once")
+	}
+}
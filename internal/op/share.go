@@ -0,0 +1,60 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// shareIdLength is the length of the random share id
+const shareIdLength = 8
+
+func GetShareById(id string) (*model.Share, error) {
+	s, err := db.GetShareById(id)
+	if err != nil {
+		return nil, err
+	}
+	s.HasPassword = s.Password != ""
+	return s, nil
+}
+
+func GetShares(userId uint, pageIndex, pageSize int) ([]model.Share, int64, error) {
+	shares, total, err := db.GetShares(userId, pageIndex, pageSize)
+	for i := range shares {
+		shares[i].HasPassword = shares[i].Password != ""
+	}
+	return shares, total, err
+}
+
+// CreateShare saves s with a new random id
+func CreateShare(s *model.Share) error {
+	s.Path = utils.FixAndCleanPath(s.Path)
+	s.Downloads = 0
+	s.Created = time.Now()
+	s.HasPassword = s.Password != ""
+	for i := 0; i < 5; i++ {
+		s.ID = random.String(shareIdLength)
+		_, err := db.GetShareById(s.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return db.CreateShare(s)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return errors.New("failed generate share id")
+}
+
+// IncrShareDownloads counts a download, false if the share has no downloads left
+func IncrShareDownloads(id string) (bool, error) {
+	return db.IncrShareDownloads(id)
+}
+
+func DeleteShareById(id string) error {
+	return db.DeleteShareById(id)
+}
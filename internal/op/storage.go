@@ -4,6 +4,7 @@ import (
 	"context"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/db"
@@ -94,6 +95,7 @@ func initStorage(ctx context.Context, storage model.Storage, storageDriver drive
 		err = storageDriver.Init(ctx)
 	}
 	storagesMap.Store(driverStorage.MountPath, storageDriver)
+	detailsCache.Del(driverStorage.MountPath)
 	if err != nil {
 		driverStorage.SetStatus(err.Error())
 		err = errors.Wrap(err, "failed init storage")
@@ -213,9 +215,105 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 	if err := db.DeleteStorageById(id); err != nil {
 		return errors.WithMessage(err, "failed delete storage in database")
 	}
+	if err := db.DeleteStorageHealthsByStorageId(id); err != nil {
+		log.Errorf("failed delete health history of storage %d: %+v", id, err)
+	}
 	return nil
 }
 
+// ReinitStorage initializes a new driver of the storage with its current config and replaces the loaded one,
+// it's used to recover a storage whose token expired or whose backend went away.
+// The old driver keeps serving until the new one is loaded, and it's dropped after the requests using it finish
+func ReinitStorage(ctx context.Context, storageDriver driver.Driver) (driver.Driver, error) {
+	storage := *storageDriver.GetStorage()
+	// the storage may be disabled, deleted or updated in the meantime
+	if current, ok := storagesMap.Load(storage.MountPath); !ok || current != storageDriver {
+		return nil, errors.Errorf("storage %s is not loaded", storage.MountPath)
+	}
+	driverNew, err := GetDriver(storage.Driver)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get driver new")
+	}
+	newDriver := driverNew()
+	err = initStorage(ctx, storage, newDriver)
+	dropWhenIdle(storageDriver)
+	go callStorageHooks("update", newDriver)
+	return newDriver, err
+}
+
+// SetStorageStatus changes the status of a loaded storage, such as marking it degraded
+func SetStorageStatus(storageDriver driver.Driver, status string) {
+	if storageDriver.GetStorage().Status == status {
+		return
+	}
+	storageDriver.GetStorage().SetStatus(status)
+	MustSaveDriverStorage(storageDriver)
+	go callStorageHooks("update", storageDriver)
+}
+
+// IsWorking tells whether a storage of the status can serve requests,
+// a degraded storage still serves them until it's recovered or failed to reinit
+func IsWorking(status string) bool {
+	return status == WORK || strings.HasPrefix(status, DEGRADED)
+}
+
+// storageRefs counts the requests using a driver, the driver replaced by ReinitStorage is dropped when it's zero
+type storageRefs struct {
+	count int
+	drop  bool
+}
+
+var (
+	refsLock sync.Mutex
+	refs     = make(map[driver.Driver]*storageRefs)
+)
+
+// acquireStorage checks the storage can serve requests and keeps it from being dropped until release is called
+func acquireStorage(storage driver.Driver) (release func(), err error) {
+	if storage.Config().CheckStatus && !IsWorking(storage.GetStorage().Status) {
+		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	refsLock.Lock()
+	r, ok := refs[storage]
+	if !ok {
+		r = &storageRefs{}
+		refs[storage] = r
+	}
+	r.count++
+	refsLock.Unlock()
+	return func() {
+		refsLock.Lock()
+		r.count--
+		drop := r.count == 0 && r.drop
+		if r.count == 0 {
+			delete(refs, storage)
+		}
+		refsLock.Unlock()
+		if drop {
+			dropStorage(storage)
+		}
+	}, nil
+}
+
+// dropWhenIdle drops the driver now if no request uses it, or after the last request releases it
+func dropWhenIdle(storage driver.Driver) {
+	refsLock.Lock()
+	r, ok := refs[storage]
+	if ok {
+		r.drop = true
+	}
+	refsLock.Unlock()
+	if !ok {
+		dropStorage(storage)
+	}
+}
+
+func dropStorage(storage driver.Driver) {
+	if err := storage.Drop(context.Background()); err != nil {
+		log.Warnf("failed drop replaced storage %s: %+v", storage.GetStorage().MountPath, err)
+	}
+}
+
 // MustSaveDriverStorage call from specific driver
 func MustSaveDriverStorage(driver driver.Driver) {
 	err := saveDriverStorage(driver)
@@ -304,7 +402,7 @@ func GetStorageVirtualFilesByPath(prefix string) []model.Obj {
 
 var balanceMap generic_sync.MapOf[string, int]
 
-// GetBalancedStorage get storage by path
+// GetBalancedStorage get storage by path, the storage of a balance group is chosen by its strategy
 func GetBalancedStorage(path string) driver.Driver {
 	path = utils.FixAndCleanPath(path)
 	storages := getStoragesByPath(path)
@@ -315,10 +413,6 @@ func GetBalancedStorage(path string) driver.Driver {
 	case 1:
 		return storages[0]
 	default:
-		virtualPath := utils.GetActualMountPath(storages[0].GetStorage().MountPath)
-		i, _ := balanceMap.LoadOrStore(virtualPath, 0)
-		i = (i + 1) % storageNum
-		balanceMap.Store(virtualPath, i)
-		return storages[i]
+		return balance(storages)
 	}
 }
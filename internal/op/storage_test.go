@@ -88,3 +88,28 @@ func setupStorages(t *testing.T) {
 		}
 	}
 }
+
+func TestReinitStorage(t *testing.T) {
+	_, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: "/reinit", Addition: `{"root_folder_path":"."}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := op.GetStorageByMountPath("/reinit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	op.SetStorageStatus(old, op.DEGRADED+": timeout")
+	reinited, err := op.ReinitStorage(context.Background(), old)
+	if err != nil {
+		t.Fatalf("failed reinit: %+v", err)
+	}
+	if current, _ := op.GetStorageByMountPath("/reinit"); current != reinited || current == old {
+		t.Errorf("the reinited driver should replace the old one")
+	}
+	if status := reinited.GetStorage().Status; status != op.WORK {
+		t.Errorf("the reinited storage should work, got %s", status)
+	}
+	if _, err := op.ReinitStorage(context.Background(), old); err == nil {
+		t.Errorf("the replaced driver should not be reinited")
+	}
+}
@@ -33,6 +33,9 @@ func GetGuest() (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := applyGroups(user); err != nil {
+			return nil, err
+		}
 		guestUser = user
 	}
 	return guestUser, nil
@@ -54,6 +57,9 @@ func GetUserByName(username string) (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := applyGroups(_user); err != nil {
+			return nil, err
+		}
 		userCache.Set(username, _user, cache.WithEx[*model.User](time.Hour))
 		return _user, nil
 	})
@@ -82,6 +88,9 @@ func DeleteUserById(id uint) error {
 		return errs.DeleteAdminOrGuest
 	}
 	userCache.Del(old.Username)
+	if err := db.DeleteSharesByUserId(id); err != nil {
+		return err
+	}
 	return db.DeleteUserById(id)
 }
 
@@ -8,7 +8,9 @@ import (
 )
 
 var config = searcher.Config{
-	Name: "bleve",
+	Name:         "bleve",
+	AutoUpdate:   true,
+	IndexContent: true,
 }
 
 func Init(indexPath *string) (bleve.Index, error) {
@@ -19,13 +21,19 @@ func Init(indexPath *string) (bleve.Index, error) {
 		indexMapping := bleve.NewIndexMapping()
 		searchNodeMapping := bleve.NewDocumentMapping()
 		searchNodeMapping.AddFieldMappingsAt("is_dir", bleve.NewBooleanFieldMapping())
+		// parent is kept as a whole to filter by path
+		searchNodeMapping.AddFieldMappingsAt("parent", bleve.NewKeywordFieldMapping())
 		// TODO: appoint analyzer
-		parentFieldMapping := bleve.NewTextFieldMapping()
-		searchNodeMapping.AddFieldMappingsAt("parent", parentFieldMapping)
-		// TODO: appoint analyzer
-		nameFieldMapping := bleve.NewKeywordFieldMapping()
-		searchNodeMapping.AddFieldMappingsAt("name", nameFieldMapping)
-		indexMapping.AddDocumentMapping("SearchNode", searchNodeMapping)
+		searchNodeMapping.AddFieldMappingsAt("name", bleve.NewTextFieldMapping())
+		searchNodeMapping.AddFieldMappingsAt("size", bleve.NewNumericFieldMapping())
+		searchNodeMapping.AddFieldMappingsAt("modified", bleve.NewDateTimeFieldMapping())
+		searchNodeMapping.AddFieldMappingsAt("category", bleve.NewNumericFieldMapping())
+		searchNodeMapping.AddFieldMappingsAt("ext", bleve.NewKeywordFieldMapping())
+		// the content is only searched, not returned
+		contentFieldMapping := bleve.NewTextFieldMapping()
+		contentFieldMapping.Store = false
+		searchNodeMapping.AddFieldMappingsAt("content", contentFieldMapping)
+		indexMapping.DefaultMapping = searchNodeMapping
 		fileIndex, err = bleve.New(*indexPath, indexMapping)
 		if err != nil {
 			return nil, err
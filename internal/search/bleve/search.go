@@ -3,11 +3,13 @@ package bleve
 import (
 	"context"
 	"os"
+	stdpath "path"
+	"strings"
+	"time"
 
 	query2 "github.com/blevesearch/bleve/v2/search/query"
 
 	"github.com/alist-org/alist/v3/internal/conf"
-	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -25,56 +27,209 @@ func (b *Bleve) Config() searcher.Config {
 	return config
 }
 
+// document is the node stored in bleve, ext is kept to filter by extension
+type document struct {
+	model.SearchNode
+	Ext     string `json:"ext"`
+	Content string `json:"content,omitempty"`
+}
+
+func toDocument(node model.SearchNode) document {
+	return document{SearchNode: node, Ext: utils.Ext(node.Name), Content: node.Content}
+}
+
+// the fields returned in the results, the content is left out
+var resultFields = []string{"parent", "name", "is_dir", "size", "modified", "category"}
+
 func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	var queries []query2.Query
-	query := bleve.NewMatchQuery(req.Keywords)
-	query.SetField("name")
-	queries = append(queries, query)
+	if req.Keywords != "" {
+		nameQuery := bleve.NewMatchQuery(req.Keywords)
+		nameQuery.SetField("name")
+		// the documents containing the phrase
+		contentQuery := bleve.NewMatchPhraseQuery(req.Keywords)
+		contentQuery.SetField("content")
+		queries = append(queries, bleve.NewDisjunctionQuery(nameQuery, contentQuery))
+	} else {
+		queries = append(queries, bleve.NewMatchAllQuery())
+	}
+	if req.Parent != "/" {
+		// the parent itself or its sub folders
+		parentQuery := bleve.NewTermQuery(req.Parent)
+		parentQuery.SetField("parent")
+		subQuery := bleve.NewPrefixQuery(req.Parent + "/")
+		subQuery.SetField("parent")
+		queries = append(queries, bleve.NewDisjunctionQuery(parentQuery, subQuery))
+	}
 	if req.Scope != 0 {
 		isDir := req.Scope == 1
 		isDirQuery := bleve.NewBoolFieldQuery(isDir)
+		isDirQuery.SetField("is_dir")
 		queries = append(queries, isDirQuery)
 	}
+	if len(req.Exts) > 0 {
+		extQueries := make([]query2.Query, 0, len(req.Exts))
+		for _, ext := range req.Exts {
+			extQuery := bleve.NewTermQuery(ext)
+			extQuery.SetField("ext")
+			extQueries = append(extQueries, extQuery)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(extQueries...))
+	}
+	if len(req.Categories) > 0 {
+		inclusive := true
+		categoryQueries := make([]query2.Query, 0, len(req.Categories))
+		for _, category := range req.Categories {
+			c := float64(category)
+			categoryQuery := bleve.NewNumericRangeInclusiveQuery(&c, &c, &inclusive, &inclusive)
+			categoryQuery.SetField("category")
+			categoryQueries = append(categoryQueries, categoryQuery)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(categoryQueries...))
+	}
+	if req.MinSize > 0 || req.MaxSize > 0 {
+		inclusive := true
+		var min, max *float64
+		if req.MinSize > 0 {
+			minSize := float64(req.MinSize)
+			min = &minSize
+		}
+		if req.MaxSize > 0 {
+			maxSize := float64(req.MaxSize)
+			max = &maxSize
+		}
+		sizeQuery := bleve.NewNumericRangeInclusiveQuery(min, max, &inclusive, &inclusive)
+		sizeQuery.SetField("size")
+		queries = append(queries, sizeQuery)
+	}
+	if req.ModifiedFrom != nil || req.ModifiedTo != nil {
+		inclusive := true
+		var start, end time.Time
+		if req.ModifiedFrom != nil {
+			start = *req.ModifiedFrom
+		}
+		if req.ModifiedTo != nil {
+			end = *req.ModifiedTo
+		}
+		modifiedQuery := bleve.NewDateRangeInclusiveQuery(start, end, &inclusive, &inclusive)
+		modifiedQuery.SetField("modified")
+		queries = append(queries, modifiedQuery)
+	}
 	reqQuery := bleve.NewConjunctionQuery(queries...)
 	search := bleve.NewSearchRequest(reqQuery)
-	search.SortBy([]string{"name"})
+	if req.OrderBy == model.SearchOrderByRelevance {
+		search.SortBy([]string{"-_score", "name"})
+	} else if req.Desc() {
+		search.SortBy([]string{"-" + req.OrderBy})
+	} else {
+		search.SortBy([]string{req.OrderBy})
+	}
 	search.From = (req.Page - 1) * req.PerPage
 	search.Size = req.PerPage
-	search.Fields = []string{"*"}
+	search.Fields = resultFields
 	searchResults, err := b.BIndex.Search(search)
 	if err != nil {
 		log.Errorf("search error: %+v", err)
 		return nil, 0, err
 	}
 	res, err := utils.SliceConvert(searchResults.Hits, func(src *search2.DocumentMatch) (model.SearchNode, error) {
-		return model.SearchNode{
-			Parent: src.Fields["parent"].(string),
-			Name:   src.Fields["name"].(string),
-			IsDir:  src.Fields["is_dir"].(bool),
-			Size:   int64(src.Fields["size"].(float64)),
-		}, nil
+		return toSearchNode(src), nil
 	})
 	return res, int64(searchResults.Total), nil
 }
 
+func toSearchNode(hit *search2.DocumentMatch) model.SearchNode {
+	node := model.SearchNode{
+		Parent: hit.Fields["parent"].(string),
+		Name:   hit.Fields["name"].(string),
+		IsDir:  hit.Fields["is_dir"].(bool),
+		Size:   int64(hit.Fields["size"].(float64)),
+	}
+	// the fields are absent in the nodes indexed before they are added
+	if modified, ok := hit.Fields["modified"].(string); ok {
+		node.Modified, _ = time.Parse(time.RFC3339Nano, modified)
+	}
+	if category, ok := hit.Fields["category"].(float64); ok {
+		node.Category = int(category)
+	}
+	return node
+}
+
+const findPageSize = 1000
+
+// find returns all the documents matching q
+func (b *Bleve) find(q query2.Query) ([]*search2.DocumentMatch, error) {
+	var hits []*search2.DocumentMatch
+	for {
+		search := bleve.NewSearchRequestOptions(q, findPageSize, len(hits), false)
+		search.Fields = resultFields
+		res, err := b.BIndex.Search(search)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, res.Hits...)
+		if len(res.Hits) < findPageSize {
+			return hits, nil
+		}
+	}
+}
+
+// parentQuery matches the documents in the folder
+func parentQuery(parent string) query2.Query {
+	q := bleve.NewTermQuery(parent)
+	q.SetField("parent")
+	return q
+}
+
 func (b *Bleve) Index(ctx context.Context, node model.SearchNode) error {
-	return b.BIndex.Index(uuid.NewString(), node)
+	return b.BIndex.Index(uuid.NewString(), toDocument(node))
 }
 
 func (b *Bleve) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
 	batch := b.BIndex.NewBatch()
 	for _, node := range nodes {
-		batch.Index(uuid.NewString(), node)
+		batch.Index(uuid.NewString(), toDocument(node))
 	}
 	return b.BIndex.Batch(batch)
 }
 
 func (b *Bleve) Get(ctx context.Context, parent string) ([]model.SearchNode, error) {
-	return nil, errs.NotSupport
+	hits, err := b.find(parentQuery(parent))
+	if err != nil {
+		return nil, err
+	}
+	return utils.MustSliceConvert(hits, toSearchNode), nil
 }
 
+// Del removes the node at prefix and the nodes under it, the parent is indexed as a keyword to be matched as a whole
 func (b *Bleve) Del(ctx context.Context, prefix string) error {
-	return errs.NotSupport
+	prefix = utils.FixAndCleanPath(prefix)
+	subQuery := bleve.NewPrefixQuery(strings.TrimSuffix(prefix, "/") + "/")
+	subQuery.SetField("parent")
+	hits, err := b.find(bleve.NewDisjunctionQuery(parentQuery(prefix), subQuery))
+	if err != nil {
+		return err
+	}
+	if prefix != "/" {
+		// the name is analyzed, so the node itself is matched among the nodes of its parent
+		nodes, err := b.find(parentQuery(stdpath.Dir(prefix)))
+		if err != nil {
+			return err
+		}
+		for _, hit := range nodes {
+			if hit.Fields["name"] == stdpath.Base(prefix) {
+				hits = append(hits, hit)
+			}
+		}
+	}
+	if len(hits) == 0 {
+		return nil
+	}
+	batch := b.BIndex.NewBatch()
+	for _, hit := range hits {
+		batch.Delete(hit.ID)
+	}
+	return b.BIndex.Batch(batch)
 }
 
 func (b *Bleve) Release(ctx context.Context) error {
@@ -0,0 +1,97 @@
+package bleve
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func newTestBleve(t *testing.T) *Bleve {
+	path := filepath.Join(t.TempDir(), "bleve")
+	index, err := Init(&path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Bleve{BIndex: index}
+	t.Cleanup(func() {
+		_ = b.Release(context.Background())
+	})
+	err = b.BatchIndex(context.Background(), []model.SearchNode{
+		{Parent: "/a", Name: "x.txt", Size: 10},
+		{Parent: "/a", Name: "b", IsDir: true},
+		{Parent: "/a/b", Name: "y.mp4", Size: 1000},
+		{Parent: "/a", Name: "bc", IsDir: true},
+		{Parent: "/a/bc", Name: "z.TXT", Size: 100},
+		{Parent: "/ab", Name: "w.txt", Size: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func paths(nodes []model.SearchNode) string {
+	var res []string
+	for _, node := range nodes {
+		res = append(res, strings.TrimSuffix(node.Parent, "/")+"/"+node.Name)
+	}
+	sort.Strings(res)
+	return strings.Join(res, ",")
+}
+
+func TestSearchFilters(t *testing.T) {
+	b := newTestBleve(t)
+	for _, c := range []struct {
+		req      model.SearchReq
+		expected string
+	}{
+		{model.SearchReq{Parent: "/a"}, "/a/b,/a/b/y.mp4,/a/bc,/a/bc/z.TXT,/a/x.txt"},
+		{model.SearchReq{Parent: "/", Exts: []string{"txt"}}, "/a/bc/z.TXT,/a/x.txt,/ab/w.txt"},
+		{model.SearchReq{Parent: "/", Scope: 1}, "/a/b,/a/bc"},
+		{model.SearchReq{Parent: "/", MinSize: 50, Scope: 2}, "/a/b/y.mp4,/a/bc/z.TXT"},
+		{model.SearchReq{Parent: "/", MaxSize: 50, Scope: 2}, "/a/x.txt,/ab/w.txt"},
+	} {
+		c.req.PageReq = model.PageReq{Page: 1, PerPage: 100}
+		if err := c.req.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		nodes, _, err := b.Search(context.Background(), c.req)
+		if err != nil {
+			t.Fatalf("failed search %+v: %+v", c.req, err)
+		}
+		if res := paths(nodes); res != c.expected {
+			t.Errorf("search %+v should find %s, got %s", c.req, c.expected, res)
+		}
+	}
+}
+
+func TestDel(t *testing.T) {
+	b := newTestBleve(t)
+	ctx := context.Background()
+	if err := b.Del(ctx, "/a/b"); err != nil {
+		t.Fatalf("failed del: %+v", err)
+	}
+	nodes, err := b.Get(ctx, "/a")
+	if err != nil {
+		t.Fatalf("failed get: %+v", err)
+	}
+	if res := paths(nodes); res != "/a/bc,/a/x.txt" {
+		t.Errorf("the folder should be removed with the nodes in it, got %s", res)
+	}
+	if nodes, _ := b.Get(ctx, "/a/b"); len(nodes) != 0 {
+		t.Errorf("the nodes in the removed folder should be removed, got %s", paths(nodes))
+	}
+	if nodes, _ := b.Get(ctx, "/a/bc"); len(nodes) != 1 {
+		t.Errorf("the folder of the same prefix should be kept")
+	}
+	if err := b.Del(ctx, "/"); err != nil {
+		t.Fatalf("failed del: %+v", err)
+	}
+	if count, _ := b.BIndex.DocCount(); count != 0 {
+		t.Errorf("all the nodes should be removed, got %d", count)
+	}
+}
@@ -166,23 +166,29 @@ func Config(ctx context.Context) searcher.Config {
 	return instance.Config()
 }
 
-func Update(parent string, objs []model.Obj) {
+// canAutoUpdate reports whether the index is built and should be updated automatically
+func canAutoUpdate() bool {
 	if instance == nil || !instance.Config().AutoUpdate || !setting.GetBool(conf.AutoUpdateIndex) || Running.Load() {
-		return
-	}
-	if isIgnorePath(parent) {
-		return
+		return false
 	}
-	ctx := context.Background()
 	// only update when index have built
 	progress, err := Progress()
 	if err != nil {
 		log.Errorf("update search index error while get progress: %+v", err)
+		return false
+	}
+	return progress.IsDone
+}
+
+func Update(parent string, objs []model.Obj) {
+	// the changes applied are walked by themselves
+	if applying.Load() || !canAutoUpdate() {
 		return
 	}
-	if !progress.IsDone {
+	if isIgnorePath(parent) {
 		return
 	}
+	ctx := context.Background()
 	nodes, err := instance.Get(ctx, parent)
 	if err != nil {
 		log.Errorf("update search index error while get nodes: %+v", err)
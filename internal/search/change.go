@@ -0,0 +1,118 @@
+package search
+
+import (
+	"context"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type objChange struct {
+	typ     string
+	path    string
+	oldPath string
+	obj     model.Obj
+}
+
+var (
+	// the changes are applied one by one in order, so a move after an add is not lost
+	objChanges = make(chan objChange, 1000)
+	applying   = atomic.Bool{}
+)
+
+// onObjChange queues the change made through op to update the index
+func onObjChange(typ, path, oldPath string, obj model.Obj) {
+	if !canAutoUpdate() {
+		return
+	}
+	select {
+	case objChanges <- objChange{typ: typ, path: path, oldPath: oldPath, obj: obj}:
+	default:
+		log.Warnf("too many changes to update the search index, drop the change [%s] of %s", typ, path)
+	}
+}
+
+func applyObjChanges() {
+	for change := range objChanges {
+		applying.Store(true)
+		err := applyObjChange(context.Background(), change)
+		applying.Store(false)
+		if err != nil {
+			log.Errorf("update search index error while apply change [%s] of %s: %+v", change.typ, change.path, err)
+		}
+	}
+}
+
+func applyObjChange(ctx context.Context, change objChange) error {
+	// the index may be rebuilt or changed after the change is queued
+	if !canAutoUpdate() {
+		return nil
+	}
+	switch change.typ {
+	case op.ObjChangeRemove:
+		return instance.Del(ctx, change.path)
+	case op.ObjChangeMove:
+		if err := instance.Del(ctx, change.oldPath); err != nil {
+			return errors.WithMessage(err, "failed del old index")
+		}
+	case op.ObjChangeAdd:
+		// the object may be overwritten
+		if err := instance.Del(ctx, change.path); err != nil {
+			return errors.WithMessage(err, "failed del old index")
+		}
+	default:
+		return errors.Errorf("unknown change type: %s", change.typ)
+	}
+	return indexPath(ctx, change.path, change.obj)
+}
+
+// indexPath indexes the object at path, with all the objects under it if it's a folder
+func indexPath(ctx context.Context, path string, obj model.Obj) error {
+	if isIgnorePath(path) {
+		return nil
+	}
+	admin, err := op.GetAdmin()
+	if err != nil {
+		return err
+	}
+	ctx = context.WithValue(ctx, "user", admin)
+	if obj == nil {
+		obj, err = fs.Get(ctx, path, &fs.GetArgs{NoLog: true})
+		if err != nil {
+			return errors.WithMessage(err, "failed get changed object")
+		}
+	}
+	if !obj.IsDir() {
+		return Index(ctx, stdpath.Dir(path), obj)
+	}
+	var objs []ObjWithParent
+	walkFn := func(reqPath string, info model.Obj) error {
+		if isIgnorePath(reqPath) {
+			return filepath.SkipDir
+		}
+		objs = append(objs, ObjWithParent{
+			Parent: stdpath.Dir(reqPath),
+			Obj:    info,
+		})
+		return nil
+	}
+	maxDepth := setting.GetInt(conf.MaxIndexDepth, 20) - strings.Count(path, "/")
+	if err = fs.WalkFS(ctx, maxDepth, path, obj, walkFn); err != nil {
+		return err
+	}
+	return BatchIndex(ctx, objs)
+}
+
+func init() {
+	op.RegisterObjChangeHook(onObjChange)
+	go applyObjChanges()
+}
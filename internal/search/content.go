@@ -0,0 +1,57 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/search/content"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// contentOf returns the text of obj at path to index, it's empty if the content is not indexed
+// or failed to extract, the failures are only logged so the obj is still indexed
+func contentOf(ctx context.Context, path string, obj model.Obj) string {
+	if obj.IsDir() || instance == nil || !instance.Config().IndexContent || !setting.GetBool(conf.IndexContent) {
+		return ""
+	}
+	if obj.GetSize() > int64(setting.GetInt(conf.IndexContentMaxSize, 10))*1024*1024 || !content.Supported(obj.GetName()) {
+		return ""
+	}
+	includePaths := splitPaths(setting.GetStr(conf.IndexContentIncludePaths))
+	if len(includePaths) > 0 && !inPaths(path, includePaths) {
+		return ""
+	}
+	if inPaths(path, splitPaths(setting.GetStr(conf.IndexContentExcludePaths))) {
+		return ""
+	}
+	text, err := content.Extract(ctx, path, obj)
+	if err != nil {
+		log.Warnf("failed extract content of %s: %+v", path, err)
+		return ""
+	}
+	return text
+}
+
+// splitPaths splits the paths of a setting with one path per line
+func splitPaths(value string) []string {
+	paths := make([]string, 0)
+	for _, path := range strings.Split(value, "\n") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, utils.FixAndCleanPath(path))
+		}
+	}
+	return paths
+}
+
+func inPaths(path string, paths []string) bool {
+	for _, p := range paths {
+		if utils.IsSubPath(p, path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+// Package content extracts the plain text of the text and office documents,
+// the text is indexed by the searchers which support searching in the contents.
+package content
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	// the text of a document is truncated to it
+	maxTextLength = 1024 * 1024
+	pdfTimeout    = time.Minute
+)
+
+// Supported returns whether the text of the file named name can be extracted,
+// pdf is supported only if pdftotext of poppler is installed
+func Supported(name string) bool {
+	switch utils.Ext(name) {
+	case "docx":
+		return true
+	case "pdf":
+		return pdftotextAvailable()
+	}
+	return utils.GetFileType(name) == conf.TEXT
+}
+
+var (
+	pdftotextOnce  sync.Once
+	pdftotextFound bool
+)
+
+// pdftotextAvailable looks up pdftotext once, so alist needs a restart to use a pdftotext installed later
+func pdftotextAvailable() bool {
+	pdftotextOnce.Do(func() {
+		_, err := exec.LookPath("pdftotext")
+		pdftotextFound = err == nil
+	})
+	return pdftotextFound
+}
+
+// Extract reads the whole file at path through its link and returns its plain text
+func Extract(ctx context.Context, path string, obj model.Obj) (string, error) {
+	link, _, err := fs.Link(ctx, path, model.LinkArgs{})
+	if err != nil {
+		return "", err
+	}
+	data, err := stream.ReadLink(ctx, link, obj.GetSize(), obj.GetSize())
+	if err != nil {
+		return "", err
+	}
+	var text string
+	switch utils.Ext(obj.GetName()) {
+	case "docx":
+		text, err = docx(data)
+	case "pdf":
+		text, err = pdf(ctx, data)
+	default:
+		text = string(data)
+	}
+	if err != nil {
+		return "", err
+	}
+	return clean(text), nil
+}
+
+// docx takes the text in the runs of the main document part
+func docx(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	f, err := zr.Open("word/document.xml")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer f.Close()
+	var (
+		sb     strings.Builder
+		inText bool
+	)
+	d := xml.NewDecoder(f)
+	for sb.Len() < maxTextLength {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteByte('\t')
+			case "br":
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// pdf converts the document with pdftotext, which needs a file to seek in
+func pdf(ctx context.Context, data []byte) (string, error) {
+	f, err := os.CreateTemp(conf.Conf.TempDir, "content-*.pdf")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, pdfTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "pdftotext", "-q", "-enc", "UTF-8", f.Name(), "-").Output()
+	if err != nil {
+		return "", errors.Wrap(err, "failed convert pdf with pdftotext")
+	}
+	return string(out), nil
+}
+
+// clean drops the invalid and null characters and truncates the text
+func clean(text string) string {
+	if len(text) > maxTextLength {
+		text = text[:maxTextLength]
+	}
+	text = strings.ToValidUTF8(text, "")
+	return strings.ReplaceAll(text, "\x00", "")
+}
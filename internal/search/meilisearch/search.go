@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/meilisearch/meilisearch-go"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,6 +17,51 @@ import (
 type searchDocument struct {
 	ID string `json:"id"`
 	model.SearchNode
+	// the fields to filter and sort by, meilisearch only compares numbers
+	Ext          string   `json:"ext"`
+	ModifiedUnix int64    `json:"modified_unix"`
+	Parents      []string `json:"parents"`
+	Content      string   `json:"content,omitempty"`
+}
+
+// the fields of the documents read back, the content is left out
+var documentFields = []string{"id", "parent", "name", "is_dir", "size", "modified", "category"}
+
+func toSearchDocument(node model.SearchNode) *searchDocument {
+	parents := []string{node.Parent}
+	for p := node.Parent; p != "/" && p != ""; {
+		p = path.Dir(p)
+		parents = append(parents, p)
+	}
+	return &searchDocument{
+		ID:           uuid.NewString(),
+		SearchNode:   node,
+		Ext:          utils.Ext(node.Name),
+		ModifiedUnix: node.Modified.Unix(),
+		Parents:      parents,
+		Content:      node.Content,
+	}
+}
+
+func toSearchNode(src map[string]any) model.SearchNode {
+	node := model.SearchNode{
+		Parent: src["parent"].(string),
+		Name:   src["name"].(string),
+		IsDir:  src["is_dir"].(bool),
+		Size:   int64(src["size"].(float64)),
+	}
+	// the fields are absent in the documents indexed before they are added
+	if modified, ok := src["modified"].(string); ok {
+		node.Modified, _ = time.Parse(time.RFC3339Nano, modified)
+	}
+	if category, ok := src["category"].(float64); ok {
+		node.Category = int(category)
+	}
+	return node
+}
+
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "\\'") + "'"
 }
 
 type Meilisearch struct {
@@ -23,6 +69,7 @@ type Meilisearch struct {
 	IndexUid             string
 	FilterableAttributes []string
 	SearchableAttributes []string
+	SortableAttributes   []string
 }
 
 func (m *Meilisearch) Config() searcher.Config {
@@ -32,24 +79,61 @@ func (m *Meilisearch) Config() searcher.Config {
 func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	mReq := &meilisearch.SearchRequest{
 		AttributesToSearchOn: m.SearchableAttributes,
+		AttributesToRetrieve: documentFields,
 		Page:                 int64(req.Page),
 		HitsPerPage:          int64(req.PerPage),
 	}
+	var filters []string
+	if req.Parent != "/" {
+		filters = append(filters, fmt.Sprintf("parents = %s", quote(req.Parent)))
+	}
 	if req.Scope != 0 {
-		mReq.Filter = fmt.Sprintf("is_dir = %v", req.Scope == 1)
+		filters = append(filters, fmt.Sprintf("is_dir = %v", req.Scope == 1))
+	}
+	if len(req.Exts) > 0 {
+		exts, _ := utils.SliceConvert(req.Exts, func(src string) (string, error) {
+			return quote(src), nil
+		})
+		filters = append(filters, fmt.Sprintf("ext IN [%s]", strings.Join(exts, ",")))
+	}
+	if len(req.Categories) > 0 {
+		categories, _ := utils.SliceConvert(req.Categories, func(src int) (string, error) {
+			return strconv.Itoa(src), nil
+		})
+		filters = append(filters, fmt.Sprintf("category IN [%s]", strings.Join(categories, ",")))
+	}
+	if req.MinSize > 0 {
+		filters = append(filters, fmt.Sprintf("size >= %d", req.MinSize))
+	}
+	if req.MaxSize > 0 {
+		filters = append(filters, fmt.Sprintf("size <= %d", req.MaxSize))
+	}
+	if req.ModifiedFrom != nil {
+		filters = append(filters, fmt.Sprintf("modified_unix >= %d", req.ModifiedFrom.Unix()))
+	}
+	if req.ModifiedTo != nil {
+		filters = append(filters, fmt.Sprintf("modified_unix <= %d", req.ModifiedTo.Unix()))
+	}
+	if len(filters) > 0 {
+		mReq.Filter = strings.Join(filters, " AND ")
+	}
+	if req.OrderBy != model.SearchOrderByRelevance {
+		field := req.OrderBy
+		if field == model.SearchOrderByModified {
+			field = "modified_unix"
+		}
+		direction := "asc"
+		if req.Desc() {
+			direction = "desc"
+		}
+		mReq.Sort = []string{field + ":" + direction}
 	}
 	search, err := m.Client.Index(m.IndexUid).Search(req.Keywords, mReq)
 	if err != nil {
 		return nil, 0, err
 	}
 	nodes, err := utils.SliceConvert(search.Hits, func(src any) (model.SearchNode, error) {
-		srcMap := src.(map[string]any)
-		return model.SearchNode{
-			Parent: srcMap["parent"].(string),
-			Name:   srcMap["name"].(string),
-			IsDir:  srcMap["is_dir"].(bool),
-			Size:   int64(srcMap["size"].(float64)),
-		}, nil
+		return toSearchNode(src.(map[string]any)), nil
 	})
 	if err != nil {
 		return nil, 0, err
@@ -63,11 +147,7 @@ func (m *Meilisearch) Index(ctx context.Context, node model.SearchNode) error {
 
 func (m *Meilisearch) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
 	documents, _ := utils.SliceConvert(nodes, func(src model.SearchNode) (*searchDocument, error) {
-
-		return &searchDocument{
-			ID:         uuid.NewString(),
-			SearchNode: src,
-		}, nil
+		return toSearchDocument(src), nil
 	})
 
 	_, err := m.Client.Index(m.IndexUid).AddDocuments(documents)
@@ -92,21 +172,17 @@ func (m *Meilisearch) BatchIndex(ctx context.Context, nodes []model.SearchNode)
 func (m *Meilisearch) getDocumentsByParent(ctx context.Context, parent string) ([]*searchDocument, error) {
 	var result meilisearch.DocumentsResult
 	err := m.Client.Index(m.IndexUid).GetDocuments(&meilisearch.DocumentsQuery{
-		Filter: fmt.Sprintf("parent = '%s'", strings.ReplaceAll(parent, "'", "\\'")),
+		Filter: fmt.Sprintf("parent = %s", quote(parent)),
 		Limit:  int64(model.MaxInt),
+		Fields: documentFields,
 	}, &result)
 	if err != nil {
 		return nil, err
 	}
 	return utils.SliceConvert(result.Results, func(src map[string]any) (*searchDocument, error) {
 		return &searchDocument{
-			ID: src["id"].(string),
-			SearchNode: model.SearchNode{
-				Parent: src["parent"].(string),
-				Name:   src["name"].(string),
-				IsDir:  src["is_dir"].(bool),
-				Size:   int64(src["size"].(float64)),
-			},
+			ID:         src["id"].(string),
+			SearchNode: toSearchNode(src),
 		}, nil
 	})
 }
@@ -150,9 +226,7 @@ func (m *Meilisearch) DelDirChild(ctx context.Context, prefix string) error {
 	if err != nil {
 		return err
 	}
-	utils.SliceReplace(dfs, func(src string) string {
-		return "'" + strings.ReplaceAll(src, "'", "\\'") + "'"
-	})
+	utils.SliceReplace(dfs, quote)
 	s := fmt.Sprintf("parent IN [%s]", strings.Join(dfs, ","))
 	task, err := m.Client.Index(m.IndexUid).DeleteDocumentsByFilter(s)
 	if err != nil {
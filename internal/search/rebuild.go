@@ -0,0 +1,75 @@
+package search
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	rebuildMu   sync.Mutex
+	rebuildCron *cron.Cron
+)
+
+// ScheduleRebuild (re)starts the cron rebuilding the index of the rebuild paths every interval hours,
+// the paths are read on each run
+func ScheduleRebuild(interval int) {
+	rebuildMu.Lock()
+	defer rebuildMu.Unlock()
+	if rebuildCron != nil {
+		rebuildCron.Stop()
+		rebuildCron = nil
+	}
+	if interval <= 0 {
+		return
+	}
+	rebuildCron = cron.NewCron(time.Duration(interval) * time.Hour)
+	rebuildCron.Do(func() {
+		paths := splitPaths(setting.GetStr(conf.IndexRebuildPaths))
+		if len(paths) == 0 {
+			return
+		}
+		if err := Rebuild(context.Background(), paths); err != nil {
+			log.Errorf("failed rebuild index of %+v: %+v", paths, err)
+		}
+	})
+}
+
+// Rebuild drops the index under paths and builds it again,
+// the index of the other paths is untouched
+func Rebuild(ctx context.Context, paths []string) error {
+	if instance == nil {
+		return errs.SearchNotAvailable
+	}
+	if Running.Load() {
+		return errors.New("index is running")
+	}
+	for _, path := range paths {
+		if err := instance.Del(ctx, path); err != nil {
+			return errors.WithMessagef(err, "failed del index of %s", path)
+		}
+	}
+	return BuildIndex(ctx, paths, conf.SlicesMap[conf.IgnorePaths], setting.GetInt(conf.MaxIndexDepth, 20), false)
+}
+
+func init() {
+	// the hook is called before the item is saved
+	op.RegisterSettingItemHook(conf.IndexRebuildInterval, func(item *model.SettingItem) error {
+		interval, err := strconv.Atoi(item.Value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", conf.IndexRebuildInterval)
+		}
+		ScheduleRebuild(interval)
+		return nil
+	})
+}
@@ -3,12 +3,14 @@ package search
 import (
 	"context"
 	"fmt"
+	"path"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -55,12 +57,19 @@ func Index(ctx context.Context, parent string, obj model.Obj) error {
 	if instance == nil {
 		return errs.SearchNotAvailable
 	}
-	return instance.Index(ctx, model.SearchNode{
-		Parent: parent,
-		Name:   obj.GetName(),
-		IsDir:  obj.IsDir(),
-		Size:   obj.GetSize(),
-	})
+	return instance.Index(ctx, toSearchNode(ctx, parent, obj))
+}
+
+func toSearchNode(ctx context.Context, parent string, obj model.Obj) model.SearchNode {
+	return model.SearchNode{
+		Parent:   parent,
+		Name:     obj.GetName(),
+		IsDir:    obj.IsDir(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		Category: utils.GetObjType(obj.GetName(), obj.IsDir()),
+		Content:  contentOf(ctx, path.Join(parent, obj.GetName()), obj),
+	}
 }
 
 type ObjWithParent struct {
@@ -77,12 +86,7 @@ func BatchIndex(ctx context.Context, objs []ObjWithParent) error {
 	}
 	var searchNodes []model.SearchNode
 	for i := range objs {
-		searchNodes = append(searchNodes, model.SearchNode{
-			Parent: objs[i].Parent,
-			Name:   objs[i].GetName(),
-			IsDir:  objs[i].IsDir(),
-			Size:   objs[i].GetSize(),
-		})
+		searchNodes = append(searchNodes, toSearchNode(ctx, objs[i].Parent, objs[i].Obj))
 	}
 	return instance.BatchIndex(ctx, searchNodes)
 }
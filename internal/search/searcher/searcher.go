@@ -9,6 +9,8 @@ import (
 type Config struct {
 	Name       string
 	AutoUpdate bool
+	// the contents of the documents can be indexed and searched
+	IndexContent bool
 }
 
 type Searcher interface {
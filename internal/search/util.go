@@ -7,6 +7,7 @@ import (
 	"github.com/alist-org/alist/v3/drivers/base"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
@@ -73,7 +74,11 @@ func updateIgnorePaths() {
 	conf.SlicesMap[conf.IgnorePaths] = ignorePaths
 }
 
+// isIgnorePath checks whether the path is ignored, the removed files in the recycle bins are never indexed
 func isIgnorePath(path string) bool {
+	if fs.IsTrashPath(path) {
+		return true
+	}
 	for _, ignorePath := range conf.SlicesMap[conf.IgnorePaths] {
 		if strings.HasPrefix(path, ignorePath) {
 			return true
@@ -0,0 +1,43 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestIgnoreTrash(t *testing.T) {
+	_, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: "/search_trash",
+		Addition: `{"root_folder_path":"` + filepath.ToSlash(t.TempDir()) + `"}`})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	for path, ignored := range map[string]bool{
+		"/search_trash/.alist_trash":            true,
+		"/search_trash/.alist_trash/1/file.txt": true,
+		"/search_trash/file.txt":                false,
+		"/search_trash/dir/.alist_trash":        false,
+	} {
+		if isIgnorePath(path) != ignored {
+			t.Errorf("%s should be ignored: %v", path, ignored)
+		}
+	}
+}
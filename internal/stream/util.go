@@ -10,6 +10,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/net"
 	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -86,3 +87,26 @@ func checkContentRange(header *http.Header, offset int64) bool {
 	}
 	return false
 }
+
+// ReadLink reads the first length bytes of the file of the link, size is the size of the file
+func ReadLink(ctx context.Context, link *model.Link, size, length int64) ([]byte, error) {
+	if link.MFile != nil {
+		defer link.MFile.Close()
+		return io.ReadAll(io.LimitReader(link.MFile, length))
+	}
+	rrc := link.RangeReadCloser
+	if rrc == nil {
+		var err error
+		if rrc, err = GetRangeReadCloserFromLink(size, link); err != nil {
+			return nil, err
+		}
+	}
+	defer rrc.Close()
+	rc, err := rrc.RangeRead(ctx, http_range.Range{Start: 0, Length: length})
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, length))
+	return data, errors.WithStack(err)
+}
@@ -0,0 +1,45 @@
+package task
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+// Creator records the user who creates a task, it's embedded in the tasks.
+// The json keys are the same as the user_id of the offline download tasks saved before.
+type Creator struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+}
+
+func (c *Creator) GetCreatorID() uint {
+	return c.UserID
+}
+
+func (c *Creator) GetCreatorName() string {
+	return c.Username
+}
+
+// WithCreator is the task which records its creator
+type WithCreator interface {
+	GetCreatorID() uint
+	GetCreatorName() string
+}
+
+// CreatorFromCtx is the user in ctx, the creator is empty if the task is not created by a user
+func CreatorFromCtx(ctx context.Context) Creator {
+	user, ok := ctx.Value("user").(*model.User)
+	if !ok || user == nil {
+		return Creator{}
+	}
+	return Creator{UserID: user.ID, Username: user.Username}
+}
+
+// CreatorIDOf is the id of the creator of t, 0 if it's unknown
+func CreatorIDOf(t any) uint {
+	if c, ok := t.(WithCreator); ok {
+		return c.GetCreatorID()
+	}
+	return 0
+}
@@ -0,0 +1,195 @@
+package task
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/xhofe/tache"
+)
+
+const persistInterval = 5 * time.Second
+
+// FinishedStates are the states of tasks which will never run again
+var FinishedStates = []int{tache.StateSucceeded, tache.StateCanceled, tache.StateFailed}
+
+// UnfinishedStates are the states of tasks which should be resumed after restart
+var UnfinishedStates = []int{tache.StatePending, tache.StateRunning, tache.StateCanceling, tache.StateErrored,
+	tache.StateFailing, tache.StateWaitingRetry, tache.StateBeforeRetry}
+
+// runningStates are the states of tasks whose fields are being changed by their Run,
+// they are saved before and after running instead, a running task starts over after restart anyway
+var runningStates = []int{tache.StateRunning, tache.StateCanceling, tache.StateBeforeRetry, tache.StateFailing}
+
+type persister interface {
+	save()
+}
+
+var (
+	persistersMu sync.Mutex
+	persisters   []persister
+	persistCron  *cron.Cron
+)
+
+type managerPersister[T tache.TaskWithInfo] struct {
+	typ     string
+	manager *tache.Manager[T]
+	mu      sync.Mutex
+	saved   map[string]model.TaskItem
+}
+
+// Persist keeps the tasks of manager in the db with the type typ.
+// The unfinished tasks of the last run are added back to manager
+// once the storages are loaded, newTask creates an empty task to decode them into.
+func Persist[T tache.TaskWithInfo](typ string, manager *tache.Manager[T], newTask func() T) {
+	p := &managerPersister[T]{
+		typ:     typ,
+		manager: manager,
+		saved:   make(map[string]model.TaskItem),
+	}
+	persistersMu.Lock()
+	persisters = append(persisters, p)
+	if persistCron == nil {
+		persistCron = cron.NewCron(persistInterval)
+		persistCron.Do(saveAll)
+	}
+	persistersMu.Unlock()
+	go func() {
+		for !conf.StoragesLoaded {
+			time.Sleep(time.Second)
+		}
+		p.recover(newTask)
+	}()
+}
+
+func (p *managerPersister[T]) recover(newTask func() T) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	items, err := db.GetTaskItemsByState(p.typ, UnfinishedStates...)
+	if err != nil {
+		log.Errorf("failed get unfinished %s tasks: %+v", p.typ, err)
+		return
+	}
+	for _, item := range items {
+		t := newTask()
+		err := utils.Json.UnmarshalFromString(item.Data, t)
+		if err == nil {
+			if r, ok := tache.Task(t).(tache.Recoverable); ok && !r.Recoverable() {
+				err = fmt.Errorf("the task is interrupted and cannot be recovered")
+			}
+		}
+		if err != nil {
+			item.State = tache.StateFailed
+			item.Error = err.Error()
+			if err := db.SaveTaskItem(&item); err != nil {
+				log.Errorf("failed save %s task [%s]: %+v", p.typ, item.ID, err)
+			}
+			continue
+		}
+		// errored tasks are only queued again when they are waiting for retry
+		if t.GetState() == tache.StateErrored {
+			t.SetState(tache.StateWaitingRetry)
+		}
+		p.saved[item.ID] = item
+		p.manager.Add(t)
+		log.Infof("recovered %s task: %s", p.typ, t.GetName())
+	}
+}
+
+func (p *managerPersister[T]) save() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	alive := make(map[string]struct{})
+	for _, t := range p.manager.GetAll() {
+		id := t.GetID()
+		alive[id] = struct{}{}
+		if utils.SliceContains(runningStates, int(t.GetState())) {
+			continue
+		}
+		item := p.toItem(t)
+		old, ok := p.saved[id]
+		if ok {
+			item.CreatedAt = old.CreatedAt
+			item.UpdatedAt = old.UpdatedAt
+			if item == old {
+				continue
+			}
+		} else {
+			item.CreatedAt = time.Now()
+		}
+		if err := db.SaveTaskItem(&item); err != nil {
+			log.Errorf("failed save %s task [%s]: %+v", p.typ, id, err)
+			continue
+		}
+		p.saved[id] = item
+	}
+	// the finished tasks removed from manager are kept as history,
+	// but the unfinished ones must not be resumed after restart
+	for id, item := range p.saved {
+		if _, ok := alive[id]; ok {
+			continue
+		}
+		delete(p.saved, id)
+		if utils.SliceContains(FinishedStates, item.State) {
+			continue
+		}
+		item.State = tache.StateCanceled
+		item.Error = "task removed"
+		if err := db.SaveTaskItem(&item); err != nil {
+			log.Errorf("failed save %s task [%s]: %+v", p.typ, id, err)
+		}
+	}
+}
+
+func (p *managerPersister[T]) toItem(t T) model.TaskItem {
+	errMsg := ""
+	if t.GetErr() != nil {
+		errMsg = t.GetErr().Error()
+	}
+	progress := t.GetProgress()
+	if math.IsNaN(progress) {
+		progress = 100
+	}
+	data, err := utils.Json.MarshalToString(t)
+	if err != nil {
+		log.Errorf("failed marshal %s task [%s]: %+v", p.typ, t.GetID(), err)
+	}
+	return model.TaskItem{
+		ID:       t.GetID(),
+		Type:     p.typ,
+		UserID:   CreatorIDOf(t),
+		Name:     t.GetName(),
+		State:    int(t.GetState()),
+		Status:   t.GetStatus(),
+		Progress: progress,
+		Error:    errMsg,
+		Data:     data,
+	}
+}
+
+func saveAll() {
+	persistersMu.Lock()
+	defer persistersMu.Unlock()
+	for _, p := range persisters {
+		p.save()
+	}
+}
+
+// Release saves all the tasks at once and stops saving them,
+// it should be called before the db is closed
+func Release() {
+	persistersMu.Lock()
+	if persistCron != nil {
+		persistCron.Stop()
+		persistCron = nil
+	}
+	persistersMu.Unlock()
+	saveAll()
+}
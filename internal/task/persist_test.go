@@ -0,0 +1,137 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/xhofe/tache"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+type testTask struct {
+	tache.Base
+	Creator
+	Name string `json:"name"`
+	// the task which streams from memory can't be recovered
+	InMemory bool   `json:"in_memory"`
+	Status   string `json:"status"`
+	// Run closes started and waits for block if they are set
+	started chan struct{}
+	block   chan struct{}
+}
+
+func (t *testTask) GetName() string {
+	return t.Name
+}
+
+func (t *testTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *testTask) Run() error {
+	t.Status = "running"
+	if t.block != nil {
+		close(t.started)
+		<-t.block
+	}
+	t.Status = "done"
+	return nil
+}
+
+func (t *testTask) Recoverable() bool {
+	return !t.InMemory
+}
+
+func newPersister(typ string) *managerPersister[*testTask] {
+	return &managerPersister[*testTask]{
+		typ: typ,
+		// the tasks are kept pending
+		manager: tache.NewManager[*testTask](tache.WithRunning(false)),
+		saved:   make(map[string]model.TaskItem),
+	}
+}
+
+func TestPersist(t *testing.T) {
+	p := newPersister("persist")
+	copying := &testTask{Name: "copy", Creator: Creator{UserID: 2, Username: "user"}}
+	uploading := &testTask{Name: "upload", InMemory: true}
+	removed := &testTask{Name: "removed"}
+	for _, task := range []*testTask{copying, uploading, removed} {
+		p.manager.Add(task)
+	}
+	p.save()
+	p.manager.Remove(removed.GetID())
+	p.save()
+
+	items, err := db.GetTaskItemsByState("persist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := make(map[string]model.TaskItem)
+	for _, item := range items {
+		states[item.Name] = item
+	}
+	if len(items) != 3 || states["copy"].UserID != 2 || states["copy"].State != tache.StatePending {
+		t.Fatalf("the tasks should be saved with their creators, got %+v", items)
+	}
+	if states["removed"].State != tache.StateCanceled {
+		t.Errorf("the unfinished task removed from the manager should be canceled, got %d", states["removed"].State)
+	}
+
+	// the tasks of the last run are recovered after restart
+	recovered := newPersister("persist")
+	recovered.recover(func() *testTask {
+		return &testTask{}
+	})
+	tasks := recovered.manager.GetAll()
+	if len(tasks) != 1 || tasks[0].GetID() != copying.GetID() || tasks[0].Name != "copy" || tasks[0].UserID != 2 {
+		t.Fatalf("only the recoverable unfinished task should be recovered, got %d tasks", len(tasks))
+	}
+	items, err = db.GetTaskItemsByState("persist", tache.StateFailed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Name != "upload" || items[0].Error == "" {
+		t.Errorf("the task which can't be recovered should fail, got %+v", items)
+	}
+}
+
+func TestPersistSkipsRunning(t *testing.T) {
+	p := newPersister("persist_running")
+	p.manager = tache.NewManager[*testTask]()
+	running := &testTask{Name: "running", started: make(chan struct{}), block: make(chan struct{})}
+	p.manager.Add(running)
+	<-running.started
+	p.save()
+	items, err := db.GetTaskItemsByState("persist_running")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 {
+		t.Errorf("the running task shouldn't be marshalled, got %+v", items)
+	}
+
+	close(running.block)
+	p.manager.Wait()
+	p.save()
+	items, err = db.GetTaskItemsByState("persist_running", tache.StateSucceeded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Status != "done" {
+		t.Errorf("the task should be saved after running, got %+v", items)
+	}
+}
@@ -0,0 +1,274 @@
+// Package thumb generates the thumbnails of the images and videos in any storage
+// with ranged reads of their links, the thumbnails are cached on disk.
+package thumb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/disintegration/imaging"
+	"github.com/pkg/errors"
+	ffmpeg "github.com/u2takey/ffmpeg-go"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	width        = 256
+	videoTimeout = time.Minute
+	// generateTimeout bounds a generation, which isn't canceled by the clients waiting for it
+	generateTimeout = 3 * time.Minute
+	// maxPixels the decoded image takes 4 bytes per pixel, so the larger images are rejected
+	// before decoding, a small png or gif can have huge dimensions
+	maxPixels = 40 * 1000 * 1000
+	// failures are not retried for a while, as the generation costs a lot of traffic
+	failureCacheTime = time.Hour
+)
+
+// the image formats which can be decoded
+var imageExts = []string{"jpg", "jpeg", "png", "gif", "bmp", "tif", "tiff", "webp"}
+
+var (
+	thumbG   singleflight.Group[string]
+	failures = cache.NewMemCache(cache.WithShards[error](16))
+
+	limitMu sync.Mutex
+	limit   chan struct{}
+)
+
+// Enabled returns whether alist generates the thumbnails
+func Enabled() bool {
+	return setting.GetInt(conf.ThumbnailConcurrency, 2) > 0
+}
+
+// Supported returns whether a thumbnail can be generated for the file named name
+func Supported(name string) bool {
+	if !Enabled() {
+		return false
+	}
+	if utils.SliceContains(imageExts, utils.Ext(name)) {
+		return true
+	}
+	return utils.GetFileType(name) == conf.VIDEO && ffmpegAvailable()
+}
+
+var (
+	ffmpegOnce  sync.Once
+	ffmpegFound bool
+)
+
+// ffmpegAvailable looks up ffmpeg once, so alist needs a restart to use an ffmpeg installed later
+func ffmpegAvailable() bool {
+	ffmpegOnce.Do(func() {
+		_, err := exec.LookPath("ffmpeg")
+		ffmpegFound = err == nil
+	})
+	return ffmpegFound
+}
+
+// Sign signs the thumbnail url of the file at path, the sign has its own scope,
+// so it can't be used to download the file from /d/
+func Sign(path string) string {
+	return sign.Sign(signData(path))
+}
+
+// Verify verifies the sign of the thumbnail url of the file at path
+func Verify(path, s string) error {
+	return sign.Verify(signData(path), s)
+}
+
+func signData(path string) string {
+	return "t:" + utils.FixAndCleanPath(path)
+}
+
+// Get returns the local path of the thumbnail of the file at path, it's generated if it's not cached
+func Get(ctx context.Context, path string) (string, error) {
+	obj, err := fs.Get(ctx, path, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return "", err
+	}
+	if obj.IsDir() || !Supported(obj.GetName()) {
+		return "", errors.WithStack(errs.NotSupport)
+	}
+	key := utils.GetMD5EncodeStr(fmt.Sprintf("%s:%d:%d", utils.FixAndCleanPath(path), obj.ModTime().Unix(), obj.GetSize()))
+	cachePath := filepath.Join(conf.Conf.ThumbDir, key[:2], key+".jpg")
+	if utils.Exists(cachePath) {
+		return cachePath, nil
+	}
+	if err, ok := failures.Get(key); ok {
+		return "", err
+	}
+	ch := thumbG.DoChan(key, func() (string, error) {
+		// the generation is shared by all the clients waiting for it, so it doesn't stop if one of them leaves
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), generateTimeout)
+		defer cancel()
+		release, err := acquire(ctx)
+		if err != nil {
+			return "", err
+		}
+		defer release()
+		data, err := generate(ctx, path, obj)
+		if err != nil {
+			err = errors.WithMessagef(err, "failed generate thumbnail of %s", path)
+			if !utils.IsCanceled(ctx) {
+				failures.Set(key, err, cache.WithEx[error](failureCacheTime))
+			}
+			return "", err
+		}
+		if err := save(cachePath, data); err != nil {
+			return "", err
+		}
+		return cachePath, nil
+	})
+	select {
+	case res := <-ch:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// acquire waits for a slot of the generation, the number of slots is the thumbnail_concurrency setting
+func acquire(ctx context.Context) (release func(), err error) {
+	n := setting.GetInt(conf.ThumbnailConcurrency, 2)
+	limitMu.Lock()
+	if limit == nil || cap(limit) != n {
+		limit = make(chan struct{}, n)
+	}
+	ch := limit
+	limitMu.Unlock()
+	select {
+	case ch <- struct{}{}:
+		return func() { <-ch }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// save writes the thumbnail to a temp file first, so a thumbnail being written is never served
+func save(cachePath string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0777); err != nil {
+		return errors.WithStack(err)
+	}
+	tmp := cachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0666); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmp, cachePath))
+}
+
+func generate(ctx context.Context, path string, obj model.Obj) ([]byte, error) {
+	maxSize := int64(setting.GetInt(conf.ThumbnailMaxSize, 20)) * 1024 * 1024
+	link, _, err := fs.Link(ctx, path, model.LinkArgs{})
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if utils.SliceContains(imageExts, utils.Ext(obj.GetName())) {
+		// the whole image is needed to decode it
+		if obj.GetSize() > maxSize {
+			return nil, errors.Errorf("the image is larger than %d bytes", maxSize)
+		}
+		data, err = stream.ReadLink(ctx, link, obj.GetSize(), obj.GetSize())
+	} else {
+		data, err = snapshot(ctx, link, obj.GetSize(), maxSize)
+	}
+	if err != nil {
+		return nil, err
+	}
+	img, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return encode(img)
+}
+
+// decode checks the dimensions of the image before decoding it
+func decode(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, errors.Errorf("the image of %dx%d is larger than %d pixels", cfg.Width, cfg.Height, maxPixels)
+	}
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	return img, errors.WithStack(err)
+}
+
+func encode(img image.Image) ([]byte, error) {
+	if img.Bounds().Dx() > width {
+		img = imaging.Resize(img, width, 0, imaging.Lanczos)
+	}
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, imaging.JPEG, imaging.JPEGQuality(80)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// snapshot takes a frame of the video with ffmpeg, which reads the local file or the url with ranged requests itself,
+// otherwise the beginning of the video is piped to it.
+func snapshot(ctx context.Context, link *model.Link, size, maxSize int64) ([]byte, error) {
+	var (
+		input string
+		args  = ffmpeg.KwArgs{}
+		stdin io.Reader
+	)
+	if f, ok := link.MFile.(*os.File); ok {
+		_ = f.Close()
+		input = f.Name()
+	} else if link.URL != "" {
+		input = link.URL
+		if len(link.Header) > 0 {
+			var headers strings.Builder
+			for k, vs := range link.Header {
+				for _, v := range vs {
+					headers.WriteString(k + ": " + v + "\r\n")
+				}
+			}
+			args["headers"] = headers.String()
+		}
+	} else {
+		data, err := stream.ReadLink(ctx, link, size, utils.Min(size, maxSize))
+		if err != nil {
+			return nil, err
+		}
+		input = "pipe:"
+		stdin = bytes.NewReader(data)
+	}
+	var out bytes.Buffer
+	s := ffmpeg.Input(input, args).Filter("select", ffmpeg.Args{"gte(n,10)"}).
+		Output("pipe:", ffmpeg.KwArgs{"vframes": 1, "format": "image2", "vcodec": "mjpeg"}).
+		WithOutput(&out).
+		WithTimeout(videoTimeout).
+		Silent(true)
+	if stdin != nil {
+		s = s.WithInput(stdin)
+	}
+	if err := s.Run(); err != nil {
+		return nil, errors.Wrap(err, "failed take a snapshot with ffmpeg")
+	}
+	if out.Len() == 0 {
+		return nil, errors.New("no frame is taken from the video")
+	}
+	return out.Bytes(), nil
+}
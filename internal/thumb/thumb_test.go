@@ -0,0 +1,30 @@
+package thumb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestDecodeMaxPixels(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if _, err := decode(data); err != nil {
+		t.Fatalf("failed decode a small image: %+v", err)
+	}
+	// the IHDR chunk follows the 8 bytes signature, its data is after the length and the type
+	ihdr := data[8+8 : 8+8+13]
+	binary.BigEndian.PutUint32(ihdr[0:4], 100000)
+	binary.BigEndian.PutUint32(ihdr[4:8], 100000)
+	binary.BigEndian.PutUint32(data[8+8+13:], crc32.ChecksumIEEE(data[8+4:8+8+13]))
+	if _, err := decode(data); err == nil || !strings.Contains(err.Error(), "pixels") {
+		t.Errorf("an image of 100000x100000 should be rejected before decoding, got %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+// Package webhook sends the file, task and storage events to the webhooks as signed json posts
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxRetry = 3
+	maxBackoff      = 5 * time.Minute
+	maxResponseLen  = 1024
+	// deliveryRetention is how long the delivery logs are kept
+	deliveryRetention = 30 * 24 * time.Hour
+)
+
+var (
+	client     *resty.Client
+	clientOnce sync.Once
+)
+
+// getClient returns the client without the retries of resty, the attempts are counted by deliver
+func getClient() *resty.Client {
+	clientOnce.Do(func() {
+		client = base.NewRestyClient().SetRetryCount(0)
+	})
+	return client
+}
+
+var webhooksCache = cache.NewMemCache(cache.WithShards[[]model.Webhook](1))
+var webhooksG singleflight.Group[[]model.Webhook]
+
+func getEnabledWebhooks() ([]model.Webhook, error) {
+	if webhooks, ok := webhooksCache.Get("webhooks"); ok {
+		return webhooks, nil
+	}
+	webhooks, err, _ := webhooksG.Do("webhooks", func() ([]model.Webhook, error) {
+		_webhooks, err := db.GetEnabledWebhooks()
+		if err != nil {
+			return nil, err
+		}
+		webhooksCache.Set("webhooks", _webhooks, cache.WithEx[[]model.Webhook](time.Hour))
+		return _webhooks, nil
+	})
+	return webhooks, err
+}
+
+// Payload is the body posted to the webhooks
+type Payload struct {
+	Event string                 `json:"event"`
+	Time  time.Time              `json:"time"`
+	Data  map[string]interface{} `json:"data"`
+}
+
+func match(w *model.Webhook, event string, data map[string]interface{}) bool {
+	if len(w.Events) > 0 && !utils.SliceContains(w.Events, event) {
+		return false
+	}
+	if len(w.Paths) == 0 {
+		return true
+	}
+	path, _ := data["path"].(string)
+	if path == "" {
+		return false
+	}
+	for _, p := range w.Paths {
+		if utils.IsSubPath(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Send posts the event to the matched webhooks in the background
+func Send(event string, data map[string]interface{}) {
+	webhooks, err := getEnabledWebhooks()
+	if err != nil {
+		log.Errorf("failed get webhooks: %+v", err)
+		return
+	}
+	var body []byte
+	for i := range webhooks {
+		w := webhooks[i]
+		if !match(&w, event, data) {
+			continue
+		}
+		if body == nil {
+			body, err = utils.Json.Marshal(Payload{Event: event, Time: time.Now(), Data: data})
+			if err != nil {
+				log.Errorf("failed marshal webhook payload of %s: %+v", event, err)
+				return
+			}
+		}
+		go deliver(w, event, body)
+	}
+}
+
+// deliver posts body to w, retries with exponential backoff until it succeeds or MaxRetry is reached
+func deliver(w model.Webhook, event string, body []byte) {
+	d := &model.WebhookDelivery{
+		WebhookID: w.ID,
+		Event:     event,
+		Payload:   string(body),
+	}
+	if err := db.CreateWebhookDelivery(d); err != nil {
+		log.Errorf("failed create webhook delivery: %+v", err)
+	}
+	maxRetry := w.MaxRetry
+	if maxRetry <= 0 {
+		maxRetry = defaultMaxRetry
+	}
+	backoff := time.Second
+	for i := 0; i <= maxRetry; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+		d.Attempts++
+		d.StatusCode, d.Response, d.Error = post(&w, event, d.ID, body)
+		d.Success = d.Error == "" && d.StatusCode >= 200 && d.StatusCode < 300
+		if err := db.UpdateWebhookDelivery(d); err != nil {
+			log.Errorf("failed update webhook delivery: %+v", err)
+		}
+		if d.Success {
+			return
+		}
+	}
+	log.Warnf("failed deliver %s to webhook [%s] after %d attempts", event, w.Name, d.Attempts)
+}
+
+func post(w *model.Webhook, event string, deliveryId uint, body []byte) (int, string, string) {
+	req := getClient().R().
+		SetHeader("Content-Type", "application/json").
+		SetHeader("X-Alist-Event", event).
+		SetHeader("X-Alist-Delivery", strconv.FormatUint(uint64(deliveryId), 10)).
+		SetBody(body)
+	if w.Secret != "" {
+		req.SetHeader("X-Alist-Signature", "sha256="+Sign(w.Secret, body))
+	}
+	res, err := req.Post(w.URL)
+	if err != nil {
+		return 0, "", err.Error()
+	}
+	resp := res.String()
+	if len(resp) > maxResponseLen {
+		resp = resp[:maxResponseLen]
+	}
+	return res.StatusCode(), resp, ""
+}
+
+// Sign returns the hex hmac-sha256 of body, receivers compare it with the X-Alist-Signature header
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CleanDeliveries deletes the expired delivery logs
+func CleanDeliveries() {
+	if err := db.DeleteWebhookDeliveriesBefore(time.Now().Add(-deliveryRetention)); err != nil {
+		log.Errorf("failed clean webhook deliveries: %+v", err)
+	}
+}
+
+func GetWebhookById(id uint) (*model.Webhook, error) {
+	return db.GetWebhookById(id)
+}
+
+func GetWebhooks(pageIndex, pageSize int) ([]model.Webhook, int64, error) {
+	return db.GetWebhooks(pageIndex, pageSize)
+}
+
+func CreateWebhook(w *model.Webhook) error {
+	cleanPaths(w)
+	webhooksCache.Del("webhooks")
+	return db.CreateWebhook(w)
+}
+
+func UpdateWebhook(w *model.Webhook) error {
+	cleanPaths(w)
+	webhooksCache.Del("webhooks")
+	return db.UpdateWebhook(w)
+}
+
+func DeleteWebhookById(id uint) error {
+	webhooksCache.Del("webhooks")
+	return db.DeleteWebhookById(id)
+}
+
+func GetDeliveries(webhookId uint, pageIndex, pageSize int) ([]model.WebhookDelivery, int64, error) {
+	return db.GetWebhookDeliveries(webhookId, pageIndex, pageSize)
+}
+
+func cleanPaths(w *model.Webhook) {
+	for i := range w.Paths {
+		w.Paths[i] = utils.FixAndCleanPath(w.Paths[i])
+	}
+}
+
+func init() {
+	op.RegisterEventHook(Send)
+	op.RegisterStorageHook(func(typ string, storage driver.Driver) {
+		s := storage.GetStorage()
+		Send(model.EventStorageStatus, map[string]interface{}{
+			"path":   s.MountPath,
+			"type":   typ,
+			"status": s.Status,
+		})
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"hash"
 	"io"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/errs"
 	log "github.com/sirupsen/logrus"
@@ -223,6 +224,23 @@ func (hi HashInfo) GetHash(ht *HashType) string {
 	return hi.h[ht]
 }
 
+// Compare checks the hashes of the types both hi and other have,
+// ok is false if they have no hash type in common
+func (hi HashInfo) Compare(other HashInfo) (equal bool, ok bool) {
+	for ht, v := range hi.h {
+		if v == "" {
+			continue
+		}
+		if ov := other.h[ht]; ov != "" {
+			if !strings.EqualFold(v, ov) {
+				return false, true
+			}
+			ok = true
+		}
+	}
+	return ok, ok
+}
+
 func (hi HashInfo) Export() map[*HashType]string {
 	return hi.h
 }
@@ -64,3 +64,15 @@ func TestMultiHasher(t *testing.T) {
 
 	}
 }
+
+func TestHashInfoCompare(t *testing.T) {
+	a := NewHashInfoByMap(map[*HashType]string{MD5: "BF13FC19E5151AC57D4252E0E0F87ABE", SHA1: "3ab6"})
+	equal, ok := a.Compare(NewHashInfo(MD5, "bf13fc19e5151ac57d4252e0e0f87abe"))
+	assert.True(t, ok)
+	assert.True(t, equal)
+	equal, ok = a.Compare(NewHashInfoByMap(map[*HashType]string{MD5: "bf13fc19e5151ac57d4252e0e0f87abe", SHA1: "ffff"}))
+	assert.True(t, ok)
+	assert.False(t, equal)
+	_, ok = a.Compare(NewHashInfo(SHA256, "c839"))
+	assert.False(t, ok)
+}
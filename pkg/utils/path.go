@@ -91,6 +91,11 @@ func JoinBasePath(basePath, reqPath string) (string, error) {
 	return stdpath.Join(FixAndCleanPath(basePath), FixAndCleanPath(reqPath)), nil
 }
 
+// IsValidName checks that name is a single path element, so it can't be joined out of its dir
+func IsValidName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\\")
+}
+
 func GetFullPath(mountPath, path string) string {
 	return stdpath.Join(GetActualMountPath(mountPath), path)
 }
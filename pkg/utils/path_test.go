@@ -20,3 +20,21 @@ func TestFixAndCleanPath(t *testing.T) {
 		}
 	}
 }
+
+func TestIsValidName(t *testing.T) {
+	datas := map[string]bool{
+		"a.txt":     true,
+		"..a":       true,
+		"":          false,
+		".":         false,
+		"..":        false,
+		"../../etc": false,
+		"a/b":       false,
+		"a\\b":      false,
+	}
+	for name, valid := range datas {
+		if IsValidName(name) != valid {
+			t.Errorf("IsValidName(%q) should be %v", name, valid)
+		}
+	}
+}
@@ -6,10 +6,13 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/dlclark/regexp2"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 func IsStorageSignEnabled(rawPath string) bool {
@@ -58,6 +61,89 @@ func CanAccess(user *model.User, meta *model.Meta, reqPath string, password stri
 	return meta.Password == password
 }
 
+// CanReadPath checks the hides, the meta password and the read acl of reqPath for user
+func CanReadPath(user *model.User, reqPath string, password string) (bool, error) {
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return false, err
+	}
+	return CanAccess(user, meta, reqPath, password) &&
+		HasPathPermission(user, reqPath, model.AclRead, true), nil
+}
+
+// DecideAcl returns whether the action on reqPath is allowed by rules,
+// decided is false if no rule contains the action. The rule with the longest path
+// decides, a user rule wins over a group rule of the same path and deny wins over allow.
+func DecideAcl(rules []model.AclRule, reqPath string, action int32) (allowed bool, decided bool) {
+	best := -1
+	for _, rule := range rules {
+		if (rule.Allow|rule.Deny)&action == 0 || !utils.IsSubPath(rule.Path, reqPath) {
+			continue
+		}
+		deny := rule.Deny&action != 0
+		score := len(utils.FixAndCleanPath(rule.Path)) * 4
+		if rule.IsUser() {
+			score += 2
+		}
+		if deny {
+			score += 1
+		}
+		if score > best {
+			best, allowed = score, !deny
+		}
+	}
+	return allowed, best >= 0
+}
+
+// HasPathPermission checks the acl rules of user for the action on reqPath,
+// fallback is the result if no rule decides it, usually the global permission of user.
+// It's shared by the api, webdav and s3, so the rules apply to all of them.
+func HasPathPermission(user *model.User, reqPath string, action int32, fallback bool) bool {
+	if user.IsAdmin() {
+		return true
+	}
+	if visible, ancestor := CanSeePath(user, reqPath); !visible {
+		// the parents of the roots can be listed to reach them
+		return ancestor && action == model.AclList
+	}
+	rules, err := op.GetUserAclRules(user)
+	if err != nil {
+		log.Errorf("failed get acl rules of user [%s]: %+v", user.Username, err)
+		return false
+	}
+	if allowed, ok := DecideAcl(rules, reqPath, action); ok {
+		return allowed
+	}
+	return fallback
+}
+
+// CanSeePath checks whether reqPath is in the roots and the visible storages
+// the user gets from its groups, ancestor is true if it's a parent dir of a root.
+func CanSeePath(user *model.User, reqPath string) (visible bool, ancestor bool) {
+	if user.IsAdmin() {
+		return true, false
+	}
+	if in, ancestor := user.InRoots(reqPath); !in {
+		return false, ancestor
+	}
+	if user.StorageIds == nil {
+		return true, false
+	}
+	storage := op.GetBalancedStorage(reqPath)
+	return storage == nil || utils.SliceContains(user.StorageIds, storage.GetStorage().ID), false
+}
+
+// FilterVisibleObjs removes the objs in dirPath that the user can't see
+func FilterVisibleObjs(user *model.User, dirPath string, objs []model.Obj) []model.Obj {
+	if user.IsAdmin() || (user.Roots == nil && user.StorageIds == nil) {
+		return objs
+	}
+	return utils.SliceFilter(objs, func(obj model.Obj) bool {
+		visible, ancestor := CanSeePath(user, path.Join(dirPath, obj.GetName()))
+		return visible || ancestor
+	})
+}
+
 // ShouldProxy TODO need optimize
 // when should be proxy?
 // 1. config.MustProxy()
@@ -1,6 +1,10 @@
 package common
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
 
 func TestIsApply(t *testing.T) {
 	datas := []struct {
@@ -22,3 +26,32 @@ func TestIsApply(t *testing.T) {
 		}
 	}
 }
+
+func TestDecideAcl(t *testing.T) {
+	rules := []model.AclRule{
+		{SubjectType: model.AclSubjectGroup, Path: "/", Allow: model.AclRead | model.AclList},
+		{SubjectType: model.AclSubjectGroup, Path: "/a", Deny: model.AclRead},
+		{SubjectType: model.AclSubjectUser, Path: "/a", Allow: model.AclRead},
+		{SubjectType: model.AclSubjectUser, Path: "/a/b", Allow: model.AclWrite, Deny: model.AclWrite | model.AclList},
+	}
+	datas := []struct {
+		reqPath string
+		action  int32
+		allowed bool
+		decided bool
+	}{
+		{reqPath: "/x", action: model.AclRead, allowed: true, decided: true},
+		{reqPath: "/x", action: model.AclWrite, allowed: false, decided: false},
+		{reqPath: "/a/c", action: model.AclRead, allowed: true, decided: true},
+		{reqPath: "/ab", action: model.AclRead, allowed: true, decided: true},
+		{reqPath: "/a/b/c", action: model.AclWrite, allowed: false, decided: true},
+		{reqPath: "/a/b", action: model.AclList, allowed: false, decided: true},
+		{reqPath: "/a", action: model.AclList, allowed: true, decided: true},
+	}
+	for i, data := range datas {
+		allowed, decided := DecideAcl(rules, data.reqPath, data.action)
+		if allowed != data.allowed || decided != data.decided {
+			t.Errorf("TestDecideAcl %d failed, got %v %v", i, allowed, decided)
+		}
+	}
+}
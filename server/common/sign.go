@@ -5,8 +5,10 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/sign"
+	log "github.com/sirupsen/logrus"
 )
 
 func Sign(obj model.Obj, parent string, encrypt bool) string {
@@ -15,3 +17,33 @@ func Sign(obj model.Obj, parent string, encrypt bool) string {
 	}
 	return sign.Sign(stdpath.Join(parent, obj.GetName()))
 }
+
+// SignWithAcl is the sign of obj for user, it's empty if user can't read obj.
+// The link is also signed if the guest can't read obj, since the links
+// without sign are checked as the guest.
+func SignWithAcl(user *model.User, obj model.Obj, parent string, encrypt bool) string {
+	if obj.IsDir() {
+		return ""
+	}
+	reqPath := stdpath.Join(parent, obj.GetName())
+	if !HasPathPermission(user, reqPath, model.AclRead, true) {
+		return ""
+	}
+	if s := Sign(obj, parent, encrypt); s != "" {
+		return s
+	}
+	if !GuestCanRead(reqPath) {
+		return sign.Sign(reqPath)
+	}
+	return ""
+}
+
+// GuestCanRead checks the read acl of reqPath for the guest, who the requests without token are
+func GuestCanRead(reqPath string) bool {
+	guest, err := op.GetGuest()
+	if err != nil {
+		log.Errorf("failed get guest: %+v", err)
+		return false
+	}
+	return HasPathPermission(guest, reqPath, model.AclRead, true)
+}
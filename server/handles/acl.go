@@ -0,0 +1,107 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type AclListReq struct {
+	model.PageReq
+	SubjectType string `json:"subject_type" form:"subject_type"`
+	SubjectId   uint   `json:"subject_id" form:"subject_id"`
+}
+
+func ListAclRules(c *gin.Context) {
+	var req AclListReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	rules, total, err := op.GetAclRules(req.SubjectType, req.SubjectId, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: rules,
+		Total:   total,
+	})
+}
+
+func GetAclRule(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	rule, err := op.GetAclRuleById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, rule)
+}
+
+func validAclRule(rule *model.AclRule) string {
+	if rule.SubjectType != model.AclSubjectUser && rule.SubjectType != model.AclSubjectGroup {
+		return "invalid subject type"
+	}
+	if rule.Allow == 0 && rule.Deny == 0 {
+		return "the rule has no action"
+	}
+	return ""
+}
+
+func CreateAclRule(c *gin.Context) {
+	var req model.AclRule
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if msg := validAclRule(&req); msg != "" {
+		common.ErrorStrResp(c, msg, 400)
+		return
+	}
+	if err := op.CreateAclRule(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func UpdateAclRule(c *gin.Context) {
+	var req model.AclRule
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if msg := validAclRule(&req); msg != "" {
+		common.ErrorStrResp(c, msg, 400)
+		return
+	}
+	if err := op.UpdateAclRule(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteAclRule(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.DeleteAclRuleById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
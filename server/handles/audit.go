@@ -0,0 +1,53 @@
+package handles
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditListReq struct {
+	model.PageReq
+	Username string     `json:"username" form:"username"`
+	Action   string     `json:"action" form:"action"`
+	Protocol string     `json:"protocol" form:"protocol"`
+	IP       string     `json:"ip" form:"ip"`
+	Path     string     `json:"path" form:"path"`
+	Success  *bool      `json:"success" form:"success"`
+	Start    *time.Time `json:"start" form:"start"`
+	End      *time.Time `json:"end" form:"end"`
+}
+
+func ListAuditLogs(c *gin.Context) {
+	var req AuditListReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	if req.Path != "" {
+		req.Path = utils.FixAndCleanPath(req.Path)
+	}
+	logs, total, err := db.GetAuditLogs(db.AuditLogFilter{
+		Username: req.Username,
+		Action:   req.Action,
+		Protocol: req.Protocol,
+		IP:       req.IP,
+		Path:     req.Path,
+		Success:  req.Success,
+		Start:    req.Start,
+		End:      req.End,
+	}, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: logs,
+		Total:   total,
+	})
+}
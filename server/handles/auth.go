@@ -7,10 +7,12 @@ import (
 	"time"
 
 	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	"github.com/pquerna/otp/totp"
 )
 
@@ -59,12 +61,14 @@ func loginHash(c *gin.Context, req *LoginReq) {
 	// check username
 	user, err := op.GetUserByName(req.Username)
 	if err != nil {
+		audit.Login(c, req.Username, err)
 		common.ErrorResp(c, err, 400)
 		loginCache.Set(ip, count+1)
 		return
 	}
 	// validate password hash
 	if err := user.ValidatePwdStaticHash(req.Password); err != nil {
+		audit.Login(c, req.Username, err)
 		common.ErrorResp(c, err, 400)
 		loginCache.Set(ip, count+1)
 		return
@@ -72,6 +76,7 @@ func loginHash(c *gin.Context, req *LoginReq) {
 	// check 2FA
 	if user.OtpSecret != "" {
 		if !totp.Validate(req.OtpCode, user.OtpSecret) {
+			audit.Login(c, req.Username, errors.New("invalid 2FA code"))
 			common.ErrorStrResp(c, "Invalid 2FA code", 402)
 			loginCache.Set(ip, count+1)
 			return
@@ -83,13 +88,15 @@ func loginHash(c *gin.Context, req *LoginReq) {
 		common.ErrorResp(c, err, 400, true)
 		return
 	}
+	audit.Login(c, req.Username, nil)
 	common.SuccessResp(c, gin.H{"token": token})
 	loginCache.Del(ip)
 }
 
 type UserResp struct {
 	model.User
-	Otp bool `json:"otp"`
+	Otp   bool             `json:"otp"`
+	Usage *model.UserUsage `json:"usage"`
 }
 
 // CurrentUser get current user by token
@@ -100,6 +107,11 @@ func CurrentUser(c *gin.Context) {
 		User: *user,
 	}
 	userResp.Password = ""
+	// show the rights from the groups too
+	userResp.Permission = user.GetPermission()
+	if usage, err := op.GetUserUsage(user.ID); err == nil {
+		userResp.Usage = usage
+	}
 	if userResp.OtpSecret != "" {
 		userResp.Otp = true
 	}
@@ -6,10 +6,12 @@ import (
 	stdpath "path"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/bandwidth"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/sign"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -30,7 +32,7 @@ func Down(c *gin.Context) {
 		Proxy(c)
 		return
 	} else {
-		link, _, err := fs.Link(c, rawPath, model.LinkArgs{
+		link, file, err := fs.Link(c, rawPath, model.LinkArgs{
 			IP:      c.ClientIP(),
 			Header:  c.Request.Header,
 			Type:    c.Query("type"),
@@ -40,6 +42,11 @@ func Down(c *gin.Context) {
 			common.ErrorResp(c, err, 500)
 			return
 		}
+		// the entries of archives have no url, they are streamed by alist
+		if link.URL == "" {
+			proxyLink(c, storage, link, file)
+			return
+		}
 		if link.MFile != nil {
 			defer func(ReadSeekCloser io.ReadCloser) {
 				err := ReadSeekCloser.Close()
@@ -86,38 +93,59 @@ func Proxy(c *gin.Context) {
 				return
 			}
 		}
-		link, file, err := fs.Link(c, rawPath, model.LinkArgs{
-			Header:  c.Request.Header,
-			Type:    c.Query("type"),
-			HttpReq: c.Request,
-		})
-		if err != nil {
-			common.ErrorResp(c, err, 500)
-			return
-		}
-		if link.URL != "" && setting.GetBool(conf.ForwardDirectLinkParams) {
-			query := c.Request.URL.Query()
-			for _, v := range conf.SlicesMap[conf.IgnoreDirectLinkParams] {
-				query.Del(v)
-			}
-			link.URL, err = utils.InjectQuery(link.URL, query)
-			if err != nil {
-				common.ErrorResp(c, err, 500)
-				return
-			}
-		}
-		if storage.GetStorage().ProxyRange {
-			common.ProxyRange(link, file.GetSize())
+		proxyPath(c, storage, rawPath)
+	} else {
+		common.ErrorStrResp(c, "proxy not allowed", 403)
+		return
+	}
+}
+
+// proxyPath proxies the file at rawPath in storage through alist
+func proxyPath(c *gin.Context, storage driver.Driver, rawPath string) {
+	link, file, err := fs.Link(c, rawPath, model.LinkArgs{
+		Header:  c.Request.Header,
+		Type:    c.Query("type"),
+		HttpReq: c.Request,
+	})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if link.URL != "" && setting.GetBool(conf.ForwardDirectLinkParams) {
+		query := c.Request.URL.Query()
+		for _, v := range conf.SlicesMap[conf.IgnoreDirectLinkParams] {
+			query.Del(v)
 		}
-		err = common.Proxy(c.Writer, c.Request, link, file)
+		link.URL, err = utils.InjectQuery(link.URL, query)
 		if err != nil {
-			common.ErrorResp(c, err, 500, true)
+			common.ErrorResp(c, err, 500)
 			return
 		}
-	} else {
-		common.ErrorStrResp(c, "proxy not allowed", 403)
-		return
 	}
+	if storage.GetStorage().ProxyRange {
+		common.ProxyRange(link, file.GetSize())
+	}
+	proxyLink(c, storage, link, file)
+}
+
+func proxyLink(c *gin.Context, storage driver.Driver, link *model.Link, file model.Obj) {
+	w := bandwidth.NewResponseWriter(c, c.Writer, proxyLimiters(c, storage))
+	release := op.Acquire(storage)
+	err := common.Proxy(w, c.Request, link, file)
+	release()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+	}
+}
+
+// proxyLimiters returns the bandwidth limiters of a proxied download,
+// signed links don't carry the user, so only the global, storage and ip limits apply to them.
+func proxyLimiters(c *gin.Context, storage driver.Driver) bandwidth.Limiters {
+	var user *model.User
+	if u, ok := c.Get("user"); ok {
+		user, _ = u.(*model.User)
+	}
+	return bandwidth.Get(user, storage.GetStorage(), c.ClientIP())
 }
 
 // TODO need optimize
@@ -0,0 +1,91 @@
+package handles
+
+import (
+	"fmt"
+	"net/url"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/archive"
+	"github.com/alist-org/alist/v3/internal/bandwidth"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type ArchiveDownloadReq struct {
+	SrcDir   string   `json:"src_dir" form:"src_dir"`
+	Names    []string `json:"names" form:"names"`
+	Password string   `json:"password" form:"password"`
+	// Format is zip or tar.gz, zip by default
+	Format string `json:"format" form:"format"`
+}
+
+// FsArchiveDownload streams the names in src_dir as an archive built on the fly,
+// the files and folders which the user can't access are left out.
+func FsArchiveDownload(c *gin.Context) {
+	var req ArchiveDownloadReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if len(req.Names) == 0 {
+		common.ErrorStrResp(c, "Empty file names", 400)
+		return
+	}
+	if req.Format == "" {
+		req.Format = archive.FormatZip
+	}
+	if req.Format != archive.FormatZip && req.Format != archive.FormatTarGz {
+		common.ErrorStrResp(c, "invalid archive format", 400)
+		return
+	}
+	if err := checkNames(req.Names); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	srcDir, err := user.JoinPath(req.SrcDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	for _, name := range req.Names {
+		ok, err := common.CanReadPath(user, stdpath.Join(srcDir, name), req.Password)
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		if !ok {
+			common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+			return
+		}
+	}
+	filename := stdpath.Base(srcDir)
+	if len(req.Names) == 1 {
+		filename = req.Names[0]
+	}
+	if filename == "/" {
+		filename = "download"
+	}
+	filename += "." + req.Format
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, filename, url.PathEscape(filename)))
+	c.Header("Content-Type", utils.GetMimeType(filename))
+	c.Status(200)
+	w := metrics.NewProxyWriter(c.Request.Context(), c.Writer)
+	w = bandwidth.NewResponseWriter(c, w, bandwidth.Get(user, nil, c.ClientIP()))
+	err = fs.WriteArchive(c, w, srcDir, req.Names, fs.WriteArchiveArgs{
+		Format: req.Format,
+		Skip: func(path string, obj model.Obj) bool {
+			ok, err := common.CanReadPath(user, path, req.Password)
+			return err != nil || !ok
+		},
+	})
+	// the response has been started, so the error can only be logged
+	if err != nil {
+		log.Errorf("failed stream archive of %v in %s: %+v", req.Names, srcDir, err)
+	}
+}
@@ -2,6 +2,7 @@ package handles
 
 import (
 	"fmt"
+	stdpath "path"
 	"regexp"
 
 	"github.com/alist-org/alist/v3/internal/errs"
@@ -9,6 +10,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/generic"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -29,11 +31,6 @@ func FsBatchRename(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRename() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
-
 	reqPath, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -48,12 +45,23 @@ func FsBatchRename(c *gin.Context) {
 		}
 	}
 	c.Set("meta", meta)
+	// all the objects are checked before any of them is renamed
+	var renames []rename
 	for _, renameObject := range req.RenameObjects {
 		if renameObject.SrcName == "" || renameObject.NewName == "" {
 			continue
 		}
-		filePath := fmt.Sprintf("%s/%s", reqPath, renameObject.SrcName)
-		if err := fs.Rename(c, filePath, renameObject.NewName); err != nil {
+		renames = append(renames, rename{
+			path:    fmt.Sprintf("%s/%s", reqPath, renameObject.SrcName),
+			newName: renameObject.NewName,
+		})
+	}
+	if err := checkRenames(user, renames); err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	for _, r := range renames {
+		if err := fs.Rename(c, r.path, r.newName); err != nil {
 			common.ErrorResp(c, err, 500)
 			return
 		}
@@ -61,6 +69,24 @@ func FsBatchRename(c *gin.Context) {
 	common.SuccessResp(c)
 }
 
+type rename struct {
+	path    string
+	newName string
+}
+
+// checkRenames checks the names and the rename permission of all the renames
+func checkRenames(user *model.User, renames []rename) error {
+	for _, r := range renames {
+		if !utils.IsValidName(stdpath.Base(r.path)) || !utils.IsValidName(r.newName) {
+			return errors.Errorf("invalid rename of %s to %s", r.path, r.newName)
+		}
+		if !user.CanRename() || !common.HasPathPermission(user, r.path, model.AclRename, true) {
+			return errs.PermissionDenied
+		}
+	}
+	return nil
+}
+
 type RecursiveMoveReq struct {
 	SrcDir string `json:"src_dir"`
 	DstDir string `json:"dst_dir"`
@@ -74,10 +100,6 @@ func FsRecursiveMove(c *gin.Context) {
 	}
 
 	user := c.MustGet("user").(*model.User)
-	if !user.CanMove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -88,6 +110,11 @@ func FsRecursiveMove(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !user.CanMove() || !common.HasPathPermission(user, srcDir, model.AclDelete, true) ||
+		!common.HasPathPermission(user, dstDir, model.AclWrite, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 
 	meta, err := op.GetNearestMeta(srcDir)
 	if err != nil {
@@ -162,11 +189,6 @@ func FsRegexRename(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRename() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
-
 	reqPath, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -194,17 +216,24 @@ func FsRegexRename(c *gin.Context) {
 		return
 	}
 
+	var renames []rename
 	for _, file := range files {
-
 		if srcRegexp.MatchString(file.GetName()) {
-			filePath := fmt.Sprintf("%s/%s", reqPath, file.GetName())
-			newFileName := srcRegexp.ReplaceAllString(file.GetName(), req.NewNameRegex)
-			if err := fs.Rename(c, filePath, newFileName); err != nil {
-				common.ErrorResp(c, err, 500)
-				return
-			}
+			renames = append(renames, rename{
+				path:    fmt.Sprintf("%s/%s", reqPath, file.GetName()),
+				newName: srcRegexp.ReplaceAllString(file.GetName(), req.NewNameRegex),
+			})
+		}
+	}
+	if err := checkRenames(user, renames); err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	for _, r := range renames {
+		if err := fs.Rename(c, r.path, r.newName); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
 		}
-
 	}
 
 	common.SuccessResp(c)
@@ -0,0 +1,38 @@
+package handles
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBatchRenameChecksAll(t *testing.T) {
+	root := newLocalStorage(t, "/batch_rename", map[string]string{"a.txt": "a", "b.txt": "b"})
+	user := newTestUser(t, "batch_rename")
+	if err := op.CreateAclRule(&model.AclRule{SubjectType: model.AclSubjectUser, SubjectID: user.ID,
+		Path: "/batch_rename/b.txt", Deny: model.AclRename}); err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.POST("/rename", func(c *gin.Context) {
+		c.Set("user", user)
+		FsBatchRename(c)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/rename", strings.NewReader(`{"src_dir":"/batch_rename",
+"rename_objects":[{"src_name":"a.txt","new_name":"a1.txt"},{"src_name":"b.txt","new_name":"b1.txt"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), `"code":403`) {
+		t.Errorf("the rename should be denied, got %s", w.Body.String())
+	}
+	if _, err := os.Stat(root + "/a.txt"); err != nil {
+		t.Errorf("no object should be renamed if any of them is denied")
+	}
+}
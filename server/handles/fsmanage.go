@@ -1,11 +1,14 @@
 package handles
 
 import (
+	"context"
 	"fmt"
 	"github.com/xhofe/tache"
 	"io"
 	stdpath "path"
 
+	"github.com/alist-org/alist/v3/internal/archive"
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -35,7 +38,8 @@ func FsMkdir(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
-	if !user.CanWrite() {
+	canWrite := user.CanWrite()
+	if !canWrite {
 		meta, err := op.GetNearestMeta(stdpath.Dir(reqPath))
 		if err != nil {
 			if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
@@ -43,10 +47,11 @@ func FsMkdir(c *gin.Context) {
 				return
 			}
 		}
-		if !common.CanWrite(meta, reqPath) {
-			common.ErrorResp(c, errs.PermissionDenied, 403)
-			return
-		}
+		canWrite = common.CanWrite(meta, reqPath)
+	}
+	if !common.HasPathPermission(user, reqPath, model.AclWrite, canWrite) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
 	}
 	if err := fs.MakeDir(c, reqPath); err != nil {
 		common.ErrorResp(c, err, 500)
@@ -55,10 +60,32 @@ func FsMkdir(c *gin.Context) {
 	common.SuccessResp(c)
 }
 
+// checkNames rejects the names which are not a single path element, e.g. "../etc"
+func checkNames(names []string) error {
+	for _, name := range names {
+		if !utils.IsValidName(name) {
+			return errors.Errorf("invalid name: %s", name)
+		}
+	}
+	return nil
+}
+
+// hasNamesPermission checks the acl action on all the names in dir
+func hasNamesPermission(user *model.User, dir string, names []string, action int32, fallback bool) bool {
+	for _, name := range names {
+		if !common.HasPathPermission(user, stdpath.Join(dir, name), action, fallback) {
+			return false
+		}
+	}
+	return true
+}
+
 type MoveCopyReq struct {
 	SrcDir string   `json:"src_dir"`
 	DstDir string   `json:"dst_dir"`
 	Names  []string `json:"names"`
+	// ConflictPolicy only works for copy, skip, overwrite, rename or newer
+	ConflictPolicy string `json:"conflict_policy"`
 }
 
 func FsMove(c *gin.Context) {
@@ -72,10 +99,6 @@ func FsMove(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanMove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -86,6 +109,11 @@ func FsMove(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !user.CanMove() || !hasNamesPermission(user, srcDir, req.Names, model.AclDelete, true) ||
+		!hasNamesPermission(user, dstDir, req.Names, model.AclWrite, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	for i, name := range req.Names {
 		err := fs.Move(c, stdpath.Join(srcDir, name), dstDir, len(req.Names) > i+1)
 		if err != nil {
@@ -107,10 +135,6 @@ func FsCopy(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanCopy() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -121,9 +145,20 @@ func FsCopy(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !user.CanCopy() || !hasNamesPermission(user, srcDir, req.Names, model.AclRead, true) ||
+		!hasNamesPermission(user, dstDir, req.Names, model.AclWrite, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	policy := fs.ConflictPolicy(req.ConflictPolicy)
+	if !utils.SliceContains([]fs.ConflictPolicy{"", fs.ConflictSkip, fs.ConflictOverwrite, fs.ConflictRename, fs.ConflictNewer}, policy) {
+		common.ErrorStrResp(c, "invalid conflict policy", 400)
+		return
+	}
+	ctx := context.WithValue(c, conf.ConflictPolicyKey, policy)
 	var addedTasks []tache.TaskWithInfo
 	for i, name := range req.Names {
-		t, err := fs.Copy(c, stdpath.Join(srcDir, name), dstDir, len(req.Names) > i+1)
+		t, err := fs.Copy(ctx, stdpath.Join(srcDir, name), dstDir, len(req.Names) > i+1)
 		if t != nil {
 			addedTasks = append(addedTasks, t)
 		}
@@ -137,6 +172,109 @@ func FsCopy(c *gin.Context) {
 	})
 }
 
+type ExtractReq struct {
+	// Path is the archive, or a folder in the archive
+	Path   string `json:"path"`
+	DstDir string `json:"dst_dir"`
+}
+
+func FsExtract(c *gin.Context) {
+	var req ExtractReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	srcPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	dstDir, err := user.JoinPath(req.DstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !user.CanCopy() || !common.HasPathPermission(user, srcPath, model.AclRead, true) ||
+		!common.HasPathPermission(user, dstDir, model.AclWrite, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	t, err := fs.Extract(c, srcPath, dstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"tasks": getTaskInfos([]tache.TaskWithInfo{t}),
+	})
+}
+
+type CompressReq struct {
+	SrcDir string   `json:"src_dir"`
+	DstDir string   `json:"dst_dir"`
+	Names  []string `json:"names"`
+	// Name is the name of the archive
+	Name string `json:"name"`
+	// Format is zip or tar.gz, zip by default
+	Format   string `json:"format"`
+	Password string `json:"password"`
+}
+
+func FsCompress(c *gin.Context) {
+	var req CompressReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if len(req.Names) == 0 {
+		common.ErrorStrResp(c, "Empty file names", 400)
+		return
+	}
+	if err := checkNames(req.Names); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Format == "" {
+		req.Format = archive.FormatZip
+	}
+	user := c.MustGet("user").(*model.User)
+	srcDir, err := user.JoinPath(req.SrcDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	dstDir, err := user.JoinPath(req.DstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !user.CanCopy() || !hasNamesPermission(user, srcDir, req.Names, model.AclRead, true) ||
+		!common.HasPathPermission(user, stdpath.Join(dstDir, req.Name), model.AclWrite, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	for _, name := range req.Names {
+		ok, err := common.CanReadPath(user, stdpath.Join(srcDir, name), req.Password)
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		if !ok {
+			common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+			return
+		}
+	}
+	t, err := fs.Compress(c, srcDir, req.Names, dstDir, req.Name, req.Format, req.Password)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"tasks": getTaskInfos([]tache.TaskWithInfo{t}),
+	})
+}
+
 type RenameReq struct {
 	Path string `json:"path"`
 	Name string `json:"name"`
@@ -149,15 +287,15 @@ func FsRename(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRename() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	reqPath, err := user.JoinPath(req.Path)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !user.CanRename() || !common.HasPathPermission(user, reqPath, model.AclRename, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	if err := fs.Rename(c, reqPath, req.Name); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
@@ -181,15 +319,15 @@ func FsRemove(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRemove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	reqDir, err := user.JoinPath(req.Dir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !user.CanRemove() || !hasNamesPermission(user, reqDir, req.Names, model.AclDelete, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	for _, name := range req.Names {
 		err := fs.Remove(c, stdpath.Join(reqDir, name))
 		if err != nil {
@@ -213,15 +351,15 @@ func FsRemoveEmptyDirectory(c *gin.Context) {
 	}
 
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRemove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !user.CanRemove() || !common.HasPathPermission(user, srcDir, model.AclDelete, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 
 	meta, err := op.GetNearestMeta(srcDir)
 	if err != nil {
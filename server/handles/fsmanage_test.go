@@ -0,0 +1,35 @@
+package handles
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAclNotGrantCopy(t *testing.T) {
+	newLocalStorage(t, "/acl_copy", map[string]string{"a.txt": "a", "dst/.keep": ""})
+	user := newTestUser(t, "acl_copy")
+	user.Permission &^= 1 << 6
+	if err := op.CreateAclRule(&model.AclRule{SubjectType: model.AclSubjectUser, SubjectID: user.ID,
+		Path: "/acl_copy", Allow: model.AclRead | model.AclWrite}); err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.POST("/copy", func(c *gin.Context) {
+		c.Set("user", user)
+		FsCopy(c)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/copy", strings.NewReader(`{"src_dir":"/acl_copy",
+"dst_dir":"/acl_copy/dst","names":["a.txt"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), `"code":403`) {
+		t.Errorf("an acl allow shouldn't grant the copy permission, got %s", w.Body.String())
+	}
+}
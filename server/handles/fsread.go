@@ -2,6 +2,7 @@ package handles
 
 import (
 	"fmt"
+	"net/http"
 	stdpath "path"
 	"strings"
 	"time"
@@ -75,11 +76,13 @@ func FsList(c *gin.Context) {
 		}
 	}
 	c.Set("meta", meta)
-	if !common.CanAccess(user, meta, reqPath, req.Password) {
+	if !common.CanAccess(user, meta, reqPath, req.Password) ||
+		!common.HasPathPermission(user, reqPath, model.AclList, true) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
-	if !user.CanWrite() && !common.CanWrite(meta, reqPath) && req.Refresh {
+	write := common.HasPathPermission(user, reqPath, model.AclWrite, user.CanWrite() || common.CanWrite(meta, reqPath))
+	if !write && req.Refresh {
 		common.ErrorStrResp(c, "Refresh without permission", 403)
 		return
 	}
@@ -88,6 +91,7 @@ func FsList(c *gin.Context) {
 		common.ErrorResp(c, err, 500)
 		return
 	}
+	objs = common.FilterVisibleObjs(user, reqPath, objs)
 	total, objs := pagination(objs, &req.PageReq)
 	provider := "unknown"
 	storage, err := fs.GetStorage(reqPath, &fs.GetStoragesArgs{})
@@ -95,11 +99,11 @@ func FsList(c *gin.Context) {
 		provider = storage.GetStorage().Driver
 	}
 	common.SuccessResp(c, FsListResp{
-		Content:  toObjsResp(objs, reqPath, isEncrypt(meta, reqPath)),
+		Content:  toObjsResp(c.Request, user, objs, reqPath, isEncrypt(meta, reqPath)),
 		Total:    int64(total),
 		Readme:   getReadme(meta, reqPath),
 		Header:   getHeader(meta, reqPath),
-		Write:    user.CanWrite() || common.CanWrite(meta, reqPath),
+		Write:    write,
 		Provider: provider,
 	})
 }
@@ -133,7 +137,8 @@ func FsDirs(c *gin.Context) {
 		}
 	}
 	c.Set("meta", meta)
-	if !common.CanAccess(user, meta, reqPath, req.Password) {
+	if !common.CanAccess(user, meta, reqPath, req.Password) ||
+		!common.HasPathPermission(user, reqPath, model.AclList, true) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
@@ -142,7 +147,7 @@ func FsDirs(c *gin.Context) {
 		common.ErrorResp(c, err, 500)
 		return
 	}
-	dirs := filterDirs(objs)
+	dirs := filterDirs(common.FilterVisibleObjs(user, reqPath, objs))
 	common.SuccessResp(c, dirs)
 }
 
@@ -205,10 +210,10 @@ func pagination(objs []model.Obj, req *model.PageReq) (int, []model.Obj) {
 	return total, objs[start:end]
 }
 
-func toObjsResp(objs []model.Obj, parent string, encrypt bool) []ObjResp {
+// toObjsResp the objs which user can't read have no sign
+func toObjsResp(r *http.Request, user *model.User, objs []model.Obj, parent string, encrypt bool) []ObjResp {
 	var resp []ObjResp
 	for _, obj := range objs {
-		thumb, _ := model.GetThumb(obj)
 		resp = append(resp, ObjResp{
 			Name:        obj.GetName(),
 			Size:        obj.GetSize(),
@@ -217,8 +222,8 @@ func toObjsResp(objs []model.Obj, parent string, encrypt bool) []ObjResp {
 			Created:     obj.CreateTime(),
 			HashInfoStr: obj.GetHash().String(),
 			HashInfo:    obj.GetHash().Export(),
-			Sign:        common.Sign(obj, parent, encrypt),
-			Thumb:       thumb,
+			Sign:        common.SignWithAcl(user, obj, parent, encrypt),
+			Thumb:       getThumb(r, user, obj, stdpath.Join(parent, obj.GetName())),
 			Type:        utils.GetObjType(obj.GetName(), obj.IsDir()),
 		})
 	}
@@ -259,7 +264,8 @@ func FsGet(c *gin.Context) {
 		}
 	}
 	c.Set("meta", meta)
-	if !common.CanAccess(user, meta, reqPath, req.Password) {
+	if !common.CanAccess(user, meta, reqPath, req.Password) ||
+		!common.HasPathPermission(user, reqPath, model.AclRead, true) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
@@ -282,7 +288,7 @@ func FsGet(c *gin.Context) {
 		}
 		if storage.Config().MustProxy() || storage.GetStorage().WebProxy {
 			query := ""
-			if isEncrypt(meta, reqPath) || setting.GetBool(conf.SignAll) {
+			if isEncrypt(meta, reqPath) || setting.GetBool(conf.SignAll) || !common.GuestCanRead(reqPath) {
 				query = "?sign=" + sign.Sign(reqPath)
 			}
 			if storage.GetStorage().DownProxyUrl != "" {
@@ -312,6 +318,13 @@ func FsGet(c *gin.Context) {
 					return
 				}
 				rawURL = link.URL
+				// the entries of archives have no url, they are streamed by alist
+				if rawURL == "" {
+					rawURL = fmt.Sprintf("%s/d%s?sign=%s",
+						common.GetApiUrl(c.Request),
+						utils.EncodePath(reqPath, true),
+						sign.Sign(reqPath))
+				}
 			}
 		}
 	}
@@ -322,7 +335,6 @@ func FsGet(c *gin.Context) {
 		related = filterRelated(sameLevelFiles, obj)
 	}
 	parentMeta, _ := op.GetNearestMeta(parentPath)
-	thumb, _ := model.GetThumb(obj)
 	common.SuccessResp(c, FsGetResp{
 		ObjResp: ObjResp{
 			Name:        obj.GetName(),
@@ -332,15 +344,15 @@ func FsGet(c *gin.Context) {
 			Created:     obj.CreateTime(),
 			HashInfoStr: obj.GetHash().String(),
 			HashInfo:    obj.GetHash().Export(),
-			Sign:        common.Sign(obj, parentPath, isEncrypt(meta, reqPath)),
+			Sign:        common.SignWithAcl(user, obj, parentPath, isEncrypt(meta, reqPath)),
 			Type:        utils.GetFileType(obj.GetName()),
-			Thumb:       thumb,
+			Thumb:       getThumb(c.Request, user, obj, reqPath),
 		},
 		RawURL:   rawURL,
 		Readme:   getReadme(meta, reqPath),
 		Header:   getHeader(meta, reqPath),
 		Provider: provider,
-		Related:  toObjsResp(related, parentPath, isEncrypt(parentMeta, parentPath)),
+		Related:  toObjsResp(c.Request, user, related, parentPath, isEncrypt(parentMeta, parentPath)),
 	})
 }
 
@@ -384,7 +396,8 @@ func FsOther(c *gin.Context) {
 		}
 	}
 	c.Set("meta", meta)
-	if !common.CanAccess(user, meta, req.Path, req.Password) {
+	if !common.CanAccess(user, meta, req.Path, req.Password) ||
+		!common.HasPathPermission(user, req.Path, model.AclRead, true) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
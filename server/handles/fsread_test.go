@@ -0,0 +1,99 @@
+package handles
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/thumb"
+	"github.com/alist-org/alist/v3/server/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadAclSign(t *testing.T) {
+	item, err := op.GetSettingItemByKey(conf.SignAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Value = "false"
+	if err := op.SaveSettingItem(item); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		item.Value = "true"
+		_ = op.SaveSettingItem(item)
+	}()
+	newLocalStorage(t, "/acl_sign", map[string]string{
+		"public.txt":  "public",
+		"user.txt":    "user",
+		"private.txt": "private",
+	})
+	user := newTestUser(t, "acl_sign")
+	guest, err := op.GetGuest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range []model.AclRule{
+		{SubjectType: model.AclSubjectUser, SubjectID: user.ID, Path: "/acl_sign/private.txt", Deny: model.AclRead},
+		{SubjectType: model.AclSubjectUser, SubjectID: guest.ID, Path: "/acl_sign/private.txt", Deny: model.AclRead},
+		{SubjectType: model.AclSubjectUser, SubjectID: guest.ID, Path: "/acl_sign/user.txt", Deny: model.AclRead},
+	} {
+		rule := rule
+		if err := op.CreateAclRule(&rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	storage, err := op.GetStorageByMountPath("/acl_sign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	objs, err := op.List(context.Background(), storage, "/", model.ListArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signs := make(map[string]string)
+	for _, obj := range toObjsResp(httptest.NewRequest(http.MethodGet, "/", nil), user, objs, "/acl_sign", false) {
+		signs[obj.Name] = obj.Sign
+	}
+	if signs["private.txt"] != "" {
+		t.Errorf("the file which the user can't read should have no sign")
+	}
+	if signs["user.txt"] == "" {
+		t.Errorf("the file which only the user can read should be signed")
+	}
+
+	r := gin.New()
+	r.GET("/d/*path", middlewares.Down, Down)
+	download := func(path string) string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Body.String()
+	}
+	if body := download("/d/acl_sign/public.txt"); body != "public" {
+		t.Errorf("the file which the guest can read should be downloaded, got %s", body)
+	}
+	if body := download("/d/acl_sign/private.txt"); body == "private" {
+		t.Errorf("the file which the guest can't read should not be downloaded without sign")
+	}
+	if body := download("/d/acl_sign/user.txt?sign=" + signs["user.txt"]); body != "user" {
+		t.Errorf("the file should be downloaded with the sign, got %s", body)
+	}
+}
+
+func TestThumbSignScope(t *testing.T) {
+	newLocalStorage(t, "/thumb_sign", map[string]string{"a.jpg": "jpg"})
+	r := gin.New()
+	r.GET("/d/*path", middlewares.Down, Down)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/d/thumb_sign/a.jpg?sign="+thumb.Sign("/thumb_sign/a.jpg"), nil))
+	if w.Body.String() == "jpg" {
+		t.Errorf("the sign of the thumbnail should not download the file")
+	}
+	if err := thumb.Verify("/thumb_sign/a.jpg", thumb.Sign("/thumb_sign/a.jpg")); err != nil {
+		t.Errorf("failed verify the sign of the thumbnail: %+v", err)
+	}
+}
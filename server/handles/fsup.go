@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/bandwidth"
 	"github.com/alist-org/alist/v3/internal/stream"
 
 	"github.com/alist-org/alist/v3/internal/fs"
@@ -54,13 +55,13 @@ func FsStream(c *gin.Context) {
 			Size:     size,
 			Modified: getLastModified(c),
 		},
-		Reader:       c.Request.Body,
+		Reader:       bandwidth.NewReader(c, c.Request.Body, bandwidth.GetByPath(user, path, c.ClientIP())),
 		Mimetype:     c.GetHeader("Content-Type"),
 		WebPutAsTask: asTask,
 	}
 	var t tache.TaskWithInfo
 	if asTask {
-		t, err = fs.PutAsTask(dir, s)
+		t, err = fs.PutAsTask(c, dir, s)
 	} else {
 		err = fs.PutDirectly(c, dir, s, true)
 	}
@@ -101,6 +102,7 @@ func FsForm(c *gin.Context) {
 		common.ErrorStrResp(c, "Current storage doesn't support upload", 405)
 		return
 	}
+	c.Request.Body = bandwidth.NewReadCloser(c, c.Request.Body, bandwidth.Get(user, storage.GetStorage(), c.ClientIP()))
 	file, err := c.FormFile("file")
 	if err != nil {
 		common.ErrorResp(c, err, 500)
@@ -128,7 +130,7 @@ func FsForm(c *gin.Context) {
 		s.Reader = struct {
 			io.Reader
 		}{f}
-		t, err = fs.PutAsTask(dir, &s)
+		t, err = fs.PutAsTask(c, dir, &s)
 	} else {
 		ss, err := stream.NewSeekableStream(s, nil)
 		if err != nil {
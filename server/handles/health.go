@@ -0,0 +1,37 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/health"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListStorageHealth returns the current health of the checked storages
+func ListStorageHealth(c *gin.Context) {
+	common.SuccessResp(c, health.GetStates())
+}
+
+type StorageHealthReq struct {
+	model.PageReq
+	Id uint `json:"id" form:"id"`
+}
+
+// ListStorageHealthHistory lists the health checks of the storage id, or of all storages
+func ListStorageHealthHistory(c *gin.Context) {
+	var req StorageHealthReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	healths, total, err := health.GetHistory(req.Id, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: healths,
+		Total:   total,
+	})
+}
@@ -0,0 +1,99 @@
+package handles
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/alist-org/alist/v3/internal/mirror"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/search"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/xhofe/tache"
+)
+
+var taskStateNames = map[tache.State]string{
+	tache.StatePending:      "pending",
+	tache.StateRunning:      "running",
+	tache.StateSucceeded:    "succeeded",
+	tache.StateCanceling:    "canceling",
+	tache.StateCanceled:     "canceled",
+	tache.StateErrored:      "errored",
+	tache.StateFailing:      "failing",
+	tache.StateFailed:       "failed",
+	tache.StateWaitingRetry: "waiting_retry",
+	tache.StateBeforeRetry:  "before_retry",
+}
+
+// Metrics serves the prometheus metrics, the token is passed by the Authorization header or the token query
+func Metrics(c *gin.Context) {
+	token := setting.GetStr(conf.MetricsToken)
+	if token == "" {
+		common.ErrorStrResp(c, "metrics is disabled", 404)
+		return
+	}
+	reqToken := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if reqToken == "" {
+		reqToken = c.Query("token")
+	}
+	if subtle.ConstantTimeCompare([]byte(reqToken), []byte(token)) != 1 {
+		common.ErrorStrResp(c, "invalid metrics token", 401)
+		return
+	}
+	refreshMetrics()
+	metrics.Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// refreshMetrics updates the gauges which are read from the current state
+func refreshMetrics() {
+	metrics.Tasks.Reset()
+	taskMetrics("upload", fs.UploadTaskManager)
+	taskMetrics("copy", fs.CopyTaskManager)
+	taskMetrics("extract", fs.ExtractTaskManager)
+	taskMetrics("compress", fs.CompressTaskManager)
+	taskMetrics("sync", mirror.TaskManager)
+	taskMetrics("offline_download", tool.DownloadTaskManager)
+	taskMetrics("offline_download_transfer", tool.TransferTaskManager)
+
+	metrics.StorageStatus.Reset()
+	metrics.StorageUp.Reset()
+	for _, storage := range op.GetAllStorages() {
+		s := storage.GetStorage()
+		metrics.StorageStatus.WithLabelValues(s.MountPath, s.Driver, s.Status).Set(1)
+		up := 0.0
+		if s.Status == op.WORK {
+			up = 1
+		}
+		metrics.StorageUp.WithLabelValues(s.MountPath, s.Driver).Set(up)
+	}
+
+	if progress, err := search.Progress(); err == nil {
+		metrics.IndexObjects.Set(float64(progress.ObjCount))
+		done := 0.0
+		if progress.IsDone {
+			done = 1
+		}
+		metrics.IndexDone.Set(done)
+		if progress.LastDoneTime != nil {
+			metrics.IndexLastDone.Set(float64(progress.LastDoneTime.Unix()))
+		}
+	}
+}
+
+func taskMetrics[T tache.TaskWithInfo](name string, manager *tache.Manager[T]) {
+	if manager == nil {
+		return
+	}
+	// report the empty states as 0 so that they don't disappear
+	for _, stateName := range taskStateNames {
+		metrics.Tasks.WithLabelValues(name, stateName).Set(0)
+	}
+	for _, t := range manager.GetAll() {
+		metrics.Tasks.WithLabelValues(name, taskStateNames[t.GetState()]).Inc()
+	}
+}
@@ -83,11 +83,6 @@ type AddOfflineDownloadReq struct {
 
 func AddOfflineDownload(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
-	if !user.CanAddOfflineDownloadTasks() {
-		common.ErrorStrResp(c, "permission denied", 403)
-		return
-	}
-
 	var req AddOfflineDownloadReq
 	if err := c.ShouldBind(&req); err != nil {
 		common.ErrorResp(c, err, 400)
@@ -98,6 +93,14 @@ func AddOfflineDownload(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !common.HasPathPermission(user, reqPath, model.AclOfflineDownload, user.CanAddOfflineDownloadTasks()) {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
+	if err := op.CheckQuota(user, 0, int64(len(req.Urls))); err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
 	var tasks []tache.TaskWithInfo
 	for _, url := range req.Urls {
 		t, err := tool.AddURL(c, &tool.AddURLArgs{
@@ -57,7 +57,12 @@ func Search(c *gin.Context) {
 		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			continue
 		}
-		if !common.CanAccess(user, meta, path.Join(node.Parent, node.Name), req.Password) {
+		nodePath := path.Join(node.Parent, node.Name)
+		if visible, _ := common.CanSeePath(user, nodePath); !visible {
+			continue
+		}
+		if !common.CanAccess(user, meta, nodePath, req.Password) ||
+			!common.HasPathPermission(user, nodePath, model.AclList, true) {
 			continue
 		}
 		filteredNodes = append(filteredNodes, node)
@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
@@ -17,7 +18,9 @@ import (
 func ResetToken(c *gin.Context) {
 	token := random.Token()
 	item := model.SettingItem{Key: "token", Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE}
-	if err := op.SaveSettingItem(&item); err != nil {
+	err := op.SaveSettingItem(&item)
+	audit.Log(c, model.AuditTokenReset, "", item.Key, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -51,7 +54,9 @@ func SaveSettings(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.SaveSettingItems(req); err != nil {
+	err := op.SaveSettingItems(req)
+	audit.Log(c, model.AuditSettingSave, "", settingKeys(req), err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c)
@@ -59,6 +64,15 @@ func SaveSettings(c *gin.Context) {
 	}
 }
 
+// settingKeys joins the keys of items for the audit log
+func settingKeys(items []model.SettingItem) string {
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+	return strings.Join(keys, ",")
+}
+
 func ListSettings(c *gin.Context) {
 	groupStr := c.Query("group")
 	groupsStr := c.Query("groups")
@@ -93,7 +107,9 @@ func ListSettings(c *gin.Context) {
 
 func DeleteSetting(c *gin.Context) {
 	key := c.Query("key")
-	if err := op.DeleteSettingItemByKey(key); err != nil {
+	err := op.DeleteSettingItemByKey(key)
+	audit.Log(c, model.AuditSettingDelete, "", key, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -0,0 +1,366 @@
+package handles
+
+import (
+	"context"
+	"crypto/subtle"
+	stdpath "path"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type ShareListReq struct {
+	model.PageReq
+	UserId uint `json:"user_id" form:"user_id"`
+}
+
+// ListShares lists the shares of the current user
+func ListShares(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	shares, total, err := op.GetShares(user.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: shares,
+		Total:   total,
+	})
+}
+
+// ListAllShares lists the shares of all users, or of the user_id
+func ListAllShares(c *gin.Context) {
+	var req ShareListReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	shares, total, err := op.GetShares(req.UserId, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: shares,
+		Total:   total,
+	})
+}
+
+type CreateShareReq struct {
+	Path         string     `json:"path" binding:"required"`
+	PathPassword string     `json:"path_password"` // the meta password of the path if it has one
+	Password     string     `json:"password"`
+	Expires      *time.Time `json:"expires"`
+	MaxDownloads int64      `json:"max_downloads"`
+}
+
+func CreateShare(c *gin.Context) {
+	var req CreateShareReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "guest can't create shares", 403)
+		return
+	}
+	if req.MaxDownloads < 0 {
+		common.ErrorStrResp(c, "max downloads can't be negative", 400)
+		return
+	}
+	if req.Expires != nil && req.Expires.Before(time.Now()) {
+		common.ErrorStrResp(c, "the expiry date has passed", 400)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !common.CanAccess(user, meta, reqPath, req.PathPassword) ||
+		!common.HasPathPermission(user, reqPath, model.AclRead, true) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	if _, err = fs.Get(c, reqPath, &fs.GetArgs{}); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	share := &model.Share{
+		UserID:       user.ID,
+		Path:         reqPath,
+		Password:     req.Password,
+		Expires:      req.Expires,
+		MaxDownloads: req.MaxDownloads,
+	}
+	if err := op.CreateShare(share); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, share)
+}
+
+// DeleteShare revokes a share, users can only revoke their own shares
+func DeleteShare(c *gin.Context) {
+	id := c.Query("id")
+	user := c.MustGet("user").(*model.User)
+	share, err := op.GetShareById(id)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if share.UserID != user.ID && !user.IsAdmin() {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
+	if err := op.DeleteShareById(id); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+type ShareObjResp struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	IsDir    bool      `json:"is_dir"`
+	Modified time.Time `json:"modified"`
+	Thumb    string    `json:"thumb"`
+	Type     int       `json:"type"`
+}
+
+type ShareListResp struct {
+	Name      string         `json:"name"`
+	Path      string         `json:"path"` // the path in the share
+	Content   []ShareObjResp `json:"content"`
+	Total     int64          `json:"total"`
+	Expires   *time.Time     `json:"expires"`
+	Downloads int64          `json:"downloads"`
+}
+
+// ShareGet serves /s/:id/*path, folders are listed and files are downloaded
+func ShareGet(c *gin.Context) {
+	share, err := op.GetShareById(c.Param("id"))
+	if err != nil {
+		common.ErrorStrResp(c, "share not found", 404)
+		return
+	}
+	if share.IsExpired() {
+		common.ErrorStrResp(c, "share expired", 403)
+		return
+	}
+	if share.Password != "" && sign.Verify(shareTokenData(share), c.Query("token")) != nil {
+		common.ErrorStrResp(c, "password is required", 401)
+		return
+	}
+	owner, err := getShareOwner(share)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	subPath := utils.FixAndCleanPath(c.Param("path"))
+	reqPath := stdpath.Join(share.Path, subPath)
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	ctx := context.WithValue(context.WithValue(c, "user", owner), "meta", meta)
+	obj, err := fs.Get(ctx, reqPath, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorStrResp(c, "object not found", 404)
+		return
+	}
+	if !obj.IsDir() {
+		downloadShareFile(c, share, owner, obj, reqPath)
+		return
+	}
+	if !common.HasPathPermission(owner, reqPath, model.AclList, true) {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
+	var req model.PageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	objs, err := fs.List(ctx, reqPath, &fs.ListArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	objs = common.FilterVisibleObjs(owner, reqPath, objs)
+	total, objs := pagination(objs, &req)
+	content := make([]ShareObjResp, 0, len(objs))
+	for _, o := range objs {
+		thumb, _ := model.GetThumb(o)
+		content = append(content, ShareObjResp{
+			Name:     o.GetName(),
+			Size:     o.GetSize(),
+			IsDir:    o.IsDir(),
+			Modified: o.ModTime(),
+			Thumb:    thumb,
+			Type:     utils.GetObjType(o.GetName(), o.IsDir()),
+		})
+	}
+	common.SuccessResp(c, ShareListResp{
+		Name:      stdpath.Base(share.Path),
+		Path:      subPath,
+		Content:   content,
+		Total:     int64(total),
+		Expires:   share.Expires,
+		Downloads: share.Downloads,
+	})
+}
+
+type ShareAuthReq struct {
+	Password string `json:"password" form:"password"`
+}
+
+// shareAuthCache counts the wrong passwords of each client for each share, like loginCache
+var shareAuthCache = cache.NewMemCache[int]()
+
+// ShareAuth exchanges the password of a share for a token, which is passed to
+// /s/:id with ?token= so the password never appears in the urls
+func ShareAuth(c *gin.Context) {
+	var req ShareAuthReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	share, err := op.GetShareById(c.Param("id"))
+	if err != nil {
+		common.ErrorStrResp(c, "share not found", 404)
+		return
+	}
+	if share.IsExpired() {
+		common.ErrorStrResp(c, "share expired", 403)
+		return
+	}
+	key := share.ID + ":" + c.ClientIP()
+	count, ok := shareAuthCache.Get(key)
+	if ok && count >= defaultTimes {
+		common.ErrorStrResp(c, "Too many incorrect passwords, Try again later.", 429)
+		shareAuthCache.Expire(key, defaultDuration)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Password), []byte(share.Password)) != 1 {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		shareAuthCache.Set(key, count+1, cache.WithEx[int](defaultDuration))
+		return
+	}
+	shareAuthCache.Del(key)
+	common.SuccessResp(c, gin.H{
+		"token": sign.Sign(shareTokenData(share)),
+	})
+}
+
+// shareTokenData is signed as the token of share, the scope keeps it
+// from being accepted by /d/ or /t/
+func shareTokenData(share *model.Share) string {
+	return "s:" + share.ID
+}
+
+// getShareOwner returns the owner of share, the share is invalid if the owner is disabled
+func getShareOwner(share *model.Share) (*model.User, error) {
+	u, err := op.GetUserById(share.UserID)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := op.GetUserByName(u.Username)
+	if err != nil {
+		return nil, err
+	}
+	if owner.Disabled {
+		return nil, errors.New("the owner of the share is disabled")
+	}
+	return owner, nil
+}
+
+// shareDownloadTTL is how long the counted download of a client lasts after its last request
+const shareDownloadTTL = 10 * time.Minute
+
+// shareDownloads are the bytes requested by the counted download of each client
+var shareDownloads = cache.NewMemCache(cache.WithShards[int64](16))
+
+// requestedLength is the number of bytes requested by the Range header, the whole file without it
+func requestedLength(rangeHeader string, size int64) int64 {
+	ranges, err := http_range.ParseRange(rangeHeader, size)
+	if err != nil || len(ranges) == 0 {
+		return size
+	}
+	var length int64
+	for _, r := range ranges {
+		length += r.Length
+	}
+	return length
+}
+
+func downloadShareFile(c *gin.Context, share *model.Share, owner *model.User, obj model.Obj, reqPath string) {
+	if !common.HasPathPermission(owner, reqPath, model.AclRead, true) {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
+	// the range requests of a client are counted as one download until they
+	// have requested the size of the file, HEAD requests are never counted
+	key := share.ID + ":" + c.ClientIP() + ":" + reqPath
+	requested, ok := shareDownloads.Get(key)
+	if c.Request.Method != "GET" {
+		if !ok && share.IsExhausted() {
+			common.ErrorStrResp(c, "the share has reached its download limit", 403)
+			return
+		}
+	} else {
+		length := requestedLength(c.GetHeader("Range"), obj.GetSize())
+		if !ok || requested+length > obj.GetSize() || obj.GetSize() == 0 {
+			ok, err := op.IncrShareDownloads(share.ID)
+			if err != nil {
+				common.ErrorResp(c, err, 500, true)
+				return
+			}
+			if !ok {
+				common.ErrorStrResp(c, "the share has reached its download limit", 403)
+				return
+			}
+			requested = 0
+		}
+		shareDownloads.Set(key, requested+length, cache.WithEx[int64](shareDownloadTTL))
+	}
+	c.Set("path", reqPath)
+	c.Set("user", owner)
+	if share.MaxDownloads <= 0 {
+		Down(c)
+		return
+	}
+	// a direct link or a signed proxy url could be downloaded again without being counted
+	storage, err := fs.GetStorage(reqPath, &fs.GetStoragesArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	proxyPath(c, storage, reqPath)
+}
@@ -0,0 +1,164 @@
+package handles
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/bootstrap/data"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+	data.InitData()
+	gin.SetMode(gin.ReleaseMode)
+}
+
+// newLocalStorage mounts a temp dir with the files by the local driver at mountPath
+func newLocalStorage(t *testing.T, mountPath string, files map[string]string) string {
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: mountPath,
+		Addition: `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	return root
+}
+
+func newTestUser(t *testing.T, name string) *model.User {
+	user := &model.User{Username: name, Role: model.GENERAL, BasePath: "/", Permission: 0xff}
+	if err := op.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func shareRouter() *gin.Engine {
+	r := gin.New()
+	r.GET("/s/:id", ShareGet)
+	r.GET("/s/:id/*path", ShareGet)
+	r.HEAD("/s/:id", ShareGet)
+	r.POST("/s/:id", ShareAuth)
+	return r
+}
+
+func shareRequest(r *gin.Engine, method, url, rng string) string {
+	req := httptest.NewRequest(method, url, nil)
+	if rng != "" {
+		req.Header.Set("Range", rng)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Body.String()
+}
+
+func TestShareDownloadLimit(t *testing.T) {
+	newLocalStorage(t, "/share_limit", map[string]string{"a.txt": "0123456789"})
+	user := newTestUser(t, "share_limit")
+	r := shareRouter()
+	newShare := func() string {
+		share := &model.Share{UserID: user.ID, Path: "/share_limit/a.txt", MaxDownloads: 1}
+		if err := op.CreateShare(share); err != nil {
+			t.Fatal(err)
+		}
+		return "/s/" + share.ID
+	}
+
+	// the range requests of one download are counted once
+	url := newShare()
+	if body := shareRequest(r, http.MethodGet, url, "bytes=0-4"); body != "01234" {
+		t.Errorf("the first range should be downloaded, got %s", body)
+	}
+	if body := shareRequest(r, http.MethodGet, url, "bytes=5-"); body != "56789" {
+		t.Errorf("the rest of the download should not be counted again, got %s", body)
+	}
+	if body := shareRequest(r, http.MethodGet, url, ""); !strings.Contains(body, "download limit") {
+		t.Errorf("the second download should reach the limit, got %s", body)
+	}
+
+	// repeating a range request can't download the file more times than the limit
+	url = newShare()
+	if body := shareRequest(r, http.MethodGet, url, "bytes=1-"); body != "123456789" {
+		t.Errorf("the first range should be downloaded, got %s", body)
+	}
+	if body := shareRequest(r, http.MethodGet, url, "bytes=1-"); !strings.Contains(body, "download limit") {
+		t.Errorf("the repeated range should reach the limit, got %s", body)
+	}
+}
+
+func TestSharePassword(t *testing.T) {
+	newLocalStorage(t, "/share_pwd", map[string]string{"a.txt": "content"})
+	user := newTestUser(t, "share_pwd")
+	share := &model.Share{UserID: user.ID, Path: "/share_pwd/a.txt", Password: "secret"}
+	if err := op.CreateShare(share); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := utils.Json.Marshal(share); strings.Contains(string(data), "secret") {
+		t.Errorf("the password should not be in the json of share: %s", data)
+	}
+	r := shareRouter()
+	url := "/s/" + share.ID
+	if body := shareRequest(r, http.MethodGet, url+"?pwd=secret", ""); body == "content" {
+		t.Errorf("the password should not be accepted in the url")
+	}
+	if body := shareRequest(r, http.MethodPost, url+"?password=wrong", ""); !strings.Contains(body, "incorrect") {
+		t.Errorf("a wrong password should be rejected, got %s", body)
+	}
+	body := shareRequest(r, http.MethodPost, url+"?password=secret", "")
+	var resp struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	if err := utils.Json.UnmarshalFromString(body, &resp); err != nil || resp.Data.Token == "" {
+		t.Fatalf("failed get the token: %s", body)
+	}
+	if body := shareRequest(r, http.MethodGet, url+"?token="+resp.Data.Token, ""); body != "content" {
+		t.Errorf("the file should be downloaded with the token, got %s", body)
+	}
+}
+
+func TestSharePasswordAttempts(t *testing.T) {
+	newLocalStorage(t, "/share_attempts", map[string]string{"a.txt": "content"})
+	user := newTestUser(t, "share_attempts")
+	share := &model.Share{UserID: user.ID, Path: "/share_attempts/a.txt", Password: "secret"}
+	if err := op.CreateShare(share); err != nil {
+		t.Fatal(err)
+	}
+	r := shareRouter()
+	url := "/s/" + share.ID
+	for i := 0; i < defaultTimes; i++ {
+		shareRequest(r, http.MethodPost, url+"?password=wrong", "")
+	}
+	if body := shareRequest(r, http.MethodPost, url+"?password=secret", ""); !strings.Contains(body, "Too many") {
+		t.Errorf("the password should not be tried after too many wrong ones, got %s", body)
+	}
+}
@@ -2,12 +2,18 @@ package handles
 
 import (
 	"context"
+	"sort"
 	"strconv"
+	"sync"
+	"time"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -26,19 +32,103 @@ func ListStorages(c *gin.Context) {
 		common.ErrorResp(c, err, 500)
 		return
 	}
+	details := getStoragesDetails(c, storages)
+	resp := make([]StorageResp, len(storages))
+	for i := range storages {
+		resp[i] = StorageResp{Storage: storages[i], MountDetails: details[i]}
+	}
 	common.SuccessResp(c, common.PageResp{
-		Content: storages,
+		Content: resp,
 		Total:   total,
 	})
 }
 
+type StorageResp struct {
+	model.Storage
+	MountDetails *model.StorageDetails `json:"mount_details,omitempty"`
+}
+
+// the drivers get the space usage with an api request, the slow ones are left out
+const storageDetailsTimeout = 5 * time.Second
+
+// getStoragesDetails gets the space usage of the storages at the same time,
+// it's nil for the storages which are not loaded or can't tell it
+func getStoragesDetails(ctx context.Context, storages []model.Storage) []*model.StorageDetails {
+	ctx, cancel := context.WithTimeout(ctx, storageDetailsTimeout)
+	defer cancel()
+	res := make([]*model.StorageDetails, len(storages))
+	var wg sync.WaitGroup
+	for i := range storages {
+		storage, err := op.GetStorageByMountPath(storages[i].MountPath)
+		if err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			details, err := op.GetStorageDetails(ctx, storage)
+			if err != nil {
+				if !errs.IsNotImplement(err) {
+					log.Warnf("failed get details of storage [%s]: %+v", storages[i].MountPath, err)
+				}
+				return
+			}
+			res[i] = details
+		}(i)
+	}
+	wg.Wait()
+	return res
+}
+
+type StorageDetailsResp struct {
+	MountPath string                `json:"mount_path"`
+	Driver    string                `json:"driver"`
+	Details   *model.StorageDetails `json:"details"`
+}
+
+// GetStoragesDetails is the summary of the space usage of every mount,
+// the storages which can't tell it are not included
+func GetStoragesDetails(c *gin.Context) {
+	storages := op.GetAllStorages()
+	models := make([]model.Storage, len(storages))
+	for i, storage := range storages {
+		models[i] = *storage.GetStorage()
+	}
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].MountPath < models[j].MountPath
+	})
+	details := getStoragesDetails(c, models)
+	var total model.StorageDetails
+	resp := make([]StorageDetailsResp, 0, len(models))
+	for i := range models {
+		if details[i] == nil {
+			continue
+		}
+		resp = append(resp, StorageDetailsResp{
+			MountPath: models[i].MountPath,
+			Driver:    models[i].Driver,
+			Details:   details[i],
+		})
+		// the storages of a balance group share the same space
+		if utils.GetActualMountPath(models[i].MountPath) == models[i].MountPath {
+			total.Add(details[i])
+		}
+	}
+	common.SuccessResp(c, gin.H{
+		"storages": resp,
+		"total":    total,
+	})
+}
+
 func CreateStorage(c *gin.Context) {
 	var req model.Storage
 	if err := c.ShouldBind(&req); err != nil {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if id, err := op.CreateStorage(c, req); err != nil {
+	id, err := op.CreateStorage(c, req)
+	audit.Log(c, model.AuditStorageCreate, req.MountPath, "", err)
+	if err != nil {
 		common.ErrorWithDataResp(c, err, 500, gin.H{
 			"id": id,
 		}, true)
@@ -55,7 +145,9 @@ func UpdateStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.UpdateStorage(c, req); err != nil {
+	err := op.UpdateStorage(c, req)
+	audit.Log(c, model.AuditStorageUpdate, req.MountPath, "", err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
 		common.SuccessResp(c)
@@ -69,7 +161,9 @@ func DeleteStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DeleteStorageById(c, uint(id)); err != nil {
+	err = op.DeleteStorageById(c, uint(id))
+	audit.Log(c, model.AuditStorageDelete, "", idStr, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -83,7 +177,9 @@ func DisableStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DisableStorage(c, uint(id)); err != nil {
+	err = op.DisableStorage(c, uint(id))
+	audit.Log(c, model.AuditStorageDisable, "", idStr, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -97,7 +193,9 @@ func EnableStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.EnableStorage(c, uint(id)); err != nil {
+	err = op.EnableStorage(c, uint(id))
+	audit.Log(c, model.AuditStorageEnable, "", idStr, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
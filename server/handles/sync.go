@@ -0,0 +1,144 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/mirror"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListSyncJobs(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	jobs, total, err := db.GetSyncJobs(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: jobs,
+		Total:   total,
+	})
+}
+
+func GetSyncJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	job, err := db.GetSyncJobById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, job)
+}
+
+func validSyncJob(job *model.SyncJob) string {
+	if job.Mode == "" {
+		job.Mode = model.SyncOneWay
+	}
+	if job.Mode != model.SyncOneWay && job.Mode != model.SyncTwoWay {
+		return "invalid sync mode"
+	}
+	job.SrcPath = utils.FixAndCleanPath(job.SrcPath)
+	job.DstPath = utils.FixAndCleanPath(job.DstPath)
+	if utils.IsSubPath(job.SrcPath, job.DstPath) || utils.IsSubPath(job.DstPath, job.SrcPath) {
+		return "src and dst can't contain each other"
+	}
+	return ""
+}
+
+func CreateSyncJob(c *gin.Context) {
+	var req model.SyncJob
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if msg := validSyncJob(&req); msg != "" {
+		common.ErrorStrResp(c, msg, 400)
+		return
+	}
+	if err := db.CreateSyncJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	mirror.Schedule(req)
+	common.SuccessResp(c)
+}
+
+func UpdateSyncJob(c *gin.Context) {
+	var req model.SyncJob
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if msg := validSyncJob(&req); msg != "" {
+		common.ErrorStrResp(c, msg, 400)
+		return
+	}
+	old, err := db.GetSyncJobById(req.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	req.LastRunAt, req.LastResult = old.LastRunAt, old.LastResult
+	if err := db.UpdateSyncJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	mirror.Schedule(req)
+	common.SuccessResp(c)
+}
+
+func DeleteSyncJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := db.DeleteSyncJobById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	mirror.Unschedule(uint(id))
+	common.SuccessResp(c)
+}
+
+type RunSyncJobReq struct {
+	ID     uint `json:"id" form:"id"`
+	DryRun bool `json:"dry_run" form:"dry_run"`
+}
+
+// RunSyncJob runs a job at once, with dry_run it only returns what would be done
+func RunSyncJob(c *gin.Context) {
+	var req RunSyncJobReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	job, err := db.GetSyncJobById(req.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	actions, err := mirror.Run(c, job, req.DryRun)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"actions": actions,
+	})
+}
@@ -2,9 +2,14 @@ package handles
 
 import (
 	"math"
+	"strconv"
 
+	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/mirror"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
@@ -12,87 +17,201 @@ import (
 )
 
 type TaskInfo struct {
-	ID       string      `json:"id"`
-	Name     string      `json:"name"`
-	State    tache.State `json:"state"`
-	Status   string      `json:"status"`
-	Progress float64     `json:"progress"`
-	Error    string      `json:"error"`
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	Creator   string      `json:"creator"`
+	CreatorID uint        `json:"creator_id"`
+	State     tache.State `json:"state"`
+	Status    string      `json:"status"`
+	Progress  float64     `json:"progress"`
+	Error     string      `json:"error"`
 }
 
-func getTaskInfo[T tache.TaskWithInfo](task T) TaskInfo {
+func getTaskInfo[T tache.TaskWithInfo](t T) TaskInfo {
 	errMsg := ""
-	if task.GetErr() != nil {
-		errMsg = task.GetErr().Error()
+	if t.GetErr() != nil {
+		errMsg = t.GetErr().Error()
 	}
-	progress := task.GetProgress()
+	progress := t.GetProgress()
 	// if progress is NaN, set it to 100
 	if math.IsNaN(progress) {
 		progress = 100
 	}
-	return TaskInfo{
-		ID:       task.GetID(),
-		Name:     task.GetName(),
-		State:    task.GetState(),
-		Status:   task.GetStatus(),
+	info := TaskInfo{
+		ID:       t.GetID(),
+		Name:     t.GetName(),
+		State:    t.GetState(),
+		Status:   t.GetStatus(),
 		Progress: progress,
 		Error:    errMsg,
 	}
+	if c, ok := any(t).(task.WithCreator); ok {
+		info.Creator = c.GetCreatorName()
+		info.CreatorID = c.GetCreatorID()
+	}
+	return info
 }
 
 func getTaskInfos[T tache.TaskWithInfo](tasks []T) []TaskInfo {
 	return utils.MustSliceConvert(tasks, getTaskInfo[T])
 }
 
-func taskRoute[T tache.TaskWithInfo](g *gin.RouterGroup, manager *tache.Manager[T]) {
+// taskFilter returns whether the task is visible to the request
+type taskFilter func(c *gin.Context, t tache.TaskWithInfo) bool
+
+// adminTaskFilter shows all the tasks, or the tasks created by the user_id in query
+func adminTaskFilter(c *gin.Context, t tache.TaskWithInfo) bool {
+	uid := c.Query("user_id")
+	return uid == "" || uid == strconv.FormatUint(uint64(task.CreatorIDOf(t)), 10)
+}
+
+// userTaskFilter shows the tasks created by the current user only, the guests share the same user so they see none
+func userTaskFilter(c *gin.Context, t tache.TaskWithInfo) bool {
+	user := c.MustGet("user").(*model.User)
+	return !user.IsGuest() && task.CreatorIDOf(t) == user.ID
+}
+
+func filterTasks[T tache.TaskWithInfo](c *gin.Context, tasks []T, filter taskFilter) []T {
+	var res []T
+	for _, t := range tasks {
+		if filter(c, t) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+var (
+	undoneStates = []tache.State{tache.StatePending, tache.StateRunning, tache.StateCanceling,
+		tache.StateErrored, tache.StateFailing, tache.StateWaitingRetry, tache.StateBeforeRetry}
+	doneStates = []tache.State{tache.StateCanceled, tache.StateFailed, tache.StateSucceeded}
+)
+
+func taskRoute[T tache.TaskWithInfo](g *gin.RouterGroup, manager *tache.Manager[T], filter taskFilter) {
+	// getTask responds 404 if the task is not visible to the request
+	getTask := func(c *gin.Context) (T, bool) {
+		t, ok := manager.GetByID(c.Query("tid"))
+		if !ok || !filter(c, t) {
+			common.ErrorStrResp(c, "task not found", 404)
+			return t, false
+		}
+		return t, true
+	}
 	g.GET("/undone", func(c *gin.Context) {
-		common.SuccessResp(c, getTaskInfos(manager.GetByState(tache.StatePending, tache.StateRunning,
-			tache.StateCanceling, tache.StateErrored, tache.StateFailing, tache.StateWaitingRetry, tache.StateBeforeRetry)))
+		common.SuccessResp(c, getTaskInfos(filterTasks(c, manager.GetByState(undoneStates...), filter)))
 	})
 	g.GET("/done", func(c *gin.Context) {
-		common.SuccessResp(c, getTaskInfos(manager.GetByState(tache.StateCanceled, tache.StateFailed, tache.StateSucceeded)))
+		common.SuccessResp(c, getTaskInfos(filterTasks(c, manager.GetByState(doneStates...), filter)))
 	})
 	g.POST("/info", func(c *gin.Context) {
-		tid := c.Query("tid")
-		task, ok := manager.GetByID(tid)
-		if !ok {
-			common.ErrorStrResp(c, "task not found", 404)
-			return
+		if t, ok := getTask(c); ok {
+			common.SuccessResp(c, getTaskInfo(t))
 		}
-		common.SuccessResp(c, getTaskInfo(task))
 	})
 	g.POST("/cancel", func(c *gin.Context) {
-		tid := c.Query("tid")
-		manager.Cancel(tid)
-		common.SuccessResp(c)
+		if t, ok := getTask(c); ok {
+			manager.Cancel(t.GetID())
+			common.SuccessResp(c)
+		}
 	})
 	g.POST("/delete", func(c *gin.Context) {
-		tid := c.Query("tid")
-		manager.Remove(tid)
-		common.SuccessResp(c)
+		if t, ok := getTask(c); ok {
+			manager.Remove(t.GetID())
+			common.SuccessResp(c)
+		}
 	})
 	g.POST("/retry", func(c *gin.Context) {
-		tid := c.Query("tid")
-		manager.Retry(tid)
-		common.SuccessResp(c)
+		if t, ok := getTask(c); ok {
+			manager.Retry(t.GetID())
+			common.SuccessResp(c)
+		}
 	})
 	g.POST("/clear_done", func(c *gin.Context) {
-		manager.RemoveByState(tache.StateCanceled, tache.StateFailed, tache.StateSucceeded)
+		for _, t := range filterTasks(c, manager.GetByState(doneStates...), filter) {
+			manager.Remove(t.GetID())
+		}
 		common.SuccessResp(c)
 	})
 	g.POST("/clear_succeeded", func(c *gin.Context) {
-		manager.RemoveByState(tache.StateSucceeded)
+		for _, t := range filterTasks(c, manager.GetByState(tache.StateSucceeded), filter) {
+			manager.Remove(t.GetID())
+		}
 		common.SuccessResp(c)
 	})
 	g.POST("/retry_failed", func(c *gin.Context) {
-		manager.RetryAllFailed()
+		for _, t := range filterTasks(c, manager.GetByState(tache.StateFailed), filter) {
+			manager.Retry(t.GetID())
+		}
 		common.SuccessResp(c)
 	})
 }
 
+type TaskHistoryReq struct {
+	model.PageReq
+	Type   string `json:"type" form:"type"`
+	State  []int  `json:"state" form:"state"`
+	UserID uint   `json:"user_id" form:"user_id"` // only for admin, 0 means all users
+}
+
+// ListTaskHistory lists the tasks saved in db, including the finished ones removed from the managers
+func ListTaskHistory(c *gin.Context) {
+	var req TaskHistoryReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	if user := c.MustGet("user").(*model.User); !user.IsAdmin() {
+		req.UserID = user.ID
+	}
+	items, total, err := db.GetTaskItems(req.Type, req.State, req.UserID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: items,
+		Total:   total,
+	})
+}
+
+func DeleteTaskHistory(c *gin.Context) {
+	tid := c.Query("tid")
+	if err := db.DeleteTaskItemById(tid); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// ClearTaskHistory removes the finished tasks of the type from db, all types if it's empty
+func ClearTaskHistory(c *gin.Context) {
+	typ := c.Query("type")
+	if err := db.DeleteTaskItemsByState(typ, task.FinishedStates...); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func setupTaskRoute(g *gin.RouterGroup, filter taskFilter) {
+	taskRoute(g.Group("/upload"), fs.UploadTaskManager, filter)
+	taskRoute(g.Group("/copy"), fs.CopyTaskManager, filter)
+	taskRoute(g.Group("/extract"), fs.ExtractTaskManager, filter)
+	taskRoute(g.Group("/compress"), fs.CompressTaskManager, filter)
+	taskRoute(g.Group("/sync"), mirror.TaskManager, filter)
+	taskRoute(g.Group("/offline_download"), tool.DownloadTaskManager, filter)
+	taskRoute(g.Group("/offline_download_transfer"), tool.TransferTaskManager, filter)
+	g.GET("/history", ListTaskHistory)
+}
+
 func SetupTaskRoute(g *gin.RouterGroup) {
-	taskRoute(g.Group("/upload"), fs.UploadTaskManager)
-	taskRoute(g.Group("/copy"), fs.CopyTaskManager)
-	taskRoute(g.Group("/offline_download"), tool.DownloadTaskManager)
-	taskRoute(g.Group("/offline_download_transfer"), tool.TransferTaskManager)
+	setupTaskRoute(g, adminTaskFilter)
+	g.POST("/history/delete", DeleteTaskHistory)
+	g.POST("/history/clear", ClearTaskHistory)
+}
+
+// SetupUserTaskRoute the users can only see and manage the tasks created by themselves
+func SetupUserTaskRoute(g *gin.RouterGroup) {
+	setupTaskRoute(g, userTaskFilter)
 }
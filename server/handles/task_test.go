@@ -0,0 +1,96 @@
+package handles
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/gin-gonic/gin"
+	"github.com/xhofe/tache"
+)
+
+type testTask struct {
+	tache.Base
+	task.Creator
+	Name string
+}
+
+func (t *testTask) GetName() string {
+	return t.Name
+}
+
+func (t *testTask) GetStatus() string {
+	return ""
+}
+
+func (t *testTask) Run() error {
+	return nil
+}
+
+func TestUserTaskFilter(t *testing.T) {
+	owner, other := newTestUser(t, "task_owner"), newTestUser(t, "task_other")
+	guest := &model.User{Username: "guest", Role: model.GUEST}
+	// the tasks are kept pending
+	manager := tache.NewManager[*testTask](tache.WithRunning(false))
+	mine := &testTask{Name: "mine", Creator: task.Creator{UserID: owner.ID, Username: owner.Username}}
+	theirs := &testTask{Name: "theirs", Creator: task.Creator{UserID: other.ID, Username: other.Username}}
+	anonymous := &testTask{Name: "anonymous"}
+	manager.Add(mine)
+	manager.Add(theirs)
+	manager.Add(anonymous)
+
+	request := func(user *model.User, method, url string) (code int, data json.RawMessage) {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			c.Set("user", user)
+		})
+		taskRoute(r.Group("/task"), manager, userTaskFilter)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, url, nil))
+		var resp struct {
+			Code int             `json:"code"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed decode %s: %v", w.Body.String(), err)
+		}
+		return resp.Code, resp.Data
+	}
+	names := func(user *model.User) []string {
+		_, data := request(user, http.MethodGet, "/task/undone")
+		var infos []TaskInfo
+		if err := json.Unmarshal(data, &infos); err != nil {
+			t.Fatal(err)
+		}
+		var res []string
+		for _, info := range infos {
+			res = append(res, info.Name)
+		}
+		return res
+	}
+
+	if res := names(owner); len(res) != 1 || res[0] != "mine" {
+		t.Errorf("the user should only see the own tasks, got %v", res)
+	}
+	if res := names(guest); len(res) != 0 {
+		t.Errorf("the guest should see no tasks, got %v", res)
+	}
+	if code, _ := request(owner, http.MethodPost, "/task/info?tid="+theirs.GetID()); code != 404 {
+		t.Errorf("the task of the others should not be found, got %d", code)
+	}
+	if code, _ := request(owner, http.MethodPost, "/task/delete?tid="+theirs.GetID()); code != 404 {
+		t.Errorf("the task of the others should not be deleted, got %d", code)
+	}
+	if _, ok := manager.GetByID(theirs.GetID()); !ok {
+		t.Errorf("the task of the others should be kept")
+	}
+	if code, _ := request(owner, http.MethodPost, "/task/delete?tid="+mine.GetID()); code != 200 {
+		t.Errorf("the own task should be deleted, got %d", code)
+	}
+	if _, ok := manager.GetByID(mine.GetID()); ok {
+		t.Errorf("the own task should be removed")
+	}
+}
@@ -0,0 +1,39 @@
+package handles
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/thumb"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// Thumb serves the thumbnail generated by alist of the file
+func Thumb(c *gin.Context) {
+	rawPath := c.MustGet("path").(string)
+	thumbPath, err := thumb.Get(c, rawPath)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	c.Header("Cache-Control", "max-age=86400")
+	http.ServeFile(c.Writer, c.Request, thumbPath)
+}
+
+// getThumb returns the thumbnail of the driver, or the url of the thumbnail generated by alist if it can be generated,
+// the files which user can't read have no thumbnail
+func getThumb(r *http.Request, user *model.User, obj model.Obj, path string) string {
+	if !obj.IsDir() && !common.HasPathPermission(user, path, model.AclRead, true) {
+		return ""
+	}
+	if t, ok := model.GetThumb(obj); ok {
+		return t
+	}
+	if obj.IsDir() || !thumb.Supported(obj.GetName()) {
+		return ""
+	}
+	return fmt.Sprintf("%s/t%s?sign=%s", common.GetApiUrl(r), utils.EncodePath(path, true), thumb.Sign(path))
+}
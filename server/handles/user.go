@@ -3,6 +3,7 @@ package handles
 import (
 	"strconv"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/server/common"
@@ -42,7 +43,9 @@ func CreateUser(c *gin.Context) {
 	req.SetPassword(req.Password)
 	req.Password = ""
 	req.Authn = "[]"
-	if err := op.CreateUser(&req); err != nil {
+	err := op.CreateUser(&req)
+	audit.Log(c, model.AuditUserCreate, req.BasePath, req.Username, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
 		common.SuccessResp(c)
@@ -78,7 +81,9 @@ func UpdateUser(c *gin.Context) {
 		common.ErrorStrResp(c, "admin user can not be disabled", 400)
 		return
 	}
-	if err := op.UpdateUser(&req); err != nil {
+	err = op.UpdateUser(&req)
+	audit.Log(c, model.AuditUserUpdate, req.BasePath, req.Username, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c)
@@ -92,7 +97,9 @@ func DeleteUser(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DeleteUserById(uint(id)); err != nil {
+	err = op.DeleteUserById(uint(id))
+	audit.Log(c, model.AuditUserDelete, "", idStr, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -121,7 +128,9 @@ func Cancel2FAById(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.Cancel2FAById(uint(id)); err != nil {
+	err = op.Cancel2FAById(uint(id))
+	audit.Log(c, model.AuditUserCancel2FA, "", idStr, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -137,3 +146,32 @@ func DelUserCache(c *gin.Context) {
 	}
 	common.SuccessResp(c)
 }
+
+func GetUserUsage(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	usage, err := op.GetUserUsage(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, usage)
+}
+
+func ResetUserUsage(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.ResetUserUsage(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
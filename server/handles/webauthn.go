@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/authn"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
@@ -107,6 +108,7 @@ func FinishAuthnLogin(c *gin.Context) {
 		}, sessionData, c.Request)
 	}
 	if err != nil {
+		audit.Login(c, c.Query("username"), err)
 		common.ErrorResp(c, err, 400)
 		return
 	}
@@ -116,6 +118,7 @@ func FinishAuthnLogin(c *gin.Context) {
 		common.ErrorResp(c, err, 400, true)
 		return
 	}
+	audit.Login(c, user.Username, nil)
 	common.SuccessResp(c, gin.H{"token": token})
 }
 
@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestInfo puts the client ip and the protocol into the context for the audit log
+func RequestInfo(protocol string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(conf.ClientIPKey, c.ClientIP())
+		c.Set(conf.ProtocolKey, protocol)
+		c.Next()
+	}
+}
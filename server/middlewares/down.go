@@ -10,6 +10,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/internal/thumb"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
@@ -27,8 +28,9 @@ func Down(c *gin.Context) {
 		}
 	}
 	c.Set("meta", meta)
-	// verify sign
-	if needSign(meta, rawPath) {
+	// verify sign, the links without sign are checked as the guest,
+	// so the files the guest can't read are only downloaded with a sign
+	if needSign(meta, rawPath) || !common.GuestCanRead(rawPath) {
 		s := c.Query("sign")
 		err = sign.Verify(rawPath, strings.TrimSuffix(s, "/"))
 		if err != nil {
@@ -40,6 +42,18 @@ func Down(c *gin.Context) {
 	c.Next()
 }
 
+// Thumb verifies the sign of the thumbnail url, which is only given to the users who can read the file
+func Thumb(c *gin.Context) {
+	rawPath := parsePath(c.Param("path"))
+	c.Set("path", rawPath)
+	if err := thumb.Verify(rawPath, strings.TrimSuffix(c.Query("sign"), "/")); err != nil {
+		common.ErrorResp(c, err, 401)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
 // TODO: implement
 // path maybe contains # ? etc.
 func parsePath(path string) string {
@@ -35,10 +35,16 @@ func FsUp(c *gin.Context) {
 			return
 		}
 	}
-	if !(common.CanAccess(user, meta, path, password) && (user.CanWrite() || common.CanWrite(meta, stdpath.Dir(path)))) {
+	canWrite := common.HasPathPermission(user, path, model.AclWrite, user.CanWrite() || common.CanWrite(meta, stdpath.Dir(path)))
+	if !(common.CanAccess(user, meta, path, password) && canWrite) {
 		common.ErrorResp(c, errs.PermissionDenied, 403)
 		c.Abort()
 		return
 	}
+	if err := op.CheckQuota(user, max(c.Request.ContentLength, 0), 1); err != nil {
+		common.ErrorResp(c, err, 403)
+		c.Abort()
+		return
+	}
 	c.Next()
 }
@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics counts the requests by protocol and route, the protocol is taken from the path if it's empty
+func Metrics(protocol string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := protocol
+		if p == "" {
+			p = pathProtocol(c.Request.URL.Path)
+		}
+		c.Request = c.Request.WithContext(metrics.WithProtocol(c.Request.Context(), p))
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveRequest(p, route, c.Request.Method, strconv.Itoa(c.Writer.Status()))
+	}
+}
+
+func pathProtocol(path string) string {
+	path = strings.TrimPrefix(path, strings.TrimSuffix(conf.URL.Path, "/"))
+	first, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	switch first {
+	case "api", "d", "p", "dav", "s3":
+		return first
+	case "s":
+		return "share"
+	default:
+		return "other"
+	}
+}
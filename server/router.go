@@ -4,6 +4,7 @@ import (
 	"github.com/alist-org/alist/v3/cmd/flags"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/message"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/alist/v3/server/handles"
@@ -20,13 +21,14 @@ func Init(e *gin.Engine) {
 		})
 	}
 	Cors(e)
-	g := e.Group(conf.URL.Path)
+	g := e.Group(conf.URL.Path, middlewares.Metrics(""))
 	if conf.Conf.Scheme.HttpPort != -1 && conf.Conf.Scheme.HttpsPort != -1 && conf.Conf.Scheme.ForceHttps {
 		e.Use(middlewares.ForceHttps)
 	}
 	g.Any("/ping", func(c *gin.Context) {
 		c.String(200, "pong")
 	})
+	g.GET("/metrics", handles.Metrics)
 	g.GET("/favicon.ico", handles.Favicon)
 	g.GET("/robots.txt", handles.Robots)
 	g.GET("/i/:link_name", handles.Plist)
@@ -42,8 +44,14 @@ func Init(e *gin.Engine) {
 	g.GET("/p/*path", middlewares.Down, handles.Proxy)
 	g.HEAD("/d/*path", middlewares.Down, handles.Down)
 	g.HEAD("/p/*path", middlewares.Down, handles.Proxy)
-
-	api := g.Group("/api")
+	g.GET("/t/*path", middlewares.Thumb, handles.Thumb)
+	g.GET("/s/:id", handles.ShareGet)
+	g.POST("/s/:id", handles.ShareAuth)
+	g.GET("/s/:id/*path", handles.ShareGet)
+	g.HEAD("/s/:id", handles.ShareGet)
+	g.HEAD("/s/:id/*path", handles.ShareGet)
+
+	api := g.Group("/api", middlewares.RequestInfo(model.ProtocolWeb))
 	auth := api.Group("", middlewares.Auth)
 	webauthn := api.Group("/authn", middlewares.Authn)
 
@@ -74,7 +82,13 @@ func Init(e *gin.Engine) {
 	public.Any("/settings", handles.PublicSettings)
 	public.Any("/offline_download_tools", handles.OfflineDownloadTools)
 
+	share := auth.Group("/share")
+	share.GET("/list", handles.ListShares)
+	share.POST("/create", handles.CreateShare)
+	share.POST("/delete", handles.DeleteShare)
+
 	_fs(auth.Group("/fs"))
+	handles.SetupUserTaskRoute(auth.Group("/task"))
 	admin(auth.Group("/admin", middlewares.AuthAdmin))
 	if flags.Debug || flags.Dev {
 		debug(g.Group("/debug"))
@@ -100,6 +114,29 @@ func admin(g *gin.RouterGroup) {
 	user.POST("/cancel_2fa", handles.Cancel2FAById)
 	user.POST("/delete", handles.DeleteUser)
 	user.POST("/del_cache", handles.DelUserCache)
+	user.GET("/usage", handles.GetUserUsage)
+	user.POST("/reset_usage", handles.ResetUserUsage)
+
+	g.GET("/audit", handles.ListAuditLogs)
+
+	webhook := g.Group("/webhook")
+	webhook.GET("/list", handles.ListWebhooks)
+	webhook.GET("/get", handles.GetWebhook)
+	webhook.POST("/create", handles.CreateWebhook)
+	webhook.POST("/update", handles.UpdateWebhook)
+	webhook.POST("/delete", handles.DeleteWebhook)
+	webhook.GET("/deliveries", handles.ListWebhookDeliveries)
+
+	share := g.Group("/share")
+	share.GET("/list", handles.ListAllShares)
+	share.POST("/delete", handles.DeleteShare)
+
+	group := g.Group("/group")
+	group.GET("/list", handles.ListGroups)
+	group.GET("/get", handles.GetGroup)
+	group.POST("/create", handles.CreateGroup)
+	group.POST("/update", handles.UpdateGroup)
+	group.POST("/delete", handles.DeleteGroup)
 
 	storage := g.Group("/storage")
 	storage.GET("/list", handles.ListStorages)
@@ -110,6 +147,9 @@ func admin(g *gin.RouterGroup) {
 	storage.POST("/enable", handles.EnableStorage)
 	storage.POST("/disable", handles.DisableStorage)
 	storage.POST("/load_all", handles.LoadAllStorages)
+	storage.GET("/health", handles.ListStorageHealth)
+	storage.GET("/details", handles.GetStoragesDetails)
+	storage.GET("/health/history", handles.ListStorageHealthHistory)
 
 	driver := g.Group("/driver")
 	driver.GET("/list", handles.ListDriverInfo)
@@ -128,6 +168,27 @@ func admin(g *gin.RouterGroup) {
 	task := g.Group("/task")
 	handles.SetupTaskRoute(task)
 
+	sync := g.Group("/sync")
+	sync.GET("/list", handles.ListSyncJobs)
+	sync.GET("/get", handles.GetSyncJob)
+	sync.POST("/create", handles.CreateSyncJob)
+	sync.POST("/update", handles.UpdateSyncJob)
+	sync.POST("/delete", handles.DeleteSyncJob)
+	sync.POST("/run", handles.RunSyncJob)
+
+	trash := g.Group("/trash")
+	trash.GET("/list", handles.ListTrash)
+	trash.POST("/restore", handles.RestoreTrash)
+	trash.POST("/purge", handles.PurgeTrash)
+	trash.POST("/clear", handles.ClearTrash)
+
+	acl := g.Group("/acl")
+	acl.GET("/list", handles.ListAclRules)
+	acl.GET("/get", handles.GetAclRule)
+	acl.POST("/create", handles.CreateAclRule)
+	acl.POST("/update", handles.UpdateAclRule)
+	acl.POST("/delete", handles.DeleteAclRule)
+
 	ms := g.Group("/message")
 	ms.POST("/get", message.HttpInstance.GetHandle)
 	ms.POST("/send", message.HttpInstance.SendHandle)
@@ -153,6 +214,9 @@ func _fs(g *gin.RouterGroup) {
 	g.POST("/move", handles.FsMove)
 	g.POST("/recursive_move", handles.FsRecursiveMove)
 	g.POST("/copy", handles.FsCopy)
+	g.POST("/extract", handles.FsExtract)
+	g.POST("/compress", handles.FsCompress)
+	g.POST("/archive_download", handles.FsArchiveDownload)
 	g.POST("/remove", handles.FsRemove)
 	g.POST("/remove_empty_directory", handles.FsRemoveEmptyDirectory)
 	g.PUT("/put", middlewares.FsUp, handles.FsStream)
@@ -174,5 +238,5 @@ func Cors(r *gin.Engine) {
 
 func InitS3(e *gin.Engine) {
 	Cors(e)
-	S3Server(e.Group("/"))
+	S3Server(e.Group("/", middlewares.Metrics("s3")))
 }
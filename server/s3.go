@@ -2,10 +2,12 @@ package server
 
 import (
 	"context"
+	"net/http"
 	"path"
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/alist/v3/server/s3"
 	"github.com/gin-gonic/gin"
@@ -29,11 +31,20 @@ func S3(g *gin.RouterGroup) {
 	g.Any("/*path", func(c *gin.Context) {
 		adjustedPath := strings.TrimPrefix(c.Request.URL.Path, path.Join(conf.URL.Path, "/s3"))
 		c.Request.URL.Path = adjustedPath
-		gin.WrapH(h)(c)
+		serveS3(c, h)
 	})
 }
 
 func S3Server(g *gin.RouterGroup) {
 	h, _ := s3.NewServer(context.Background())
-	g.Any("/*path", gin.WrapH(h))
+	g.Any("/*path", func(c *gin.Context) {
+		serveS3(c, h)
+	})
+}
+
+// serveS3 passes the client ip and the protocol to the s3 backend for the bandwidth limits and the audit log
+func serveS3(c *gin.Context, h http.Handler) {
+	ctx := context.WithValue(c.Request.Context(), conf.ClientIPKey, c.ClientIP())
+	ctx = context.WithValue(ctx, conf.ProtocolKey, model.ProtocolS3)
+	h.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
 }
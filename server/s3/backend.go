@@ -12,8 +12,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/bandwidth"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/stream"
@@ -104,6 +106,9 @@ func (b *s3Backend) HeadObject(ctx context.Context, bucketName, objectName strin
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if err := checkAcl(fp, model.AclRead); err != nil {
+		return nil, err
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	node, err := fs.Get(context.WithValue(ctx, "meta", fmeta), fp, &fs.GetArgs{})
 	if err != nil {
@@ -147,6 +152,9 @@ func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if err := checkAcl(fp, model.AclRead); err != nil {
+		return nil, err
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	node, err := fs.Get(context.WithValue(ctx, "meta", fmeta), fp, &fs.GetArgs{})
 	if err != nil {
@@ -243,7 +251,7 @@ func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string
 		Metadata: meta,
 		Size:     size,
 		Range:    rnge,
-		Contents: rdr,
+		Contents: metrics.NewProxyReadCloser(ctx, bandwidth.NewReadCloser(ctx, rdr, getLimiters(ctx, fp))),
 	}, nil
 }
 
@@ -266,6 +274,9 @@ func (b *s3Backend) PutObject(
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if err := checkAcl(fp, model.AclWrite); err != nil {
+		return result, err
+	}
 	reqPath := path.Dir(fp)
 	fmeta, _ := op.GetNearestMeta(fp)
 	_, err = fs.Get(context.WithValue(ctx, "meta", fmeta), reqPath, &fs.GetArgs{})
@@ -291,7 +302,7 @@ func (b *s3Backend) PutObject(
 	}
 	stream := &stream.FileStream{
 		Obj:      &obj,
-		Reader:   input,
+		Reader:   bandwidth.NewReader(ctx, input, getLimiters(ctx, fp)),
 		Mimetype: meta["Content-Type"],
 	}
 
@@ -345,6 +356,9 @@ func (b *s3Backend) deleteObject(ctx context.Context, bucketName, objectName str
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if err := checkAcl(fp, model.AclDelete); err != nil {
+		return err
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	// S3 does not report an error when attemping to delete a key that does not exist, so
 	// we need to skip IsNotExist errors.
@@ -6,11 +6,15 @@ import (
 	"path"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/gofakes3"
 )
 
 func (b *s3Backend) entryListR(bucket, fdPath, name string, addPrefix bool, response *gofakes3.ObjectList) error {
 	fp := path.Join(bucket, fdPath)
+	if err := checkAcl(fp, model.AclList); err != nil {
+		return err
+	}
 
 	dirEntries, err := getDirEntries(fp)
 	if err != nil {
@@ -33,6 +37,10 @@ func (b *s3Backend) entryListR(bucket, fdPath, name string, addPrefix bool, resp
 				response.AddPrefix(objectPath)
 				continue
 			}
+			// the sub dirs the s3 user can't list are skipped
+			if checkAcl(path.Join(fp, object), model.AclList) != nil {
+				continue
+			}
 			err := b.entryListR(bucket, path.Join(fdPath, object), "", false, response)
 			if err != nil {
 				return err
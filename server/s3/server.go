@@ -23,5 +23,15 @@ func NewServer(ctx context.Context) (h http.Handler, err error) {
 		gofakes3.WithIntegrityCheck(true), // Check Content-MD5 if supplied
 	)
 
-	return faker.Server(), nil
+	return withUser(faker.Server()), nil
+}
+
+// withUser puts the s3 user into the request context for the audit log
+func withUser(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, err := getS3User(); err == nil && user != nil {
+			r = r.WithContext(context.WithValue(r.Context(), "user", user))
+		}
+		h.ServeHTTP(w, r)
+	})
 }
@@ -7,12 +7,14 @@ import (
 	"encoding/json"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/bandwidth"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/gofakes3"
 )
 
@@ -40,6 +42,42 @@ func getBucketByName(name string) (Bucket, error) {
 	return Bucket{}, gofakes3.BucketNotFound(name)
 }
 
+// errAccessDenied is the s3 error code when an acl rule denies the request
+const errAccessDenied gofakes3.ErrorCode = "AccessDenied"
+
+// checkAcl checks the acl rules of the s3 user for the action on reqPath,
+// the guest is checked if the s3 user isn't set.
+func checkAcl(reqPath string, action int32) error {
+	user, err := getS3User()
+	if err != nil {
+		return err
+	}
+	p, err := user.JoinPath(reqPath)
+	if err != nil {
+		return errAccessDenied
+	}
+	if !common.HasPathPermission(user, p, action, true) {
+		return errAccessDenied
+	}
+	return nil
+}
+
+// getS3User returns the user set as the s3 user, the guest if it's not set
+func getS3User() (*model.User, error) {
+	username := setting.GetStr(conf.S3User)
+	if username == "" {
+		return op.GetGuest()
+	}
+	return op.GetUserByName(username)
+}
+
+// getLimiters returns the bandwidth limiters of a transfer of reqPath
+func getLimiters(ctx context.Context, reqPath string) bandwidth.Limiters {
+	user, _ := getS3User()
+	ip, _ := ctx.Value(conf.ClientIPKey).(string)
+	return bandwidth.GetByPath(user, reqPath, ip)
+}
+
 func getDirEntries(path string) ([]model.Obj, error) {
 	ctx := context.Background()
 	meta, _ := op.GetNearestMeta(path)
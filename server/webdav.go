@@ -4,16 +4,23 @@ import (
 	"context"
 	"crypto/subtle"
 	"net/http"
+	"net/url"
 	"path"
 	"strings"
+	"time"
 
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/bandwidth"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
-	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/alist-org/alist/v3/server/middlewares"
 	"github.com/alist-org/alist/v3/server/webdav"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -27,7 +34,7 @@ func WebDav(dav *gin.RouterGroup) {
 			log.Errorf("%s %s %+v", request.Method, request.URL.Path, err)
 		},
 	}
-	dav.Use(WebDAVAuth)
+	dav.Use(middlewares.RequestInfo(model.ProtocolWebDAV), WebDAVAuth)
 	dav.Any("/*path", ServeWebDAV)
 	dav.Any("", ServeWebDAV)
 	dav.Handle("PROPFIND", "/*path", ServeWebDAV)
@@ -42,8 +49,88 @@ func WebDav(dav *gin.RouterGroup) {
 
 func ServeWebDAV(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
+	if !webdavAcl(c.Request, user) {
+		c.Status(http.StatusForbidden)
+		return
+	}
 	ctx := context.WithValue(c.Request.Context(), "user", user)
-	handler.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	ctx = context.WithValue(ctx, conf.ClientIPKey, c.ClientIP())
+	ctx = context.WithValue(ctx, conf.ProtocolKey, model.ProtocolWebDAV)
+	w := http.ResponseWriter(c.Writer)
+	switch c.Request.Method {
+	case "GET", "PUT":
+		if reqPath, err := webdavPath(c.Request.URL.Path, user); err == nil {
+			limiters := bandwidth.GetByPath(user, reqPath, c.ClientIP())
+			if c.Request.Method == "GET" {
+				w = bandwidth.NewResponseWriter(ctx, w, limiters)
+			} else {
+				c.Request.Body = bandwidth.NewReadCloser(ctx, c.Request.Body, limiters)
+			}
+		}
+	}
+	handler.ServeHTTP(w, c.Request.WithContext(ctx))
+}
+
+// webdavAcl checks the acl rules for the paths of the request,
+// the webdav permissions of user are used if no rule decides.
+func webdavAcl(r *http.Request, user *model.User) bool {
+	reqPath, err := webdavPath(r.URL.Path, user)
+	if err != nil {
+		// the webdav handler responds the invalid path
+		return true
+	}
+	manage := user.CanWebdavManage()
+	switch r.Method {
+	case "GET", "HEAD", "POST":
+		return common.HasPathPermission(user, reqPath, model.AclRead, true)
+	case "PROPFIND":
+		return common.HasPathPermission(user, reqPath, model.AclList, true)
+	case "PUT", "MKCOL", "PROPPATCH":
+		return common.HasPathPermission(user, reqPath, model.AclWrite, manage)
+	case "DELETE":
+		return common.HasPathPermission(user, reqPath, model.AclDelete, manage)
+	case "COPY", "MOVE":
+		u, err := url.Parse(r.Header.Get("Destination"))
+		if err != nil {
+			return true
+		}
+		dstPath, err := webdavPath(u.Path, user)
+		if err != nil {
+			return true
+		}
+		if !common.HasPathPermission(user, dstPath, model.AclWrite, manage) {
+			return false
+		}
+		if r.Method == "COPY" {
+			return common.HasPathPermission(user, reqPath, model.AclRead, manage)
+		}
+		if path.Dir(reqPath) == path.Dir(dstPath) {
+			return common.HasPathPermission(user, reqPath, model.AclRename, manage)
+		}
+		return common.HasPathPermission(user, reqPath, model.AclDelete, manage)
+	}
+	return true
+}
+
+func webdavPath(urlPath string, user *model.User) (string, error) {
+	reqPath := strings.TrimPrefix(urlPath, handler.Prefix)
+	return user.JoinPath(reqPath)
+}
+
+// webdavLoginTTL is how long the successful logins of a user from the same ip are logged once,
+// the clients send the credentials with every request
+const webdavLoginTTL = 30 * time.Minute
+
+var webdavLogins = cache.NewMemCache(cache.WithShards[struct{}](16))
+
+// auditWebDAVLogin logs the successful login of username unless it's logged in the ttl
+func auditWebDAVLogin(c *gin.Context, username string) {
+	key := username + "@" + c.ClientIP()
+	if _, ok := webdavLogins.Get(key); ok {
+		return
+	}
+	webdavLogins.Set(key, struct{}{}, cache.WithEx[struct{}](webdavLoginTTL))
+	audit.Login(c, username, nil)
 }
 
 func WebDAVAuth(c *gin.Context) {
@@ -63,6 +150,7 @@ func WebDAVAuth(c *gin.Context) {
 					c.Abort()
 					return
 				}
+				auditWebDAVLogin(c, admin.Username)
 				c.Set("user", admin)
 				c.Next()
 				return
@@ -79,12 +167,16 @@ func WebDAVAuth(c *gin.Context) {
 		return
 	}
 	user, err := op.GetUserByName(username)
-	if err != nil || user.ValidateRawPassword(password) != nil {
+	if err == nil {
+		err = user.ValidateRawPassword(password)
+	}
+	if err != nil {
 		if c.Request.Method == "OPTIONS" {
 			c.Set("user", guest)
 			c.Next()
 			return
 		}
+		audit.Login(c, username, err)
 		c.Status(http.StatusUnauthorized)
 		c.Abort()
 		return
@@ -95,20 +187,12 @@ func WebDAVAuth(c *gin.Context) {
 			c.Next()
 			return
 		}
+		audit.Login(c, username, errors.New("webdav access is not allowed"))
 		c.Status(http.StatusForbidden)
 		c.Abort()
 		return
 	}
-	if !user.CanWebdavManage() && utils.SliceContains([]string{"PUT", "DELETE", "PROPPATCH", "MKCOL", "COPY", "MOVE"}, c.Request.Method) {
-		if c.Request.Method == "OPTIONS" {
-			c.Set("user", guest)
-			c.Next()
-			return
-		}
-		c.Status(http.StatusForbidden)
-		c.Abort()
-		return
-	}
+	auditWebDAVLogin(c, username)
 	c.Set("user", user)
 	c.Next()
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
 )
 
 // slashClean is equivalent to but slightly more efficient than
@@ -56,7 +57,11 @@ func moveFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 // See section 9.8.5 for when various HTTP status codes apply.
 func copyFiles(ctx context.Context, src, dst string, overwrite bool) (status int, err error) {
 	dstDir := path.Dir(dst)
-	_, err = fs.Copy(context.WithValue(ctx, conf.NoTaskKey, struct{}{}), src, dstDir)
+	ctx = context.WithValue(ctx, conf.NoTaskKey, struct{}{})
+	if !overwrite {
+		ctx = context.WithValue(ctx, conf.ConflictPolicyKey, fs.ConflictSkip)
+	}
+	_, err = fs.Copy(ctx, src, dstDir)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
@@ -96,6 +101,9 @@ func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 	if err != nil {
 		return walkFn(name, info, err)
 	}
+	if user, ok := ctx.Value("user").(*model.User); ok {
+		objs = common.FilterVisibleObjs(user, name, objs)
+	}
 
 	for _, fileInfo := range objs {
 		filename := path.Join(name, fileInfo.GetName())
@@ -17,6 +17,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 )
 
@@ -162,13 +163,24 @@ var liveProps = map[xml.Name]struct {
 	},
 }
 
+// quotaProps are the properties of the space usage of the collections, see RFC 4331.
+// They are not live properties as they must not be returned by allprop unless they are included.
+var quotaProps = map[xml.Name]func(*model.StorageDetails) string{
+	{Space: "DAV:", Local: "quota-available-bytes"}: func(d *model.StorageDetails) string {
+		return strconv.FormatInt(d.FreeSpace, 10)
+	},
+	{Space: "DAV:", Local: "quota-used-bytes"}: func(d *model.StorageDetails) string {
+		return strconv.FormatInt(d.UsedSpace, 10)
+	},
+}
+
 // TODO(nigeltao) merge props and allprop?
 
 // Props returns the status of the properties named pnames for resource name.
 //
 // Each Propstat has a unique status and each property name will only be part
 // of one Propstat element.
-func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name) ([]Propstat, error) {
+func props(ctx context.Context, ls LockSystem, reqPath string, fi model.Obj, pnames []xml.Name) ([]Propstat, error) {
 	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
 	//if err != nil {
 	//	return nil, err
@@ -191,12 +203,27 @@ func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name)
 
 	pstatOK := Propstat{Status: http.StatusOK}
 	pstatNotFound := Propstat{Status: http.StatusNotFound}
+	// the space usage is got only if it's asked
+	var details *model.StorageDetails
+	var detailsErr error
 	for _, pn := range pnames {
 		// If this file has dead properties, check if they contain pn.
 		if dp, ok := deadProps[pn]; ok {
 			pstatOK.Props = append(pstatOK.Props, dp)
 			continue
 		}
+		if findFn, ok := quotaProps[pn]; ok && isDir {
+			if details == nil && detailsErr == nil {
+				details, detailsErr = fs.GetDetails(ctx, reqPath)
+			}
+			if detailsErr == nil {
+				pstatOK.Props = append(pstatOK.Props, Property{
+					XMLName:  pn,
+					InnerXML: []byte(findFn(details)),
+				})
+				continue
+			}
+		}
 		// Otherwise, it must either be a live property or we don't know it.
 		if prop := liveProps[pn]; prop.findFn != nil && (prop.dir || !isDir) {
 			innerXML, err := prop.findFn(ctx, ls, fi.GetName(), fi)
@@ -258,7 +285,7 @@ func propnames(ctx context.Context, ls LockSystem, fi model.Obj) ([]xml.Name, er
 // returned if they are named in 'include'.
 //
 // See http://www.webdav.org/specs/rfc4918.html#METHOD_PROPFIND
-func allprop(ctx context.Context, ls LockSystem, fi model.Obj, include []xml.Name) ([]Propstat, error) {
+func allprop(ctx context.Context, ls LockSystem, reqPath string, fi model.Obj, include []xml.Name) ([]Propstat, error) {
 	pnames, err := propnames(ctx, ls, fi)
 	if err != nil {
 		return nil, err
@@ -273,7 +300,7 @@ func allprop(ctx context.Context, ls LockSystem, fi model.Obj, include []xml.Nam
 			pnames = append(pnames, pn)
 		}
 	}
-	return props(ctx, ls, fi, pnames)
+	return props(ctx, ls, reqPath, fi, pnames)
 }
 
 // Patch patches the properties of resource name. The return values are
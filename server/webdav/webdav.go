@@ -21,6 +21,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/sign"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
@@ -250,7 +251,9 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 		if storage.GetStorage().ProxyRange {
 			common.ProxyRange(link, fi.GetSize())
 		}
+		release := op.Acquire(storage)
 		err = common.Proxy(w, r, link, fi)
+		release()
 		if err != nil {
 			log.Errorf("webdav proxy error: %+v", err)
 			return http.StatusInternalServerError, err
@@ -267,6 +270,17 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
+		// the entries of archives have no url, they are streamed by alist
+		if link.URL == "" {
+			release := op.Acquire(storage)
+			err = common.Proxy(w, r, link, fi)
+			release()
+			if err != nil {
+				log.Errorf("webdav proxy error: %+v", err)
+				return http.StatusInternalServerError, err
+			}
+			return 0, nil
+		}
 		http.Redirect(w, r, link.URL, http.StatusFound)
 	}
 	return 0, nil
@@ -328,6 +342,9 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 	if err != nil {
 		return http.StatusForbidden, err
 	}
+	if err := op.CheckQuota(user, max(r.ContentLength, 0), 1); err != nil {
+		return http.StatusInsufficientStorage, err
+	}
 	obj := model.Object{
 		Name:     path.Base(reqPath),
 		Size:     r.ContentLength,
@@ -637,6 +654,10 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 		}
 		return http.StatusMethodNotAllowed, err
 	}
+	// an archive requested as a collection is browsed as a folder
+	if strings.HasSuffix(r.URL.Path, "/") && fs.IsArchive(fi) {
+		fi = &model.Object{Name: fi.GetName(), Path: fi.GetPath(), Modified: fi.ModTime(), IsFolder: true}
+	}
 	depth := infiniteDepth
 	if hdr := r.Header.Get("Depth"); hdr != "" {
 		depth = parseDepth(hdr)
@@ -667,9 +688,9 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 			}
 			pstats = append(pstats, pstat)
 		} else if pf.Allprop != nil {
-			pstats, err = allprop(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = allprop(ctx, h.LockSystem, reqPath, info, pf.Prop)
 		} else {
-			pstats, err = props(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = props(ctx, h.LockSystem, reqPath, info, pf.Prop)
 		}
 		if err != nil {
 			return err
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func TestWebDAVLoginAudit(t *testing.T) {
+	user := (&model.User{Username: "dav", Role: model.GENERAL, BasePath: "/", Permission: 1 << 8}).SetPassword("secret")
+	if err := op.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	auth := func(password string) int {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("PROPFIND", "/dav/", nil)
+		c.Request.SetBasicAuth("dav", password)
+		WebDAVAuth(c)
+		if !c.IsAborted() {
+			return http.StatusOK
+		}
+		return c.Writer.Status()
+	}
+	// every request of the client comes with the credentials
+	for i := 0; i < 3; i++ {
+		if code := auth("secret"); code != http.StatusOK {
+			t.Fatalf("the login should succeed, got %d", code)
+		}
+	}
+	if code := auth("wrong"); code != http.StatusUnauthorized {
+		t.Errorf("the login with the wrong password should fail, got %d", code)
+	}
+	logs, _, err := db.GetAuditLogs(db.AuditLogFilter{Username: "dav", Action: model.AuditLogin}, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var succeeded, failed int
+	for _, l := range logs {
+		if l.Success {
+			succeeded++
+		} else {
+			failed++
+		}
+	}
+	if succeeded != 1 || failed != 1 {
+		t.Errorf("the successful login should be logged once with the failed one, got %d and %d", succeeded, failed)
+	}
+}